@@ -11,7 +11,7 @@ headers = {'Content-Type': 'application/json'}
 
 def ping_server():
     """サーバーの生存確認を行う"""
-    response = requests.get(f'{base_url}/ping/', headers=headers)
+    response = requests.get(f'{base_url}/livez/', headers=headers)
     if response.ok:
         print('ping: ', response.json())
     else:
@@ -20,7 +20,7 @@ def ping_server():
 
 def ping_database():
     """データベースの生存確認を行う"""
-    response = requests.get(f'{base_url}/db-ping/', headers=headers)
+    response = requests.get(f'{base_url}/readyz/', headers=headers)
     if response.ok:
         print('db-ping: ', response.json())
     else:
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+
+	"github.com/mio256/wplus-server/pkg/infra"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/spf13/cobra"
+	"github.com/taxio/errors"
+)
+
+func assignmentSubCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "assignment",
+	}
+	cmd.AddCommand(
+		syncAssignmentCmd(ctx),
+	)
+	return cmd
+}
+
+// syncAssignmentCmd switches the current workplace of employees whose scheduled transfer has started.
+// It is meant to be run daily, shortly after midnight in the offices' time zones.
+func syncAssignmentCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "sync",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			dbConn := infra.ConnectDB(ctx)
+			defer dbConn.Close()
+
+			tx, err := dbConn.Begin(ctx)
+			if err != nil {
+				return errors.Wrap(err)
+			}
+			defer util.DeferRollback(ctx, tx)
+
+			repo := rdb.New(tx)
+
+			offices, err := repo.AllOffices(ctx)
+			if err != nil {
+				return errors.Wrap(err)
+			}
+			for _, office := range offices {
+				loc, err := util.LoadLocation(office.TimeZone)
+				if err != nil {
+					return errors.Wrap(err)
+				}
+				n, err := repo.SyncEmployeeWorkplaces(ctx, rdb.SyncEmployeeWorkplacesParams{
+					OfficeID: office.ID,
+					Today:    util.Today(loc),
+				})
+				if err != nil {
+					return errors.Wrap(err)
+				}
+				cmd.Printf("office %d: %d employees transferred\n", office.ID, n)
+			}
+
+			if err := tx.Commit(ctx); err != nil {
+				return errors.Wrap(err)
+			}
+
+			return nil
+		},
+	}
+	return cmd
+}
@@ -24,6 +24,9 @@ func rootCmd(ctx context.Context) *cobra.Command {
 		userSubCmd(ctx),
 		outputCmd(ctx),
 		sampleCmd(ctx),
+		purgeCmd(ctx),
+		assignmentSubCmd(ctx),
+		migrateSubCmd(ctx),
 	)
 
 	return cmd
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/mio256/wplus-server/db"
+	"github.com/mio256/wplus-server/pkg/infra"
+	"github.com/mio256/wplus-server/pkg/migrate"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/spf13/cobra"
+	"github.com/taxio/errors"
+)
+
+func migrateSubCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "migrate",
+	}
+	cmd.AddCommand(
+		migrateUpCmd(ctx),
+		migrateDownCmd(ctx),
+		migrateStatusCmd(ctx),
+	)
+	return cmd
+}
+
+// withMigrator runs f with the migrations embedded in the binary. The pool is closed afterwards.
+func withMigrator(cmd *cobra.Command, f func(m *migrate.Migrator) error) error {
+	migrations, err := migrate.Load(db.Migrations, "migrations")
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	dbConn := infra.ConnectDB(cmd.Context())
+	defer dbConn.Close()
+
+	m := migrate.New(dbConn, migrations)
+	m.Logf = cmd.Printf
+	return f(m)
+}
+
+// migrateUpCmd applies the pending migrations. A database whose schema was set up before migrations
+// were versioned is recorded at the baseline first.
+func migrateUpCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "up",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withMigrator(cmd, func(m *migrate.Migrator) error {
+				applied, err := m.Up(cmd.Context())
+				if err != nil {
+					return errors.Wrap(err)
+				}
+				cmd.Printf("%d migrations applied\n", len(applied))
+				return nil
+			})
+		},
+	}
+	return cmd
+}
+
+func migrateDownCmd(ctx context.Context) *cobra.Command {
+	var steps int
+	cmd := &cobra.Command{
+		Use: "down",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if steps < 1 {
+				return errors.New("invalid steps", errors.WithAttrs(errors.Attr("steps", steps)))
+			}
+			return withMigrator(cmd, func(m *migrate.Migrator) error {
+				reverted, err := m.Down(cmd.Context(), steps)
+				if err != nil {
+					return errors.Wrap(err)
+				}
+				cmd.Printf("%d migrations reverted\n", len(reverted))
+				return nil
+			})
+		},
+	}
+	cmd.Flags().IntVar(&steps, "steps", 1, "number of migrations to revert")
+	return cmd
+}
+
+func migrateStatusCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "status",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withMigrator(cmd, func(m *migrate.Migrator) error {
+				statuses, err := m.Status(cmd.Context())
+				if err != nil {
+					return errors.Wrap(err)
+				}
+				for _, s := range statuses {
+					state := "pending"
+					if s.AppliedAt.Valid {
+						state = "applied " + util.FormatTimestamp(s.AppliedAt, time.Local)
+					}
+					if s.Up == "" {
+						state += " (unknown to this binary)"
+					}
+					cmd.Printf("%04d_%s\t%s\n", s.Version, s.Name, state)
+				}
+				return nil
+			})
+		},
+	}
+	return cmd
+}
@@ -4,9 +4,7 @@ import (
 	"context"
 	"encoding/csv"
 	"fmt"
-	"github.com/jackc/pgx/v5/pgtype"
 	"io"
-	"log"
 	"os"
 	"strconv"
 	"time"
@@ -15,17 +13,14 @@ import (
 	"golang.org/x/text/transform"
 
 	"github.com/gocarina/gocsv"
+	"github.com/mio256/wplus-server/pkg/export"
 	"github.com/mio256/wplus-server/pkg/infra"
 	"github.com/mio256/wplus-server/pkg/infra/rdb"
 	"github.com/mio256/wplus-server/pkg/util"
 	"github.com/spf13/cobra"
 	"github.com/taxio/errors"
-	"github.com/xuri/excelize/v2"
 )
 
-const TEMPLATE = "resource/template.xlsx"
-const SHEET = "Sheet1"
-
 func outputCmd(ctx context.Context) *cobra.Command {
 	cmd := &cobra.Command{
 		Use: "output",
@@ -68,97 +63,16 @@ func outputElsxCmd(ctx context.Context) *cobra.Command {
 				return errors.Wrap(err)
 			}
 
-			f, err := excelize.OpenFile(TEMPLATE)
+			workplace, err := repo.GetWorkplace(ctx, int64(id))
 			if err != nil {
 				return errors.Wrap(err)
 			}
-			defer func() {
-				if f.WorkBook != nil && f.WorkBook.CalcPr != nil {
-					f.WorkBook.CalcPr.FullCalcOnLoad = true
-				}
-				if err := f.SaveAs("output.xlsx"); err != nil {
-					log.Fatal(err)
-				}
-			}()
-
-			if err := f.SetCellValue(SHEET, "C4", year); err != nil {
-				return errors.Wrap(err)
-			}
-			if err := f.SetCellValue(SHEET, "E4", month); err != nil {
-				return errors.Wrap(err)
-			}
-
-			entries, err := repo.OutputWorkEntriesByWorkplaceAndDate(ctx, rdb.OutputWorkEntriesByWorkplaceAndDateParams{
-				ID: int64(id),
-				MinDate: pgtype.Date{
-					Time:  time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC),
-					Valid: true,
-				},
-				MaxDate: pgtype.Date{
-					Time:  time.Date(year, time.Month(month+1), 0, 0, 0, 0, 0, time.UTC),
-					Valid: true,
-				},
-			})
+			f, err := export.WorkplaceMonth(ctx, repo, workplace, year, time.Month(month))
 			if err != nil {
 				return errors.Wrap(err)
 			}
-
-			type WorkEntry struct {
-				Date  time.Time
-				Hours uint
-			}
-
-			employeeEntries := map[string][]WorkEntry{}
-			for _, e := range entries {
-				employeeEntries[e.EmployeeName] = append(employeeEntries[e.EmployeeName], WorkEntry{
-					Date:  e.Date.Time,
-					Hours: uint(e.EndTime.Microseconds-e.StartTime.Microseconds) / 3600 / 1000000,
-				})
-			}
-
-			if err = f.SetCellValue(SHEET, "E4", month); err != nil {
-				return err
-			}
-
-			i := 0
-			for name, entries := range employeeEntries {
-				fmt.Printf("%s:\n", name)
-				if nameCell, err := excelize.CoordinatesToCellName(2, 7+int(i)*2); err == nil {
-					if err := f.SetCellValue(SHEET, nameCell, name); err != nil {
-						return err
-					}
-				}
-				for _, entry := range entries {
-					// HACK: format date
-					day, err := strconv.Atoi(entry.Date.Format("2006-01-02")[8:])
-					if err != nil {
-						return err
-					}
-
-					fmt.Printf("day: %d, hours: %d\n", day, entry.Hours)
-
-					// C{7+i*2}-AG{7+i*2}
-					hourCell, err := excelize.CoordinatesToCellName(3+day-1, 7+int(i)*2)
-					if err != nil {
-						return err
-					}
-
-					hourValue := 0
-
-					if hourStr, err := f.GetCellValue(SHEET, hourCell); err == nil {
-						if hourStr != "" {
-							hourValue, err = strconv.Atoi(hourStr)
-							if err != nil {
-								return err
-							}
-						}
-					}
-
-					if err = f.SetCellValue(SHEET, hourCell, entry.Hours+uint(hourValue)); err != nil {
-						return err
-					}
-				}
-				i++
+			if err := f.SaveAs("output.xlsx"); err != nil {
+				return errors.Wrap(err)
 			}
 
 			return nil
@@ -233,18 +147,13 @@ func outputCSVCmd(ctx context.Context) *cobra.Command {
 				return errors.Wrap(err)
 			}
 
-			utc, err := time.LoadLocation("UTC")
-			if err != nil {
-				return errors.Wrap(err)
-			}
-
 			var data []Entries
 			for _, e := range entries {
 				rec := Entries{
-					Date:       e.Date.Time.Format("2006-01-02"),
+					Date:       util.FormatDate(e.Date),
 					Hours:      int(e.Hours.Int16),
-					StartTime:  time.UnixMicro(e.StartTime.Microseconds).In(utc).Format("15:04:05"),
-					EndTime:    time.UnixMicro(e.EndTime.Microseconds).In(utc).Format("15:04:05"),
+					StartTime:  util.FormatClock(e.StartTime),
+					EndTime:    util.FormatClock(e.EndTime),
 					Attendance: e.Attendance.Bool,
 					Comment:    e.Comment.String,
 				}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/infra"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/spf13/cobra"
+	"github.com/taxio/errors"
+)
+
+// purgeCmd hard-deletes rows that were soft-deleted before the retention period.
+// It is meant to be run periodically, e.g. by Cloud Scheduler.
+func purgeCmd(ctx context.Context) *cobra.Command {
+	var days int
+	cmd := &cobra.Command{
+		Use: "purge",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if days < 1 {
+				return errors.New("invalid days", errors.WithAttrs(errors.Attr("days", days)))
+			}
+			ctx := cmd.Context()
+
+			dbConn := infra.ConnectDB(ctx)
+			defer dbConn.Close()
+
+			tx, err := dbConn.Begin(ctx)
+			if err != nil {
+				return errors.Wrap(err)
+			}
+			defer util.DeferRollback(ctx, tx)
+
+			res, err := purge(ctx, rdb.New(tx), util.DeletedSince(time.Now(), days))
+			if err != nil {
+				return errors.Wrap(err)
+			}
+
+			if err := tx.Commit(ctx); err != nil {
+				return errors.Wrap(err)
+			}
+
+			cmd.Printf("purged work_entries: %d, users: %d, employees: %d, shifts: %d, shift_templates: %d, workplaces: %d\n",
+				res.WorkEntries, res.Users, res.Employees, res.Shifts, res.ShiftTemplates, res.Workplaces)
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&days, "days", util.DeletedRetentionDays, "retention period in days")
+	return cmd
+}
+
+// purged counts the rows removed by purge.
+type purged struct {
+	WorkEntries, Users, Employees, Shifts, ShiftTemplates, Workplaces int64
+}
+
+// purge hard-deletes the rows soft-deleted before the given time, children first so that no foreign key is
+// left dangling. The shifts and shift templates of a workplace go with it.
+func purge(ctx context.Context, repo *rdb.Queries, before pgtype.Timestamp) (purged, error) {
+	var res purged
+	var err error
+	if res.WorkEntries, err = repo.PurgeWorkEntries(ctx, before); err != nil {
+		return purged{}, errors.Wrap(err)
+	}
+	if res.Users, err = repo.PurgeUsersOfDeletedEmployees(ctx, before); err != nil {
+		return purged{}, errors.Wrap(err)
+	}
+	if res.Employees, err = repo.PurgeEmployees(ctx, before); err != nil {
+		return purged{}, errors.Wrap(err)
+	}
+	if res.Shifts, err = repo.PurgeShiftsOfDeletedWorkplaces(ctx, before); err != nil {
+		return purged{}, errors.Wrap(err)
+	}
+	if res.ShiftTemplates, err = repo.PurgeShiftTemplatesOfDeletedWorkplaces(ctx, before); err != nil {
+		return purged{}, errors.Wrap(err)
+	}
+	if res.Workplaces, err = repo.PurgeWorkplaces(ctx, before); err != nil {
+		return purged{}, errors.Wrap(err)
+	}
+	return res, nil
+}
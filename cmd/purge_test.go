@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/infra"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/test"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurge(t *testing.T) {
+	ctx := context.Background()
+	dbConn := infra.ConnectDB(ctx)
+	t.Cleanup(dbConn.Close)
+	repo := rdb.New(dbConn)
+
+	office := test.CreateOffice(t, ctx, dbConn, nil)
+	deleted := test.CreateWorkplace(t, ctx, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+	})
+	workplace := test.CreateWorkplace(t, ctx, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+	})
+	employee := test.CreateEmployee(t, ctx, dbConn, func(v *rdb.Employee) {
+		v.WorkplaceID = workplace.ID
+	})
+
+	start, err := util.ParseClock("09:00")
+	require.NoError(t, err)
+	end, err := util.ParseClock("18:00")
+	require.NoError(t, err)
+	template, err := repo.CreateShiftTemplate(ctx, rdb.CreateShiftTemplateParams{
+		WorkplaceID: deleted.ID,
+		Name:        "早番",
+		StartTime:   start,
+		EndTime:     end,
+	})
+	require.NoError(t, err)
+	shift, err := repo.CreateShift(ctx, rdb.CreateShiftParams{
+		WorkplaceID:     deleted.ID,
+		EmployeeID:      employee.ID,
+		Date:            util.NewDate(time.Now()),
+		StartTime:       start,
+		EndTime:         end,
+		ShiftTemplateID: pgtype.Int8{Int64: template.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, repo.DeleteShift(ctx, shift.ID))
+	})
+	require.NoError(t, repo.SoftDeleteWorkplace(ctx, deleted.ID))
+
+	// purge everything deleted so far, and leave it all in place for the other tests
+	tx, err := dbConn.Begin(ctx)
+	require.NoError(t, err)
+	defer util.DeferRollback(ctx, tx)
+	txRepo := repo.WithTx(tx)
+
+	res, err := purge(ctx, txRepo, util.DeletedSince(time.Now().Add(time.Minute), 0))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, res.Shifts, int64(1))
+	assert.GreaterOrEqual(t, res.ShiftTemplates, int64(1))
+	assert.GreaterOrEqual(t, res.Workplaces, int64(1))
+
+	_, err = txRepo.GetShift(ctx, shift.ID)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	_, err = txRepo.GetShiftTemplate(ctx, template.ID)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	_, err = txRepo.TestGetDeletedAtWorkplace(ctx, deleted.ID)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	_, err = txRepo.GetEmployee(ctx, employee.ID)
+	assert.NoError(t, err)
+}
@@ -59,8 +59,9 @@ func createCmd(ctx context.Context) *cobra.Command {
 			var employees []*rdb.Employee
 			for i := 0; i < 3; i++ {
 				e, err := repo.CreateEmployee(ctx, rdb.CreateEmployeeParams{
-					Name:        fmt.Sprintf("sample_employee_%d", i),
-					WorkplaceID: wp.ID,
+					Name:           fmt.Sprintf("sample_employee_%d", i),
+					WorkplaceID:    wp.ID,
+					EmploymentType: rdb.EmploymentTypePartTime,
 				})
 				if err != nil {
 					return errors.Wrap(err)
@@ -91,15 +92,17 @@ func createCmd(ctx context.Context) *cobra.Command {
 				users = append(users, &u)
 			}
 
+			loc, err := util.LoadLocation(o.TimeZone)
+			if err != nil {
+				return errors.Wrap(err)
+			}
+
 			for i := 0; i < 3; i++ {
 				for j := 0; j < 3; j++ {
 					we, err := repo.CreateWorkEntry(ctx, rdb.CreateWorkEntryParams{
 						EmployeeID:  employees[i].ID,
 						WorkplaceID: wp.ID,
-						Date: pgtype.Date{
-							Time:  time.Now().AddDate(0, 0, -j),
-							Valid: true,
-						},
+						Date:        util.NewDate(time.Now().In(loc).AddDate(0, 0, -j)),
 						StartTime: pgtype.Time{
 							Microseconds: int64((12 - j) * int(time.Hour) / int(time.Microsecond)),
 							Valid:        true,
@@ -113,7 +116,7 @@ func createCmd(ctx context.Context) *cobra.Command {
 						return errors.Wrap(err)
 					}
 					log.Printf("work_entry[%d][%d]: id = %d, employee_id = %d, workplace_id = %d, date = %s, start_time = %s, end_time = %s",
-						i, j, we.ID, we.EmployeeID, we.WorkplaceID, we.Date.Time, we.StartTime.Microseconds, we.EndTime.Microseconds)
+						i, j, we.ID, we.EmployeeID, we.WorkplaceID, util.FormatDate(we.Date), util.FormatClock(we.StartTime), util.FormatClock(we.EndTime))
 				}
 			}
 
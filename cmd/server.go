@@ -2,12 +2,13 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"net"
-	"os"
+	"strconv"
 
+	"github.com/mio256/wplus-server/pkg/config"
 	"github.com/mio256/wplus-server/pkg/ui"
 	"github.com/spf13/cobra"
+	"github.com/taxio/errors"
 )
 
 func serverCmd(ctx context.Context) *cobra.Command {
@@ -24,38 +25,47 @@ func serverCmd(ctx context.Context) *cobra.Command {
 func runLocalCmd(ctx context.Context) *cobra.Command {
 	cmd := &cobra.Command{
 		Use: "local",
+		// the flags are the flags of the config package
+		DisableFlagParsing: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			r := ui.SetupRouter()
-
-			if err := r.Run(":8080"); err != nil {
-				panic(err)
+			cfg, err := config.Load(args)
+			if err != nil {
+				return errors.Wrap(err)
 			}
-
-			return nil
+			return ui.Run(cmd.Context(), cfg)
 		},
 	}
 	return cmd
 }
 
+// runNetworkCmd listens on every non-loopback IPv4 address of the machine unless the addresses are configured.
 func runNetworkCmd(ctx context.Context) *cobra.Command {
 	cmd := &cobra.Command{
 		Use: "network",
+		// the flags are the flags of the config package
+		DisableFlagParsing: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			r := ui.SetupRouter()
-			addrs, err := net.InterfaceAddrs()
+			cfg, err := config.Load(args)
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				return errors.Wrap(err)
 			}
-			for _, address := range addrs {
-				if ipnet, ok := address.(*net.IPNet); ok && !ipnet.IP.IsLoopback() {
-					if ipnet.IP.To4() != nil {
-						r.Run(fmt.Sprint(ipnet.IP.String(), ":8080"))
+
+			if len(cfg.Server.Addrs) == 0 {
+				addrs, err := net.InterfaceAddrs()
+				if err != nil {
+					return errors.Wrap(err)
+				}
+				for _, address := range addrs {
+					if ipnet, ok := address.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+						cfg.Server.Addrs = append(cfg.Server.Addrs, net.JoinHostPort(ipnet.IP.String(), strconv.Itoa(cfg.Server.Port)))
 					}
 				}
+				if len(cfg.Server.Addrs) == 0 {
+					return errors.New("no network address to listen on")
+				}
 			}
 
-			return nil
+			return ui.Run(cmd.Context(), cfg)
 		},
 	}
 	return cmd
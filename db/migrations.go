@@ -0,0 +1,10 @@
+// Package db holds the schema of the database. core.sql is the whole schema that sqlc reads, and migrations
+// are the steps that bring a database to it.
+package db
+
+import "embed"
+
+// Migrations are the versioned migrations, NNNN_name.up.sql and NNNN_name.down.sql.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
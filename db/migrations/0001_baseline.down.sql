@@ -0,0 +1,8 @@
+-- 初期スキーマをすべて削除する
+drop table if exists users;
+drop table if exists work_entries;
+drop table if exists employees;
+drop table if exists workplaces;
+drop table if exists offices;
+drop type if exists user_type;
+drop type if exists work_type;
@@ -0,0 +1,28 @@
+drop table if exists audit_logs;
+drop table if exists leave_requests;
+drop type if exists leave_status;
+drop table if exists leave_grants;
+drop table if exists leave_types;
+drop table if exists shifts;
+drop table if exists shift_templates;
+drop index if exists idx_work_entries_employee_id_date;
+drop index if exists idx_work_entries_workplace_id_date;
+alter table work_entries drop column if exists deleted_with_employee;
+drop table if exists employee_assignments;
+alter table employees drop constraint if exists chk_employees_hourly_wage;
+alter table employees drop constraint if exists chk_employees_leave_date;
+alter table employees drop column if exists hourly_wage;
+alter table employees drop column if exists leave_date;
+alter table employees drop column if exists hire_date;
+alter table employees drop column if exists employment_type;
+alter table employees drop column if exists name_kana;
+alter table employees drop column if exists code;
+drop type if exists employment_type;
+drop index if exists idx_workplaces_office_id;
+alter table workplaces drop column if exists allow_multiple_entries;
+alter table workplaces drop column if exists default_end_time;
+alter table workplaces drop column if exists default_start_time;
+alter table workplaces drop column if exists address;
+drop table if exists office_closures;
+drop table if exists overtime_rules;
+alter table offices drop column if exists time_zone;
@@ -0,0 +1,198 @@
+-- 版管理を始める前に core.sql に加えた変更
+
+-- 事業所の時間帯
+alter table offices add column time_zone varchar(64) not null default 'Asia/Tokyo';
+
+-- 事業所ごとの時間外労働の計算ルール
+-- 行がない事業所は法定の既定値 (1日8時間、週40時間、22時から5時、日曜日が法定休日) を使う
+create table overtime_rules (
+    office_id bigint primary key,
+    daily_limit_minutes integer not null default 480,
+    weekly_limit_minutes integer not null default 2400,
+    night_start time not null default '22:00',
+    night_end time not null default '05:00',
+    -- 0 (日曜日) から 6 (土曜日)
+    legal_holiday smallint not null default 0,
+    week_start smallint not null default 0,
+    created_at timestamp not null default current_timestamp,
+    updated_at timestamp not null default current_timestamp,
+
+    constraint chk_overtime_rules_limits check (daily_limit_minutes > 0 and weekly_limit_minutes > 0),
+    constraint chk_overtime_rules_weekdays check (legal_holiday between 0 and 6 and week_start between 0 and 6)
+);
+
+-- 事業所ごとの休業日 (国民の祝日は別に計算する)
+create table office_closures (
+    id bigserial primary key,
+    office_id bigint not null,
+    date date not null,
+    name varchar(255) not null,
+    created_at timestamp not null default current_timestamp,
+    updated_at timestamp not null default current_timestamp,
+
+    constraint uq_office_closures_office_id_date unique (office_id, date)
+);
+
+-- 職場の所在地と既定の勤務時間
+alter table workplaces add column address varchar(255);
+alter table workplaces add column default_start_time time;
+alter table workplaces add column default_end_time time;
+alter table workplaces add column allow_multiple_entries boolean not null default false;
+
+-- 雇用形態
+create type employment_type as enum ('full_time', 'part_time', 'contract', 'temporary');
+
+-- 従業員の属性
+alter table employees add column code varchar(32);
+alter table employees add column name_kana varchar(255);
+alter table employees add column employment_type employment_type not null default 'full_time';
+alter table employees add column hire_date date;
+alter table employees add column leave_date date;
+alter table employees add column hourly_wage integer;
+alter table employees add constraint chk_employees_leave_date check (leave_date is null or hire_date is null or hire_date <= leave_date);
+alter table employees add constraint chk_employees_hourly_wage check (hourly_wage is null or hourly_wage >= 0);
+
+-- 従業員の所属履歴テーブル
+-- 履歴のない従業員は employees.workplace_id に所属し続けているものとみなす
+-- employees.workplace_id は現在の所属を表し、effective_from を迎えた履歴が反映される
+create table employee_assignments (
+    id bigserial primary key,
+    employee_id bigint not null,
+    workplace_id bigint not null,
+    -- null は最初の所属 (開始日を問わない)
+    effective_from date,
+    -- null は現在も続いている所属
+    effective_to date,
+    created_at timestamp not null default current_timestamp,
+    updated_at timestamp not null default current_timestamp,
+
+    constraint chk_employee_assignments_period check (
+        effective_from is null or effective_to is null or effective_from <= effective_to
+    )
+);
+
+-- 従業員の削除に伴って削除された勤務 (従業員の復元時に一緒に復元する)
+alter table work_entries add column deleted_with_employee boolean not null default false;
+
+-- シフトの型 (早番、遅番、夜勤など)
+create table shift_templates (
+    id bigserial primary key,
+    workplace_id bigint not null,
+    name varchar(255) not null,
+    start_time time not null,
+    -- start_time 以前は日付をまたぐ
+    end_time time not null,
+    created_at timestamp not null default current_timestamp,
+    updated_at timestamp not null default current_timestamp,
+
+    constraint uq_shift_templates_workplace_id_name unique (workplace_id, name)
+);
+
+-- 勤務予定
+-- published_at が null の間は下書きで、従業員には見えない
+create table shifts (
+    id bigserial primary key,
+    workplace_id bigint not null,
+    employee_id bigint not null,
+    date date not null,
+    start_time time not null,
+    -- start_time 以前は日付をまたぐ
+    end_time time not null,
+    shift_template_id bigint,
+    published_at timestamp,
+    created_at timestamp not null default current_timestamp,
+    updated_at timestamp not null default current_timestamp
+);
+
+-- 休暇の種類
+create table leave_types (
+    id bigserial primary key,
+    office_id bigint not null,
+    name varchar(255) not null,
+    -- 年次有給休暇の残日数から差し引く
+    paid boolean not null default false,
+    -- 勤怠表に記入する記号
+    marker varchar(4) not null,
+    created_at timestamp not null default current_timestamp,
+    updated_at timestamp not null default current_timestamp,
+
+    constraint uq_leave_types_office_id_name unique (office_id, name)
+);
+
+-- 年次有給休暇の付与
+-- 勤続期間に応じた法定の付与は入社日から自動で作られ、パートタイムの比例付与は管理者が登録する
+create table leave_grants (
+    id bigserial primary key,
+    employee_id bigint not null,
+    granted_on date not null,
+    -- この日まで使える (付与から2年で時効)
+    expires_on date not null,
+    days smallint not null,
+    created_at timestamp not null default current_timestamp,
+    updated_at timestamp not null default current_timestamp,
+
+    constraint uq_leave_grants_employee_id_granted_on unique (employee_id, granted_on),
+    constraint chk_leave_grants_days check (days > 0 and granted_on <= expires_on)
+);
+
+-- 休暇申請の状態
+create type leave_status as enum ('pending', 'approved', 'rejected', 'cancelled');
+
+-- 休暇申請 (1日1行)
+create table leave_requests (
+    id bigserial primary key,
+    employee_id bigint not null,
+    leave_type_id bigint not null,
+    date date not null,
+    status leave_status not null default 'pending',
+    comment varchar(255),
+    -- 承認または却下した利用者 (users.id)
+    decided_by bigint,
+    decided_at timestamp,
+    created_at timestamp not null default current_timestamp,
+    updated_at timestamp not null default current_timestamp
+);
+
+-- 監査ログ
+-- 追記のみで、更新や削除のクエリは用意しない
+-- 事業所や利用者が削除されても残すため外部キーは張らない
+create table audit_logs (
+    id bigserial primary key,
+    office_id bigint not null,
+    -- 操作した利用者 (ログインの失敗では null)
+    user_id bigint,
+    user_name varchar(255),
+    user_role varchar(16),
+    action varchar(32) not null,
+    resource varchar(32) not null,
+    resource_id bigint,
+    before jsonb,
+    after jsonb,
+    request_id varchar(64),
+    created_at timestamp not null default current_timestamp
+);
+
+-- インデックス
+create index idx_workplaces_office_id on workplaces (office_id) where deleted_at is null;
+create index idx_work_entries_workplace_id_date on work_entries (workplace_id, date) where deleted_at is null;
+create index idx_work_entries_employee_id_date on work_entries (employee_id, date) where deleted_at is null;
+create index idx_employee_assignments_employee_id on employee_assignments (employee_id, effective_from);
+create index idx_shifts_workplace_id_date on shifts (workplace_id, date);
+create index idx_shifts_employee_id_date on shifts (employee_id, date);
+-- 同じ日に有効な申請は1つだけ
+create unique index uq_leave_requests_employee_id_date on leave_requests (employee_id, date) where status in ('pending', 'approved');
+create index idx_audit_logs_office_id_created_at on audit_logs (office_id, created_at);
+
+-- 外部キー制約
+alter table overtime_rules add constraint fk_overtime_rules_offices foreign key (office_id) references offices(id);
+alter table office_closures add constraint fk_office_closures_offices foreign key (office_id) references offices(id);
+alter table employee_assignments add constraint fk_employee_assignments_employees foreign key (employee_id) references employees(id) on delete cascade;
+alter table employee_assignments add constraint fk_employee_assignments_workplaces foreign key (workplace_id) references workplaces(id);
+alter table shift_templates add constraint fk_shift_templates_workplaces foreign key (workplace_id) references workplaces(id);
+alter table shifts add constraint fk_shifts_workplaces foreign key (workplace_id) references workplaces(id);
+alter table shifts add constraint fk_shifts_employees foreign key (employee_id) references employees(id) on delete cascade;
+alter table shifts add constraint fk_shifts_shift_templates foreign key (shift_template_id) references shift_templates(id) on delete set null;
+alter table leave_types add constraint fk_leave_types_offices foreign key (office_id) references offices(id);
+alter table leave_grants add constraint fk_leave_grants_employees foreign key (employee_id) references employees(id) on delete cascade;
+alter table leave_requests add constraint fk_leave_requests_employees foreign key (employee_id) references employees(id) on delete cascade;
+alter table leave_requests add constraint fk_leave_requests_leave_types foreign key (leave_type_id) references leave_types(id);
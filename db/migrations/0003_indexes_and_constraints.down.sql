@@ -0,0 +1,9 @@
+drop trigger if exists trg_work_entries_workplace on work_entries;
+drop function if exists check_work_entry_workplace();
+
+drop index if exists uq_users_employee_id;
+drop index if exists idx_work_entries_employee_id_deleted;
+drop index if exists idx_work_entries_deleted_at;
+drop index if exists idx_employees_deleted_at;
+drop index if exists idx_workplaces_office_id_deleted_at;
+drop index if exists idx_employees_workplace_id;
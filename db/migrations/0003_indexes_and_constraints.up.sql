@@ -0,0 +1,55 @@
+-- 勤務地ごとの在籍従業員
+create index idx_employees_workplace_id on employees (workplace_id) where deleted_at is null;
+-- 削除済み一覧と完全削除
+create index idx_workplaces_office_id_deleted_at on workplaces (office_id, deleted_at) where deleted_at is not null;
+create index idx_employees_deleted_at on employees (deleted_at) where deleted_at is not null;
+create index idx_work_entries_deleted_at on work_entries (deleted_at) where deleted_at is not null;
+-- 従業員と一緒に削除された勤怠の復元
+create index idx_work_entries_employee_id_deleted on work_entries (employee_id) where deleted_with_employee;
+-- 従業員に紐づくユーザーは1人だけ
+-- 重複があれば索引を作る前に従業員IDを挙げて止める
+do $$
+declare
+    duplicated text;
+begin
+    select string_agg(employee_id::text, ', ' order by employee_id) into duplicated
+    from (
+        select employee_id from users
+        where employee_id is not null
+        group by employee_id
+        having count(*) > 1
+    ) as d;
+    if duplicated is not null then
+        raise exception 'employees % have more than one user; remove the extra users before migrating', duplicated
+            using errcode = 'unique_violation';
+    end if;
+end;
+$$;
+create unique index uq_users_employee_id on users (employee_id) where employee_id is not null;
+
+-- 勤怠の勤務地はその日の従業員の所属と一致する
+create function check_work_entry_workplace() returns trigger as $$
+declare
+    assigned bigint;
+begin
+    select workplace_id into assigned from employee_assignments
+    where employee_id = new.employee_id
+        and (effective_from is null or effective_from <= new.date)
+        and (effective_to is null or effective_to >= new.date)
+    order by effective_from desc nulls last
+    limit 1;
+    if assigned is null then
+        select workplace_id into assigned from employees where id = new.employee_id;
+    end if;
+    if assigned is distinct from new.workplace_id then
+        raise exception 'work entry workplace % does not match the assignment % of employee % on %',
+            new.workplace_id, assigned, new.employee_id, new.date
+            using errcode = 'check_violation', constraint = 'chk_work_entries_workplace';
+    end if;
+    return new;
+end;
+$$ language plpgsql;
+
+create trigger trg_work_entries_workplace
+before insert or update of employee_id, workplace_id, date on work_entries
+for each row execute function check_work_entry_workplace();
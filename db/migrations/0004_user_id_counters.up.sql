@@ -0,0 +1,9 @@
+-- 事業所ごとに最後に払い出したユーザーID
+create table user_id_counters (
+    office_id bigint primary key,
+    last_id bigint not null
+);
+alter table user_id_counters add constraint fk_user_id_counters_offices foreign key (office_id) references offices(id) on delete cascade;
+
+insert into user_id_counters (office_id, last_id)
+select office_id, max(id) from users group by office_id;
@@ -0,0 +1,25 @@
+-- name: CreateAuditLog :exec
+insert into audit_logs (office_id, user_id, user_name, user_role, action, resource, resource_id, before, after, request_id)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: GetAuditLogs :many
+select * from audit_logs
+where office_id = @office_id
+    and (sqlc.narg(user_id)::bigint is null or user_id = sqlc.narg(user_id))
+    and (sqlc.narg(action)::text is null or action = sqlc.narg(action))
+    and (sqlc.narg(resource)::text is null or resource = sqlc.narg(resource))
+    and (sqlc.narg(resource_id)::bigint is null or resource_id = sqlc.narg(resource_id))
+    and (sqlc.narg(from_time)::timestamp is null or created_at >= sqlc.narg(from_time))
+    and (sqlc.narg(to_time)::timestamp is null or created_at < sqlc.narg(to_time))
+order by id desc
+limit @page_limit offset @page_offset;
+
+-- name: CountAuditLogs :one
+select count(*) from audit_logs
+where office_id = @office_id
+    and (sqlc.narg(user_id)::bigint is null or user_id = sqlc.narg(user_id))
+    and (sqlc.narg(action)::text is null or action = sqlc.narg(action))
+    and (sqlc.narg(resource)::text is null or resource = sqlc.narg(resource))
+    and (sqlc.narg(resource_id)::bigint is null or resource_id = sqlc.narg(resource_id))
+    and (sqlc.narg(from_time)::timestamp is null or created_at >= sqlc.narg(from_time))
+    and (sqlc.narg(to_time)::timestamp is null or created_at < sqlc.narg(to_time));
@@ -0,0 +1,32 @@
+-- name: GetEmployeeAssignments :many
+select * from employee_assignments where employee_id = $1 order by effective_from nulls first;
+
+-- name: GetEmployeeWorkplaceOnDate :one
+select workplace_id from employee_assignments
+where employee_id = $1
+    and (effective_from is null or effective_from <= @on_date)
+    and (effective_to is null or effective_to >= @on_date)
+order by effective_from desc nulls last
+limit 1;
+
+-- name: CreateEmployeeAssignment :one
+insert into employee_assignments (employee_id, workplace_id, effective_from)
+values ($1, $2, $3)
+returning *;
+
+-- name: UpdateEmployeeAssignmentEnd :exec
+update employee_assignments set effective_to = $2, updated_at = now() where id = $1;
+
+-- name: DeleteEmployeeAssignment :exec
+delete from employee_assignments where id = $1;
+
+-- name: SyncEmployeeWorkplaces :execrows
+update employees set workplace_id = employee_assignments.workplace_id, updated_at = now()
+from employee_assignments, workplaces
+where employee_assignments.employee_id = employees.id
+    and employees.workplace_id = workplaces.id
+    and workplaces.office_id = @office_id
+    and employees.deleted_at is null
+    and employee_assignments.effective_from <= @today
+    and (employee_assignments.effective_to is null or employee_assignments.effective_to >= @today)
+    and employees.workplace_id <> employee_assignments.workplace_id;
@@ -2,13 +2,34 @@
 select * from employees where id = $1 and deleted_at is null;
 
 -- name: GetEmployees :many
-select * from employees where workplace_id = $1 and deleted_at is null;
+select * from employees
+where workplace_id = $1 and deleted_at is null
+order by coalesce(name_kana, name), id;
 
 -- name: GetEmployeesByOffice :many
 select employees.*
 from employees
 join workplaces on employees.workplace_id = workplaces.id
-where workplaces.office_id = $1 and employees.deleted_at is null;
+where workplaces.office_id = $1 and employees.deleted_at is null
+order by coalesce(employees.name_kana, employees.name), employees.id;
+
+-- name: ListEmployees :many
+select employees.*
+from employees
+join workplaces on employees.workplace_id = workplaces.id
+where workplaces.office_id = @office_id
+    and employees.deleted_at is null
+    and (sqlc.narg(workplace_id)::bigint is null or employees.workplace_id = sqlc.narg(workplace_id))
+order by coalesce(employees.name_kana, employees.name), employees.id
+limit @page_limit offset @page_offset;
+
+-- name: CountEmployees :one
+select count(*)
+from employees
+join workplaces on employees.workplace_id = workplaces.id
+where workplaces.office_id = @office_id
+    and employees.deleted_at is null
+    and (sqlc.narg(workplace_id)::bigint is null or employees.workplace_id = sqlc.narg(workplace_id));
 
 -- name: GetEmployeeOffice :one
 select workplaces.office_id
@@ -16,10 +37,77 @@ from employees join workplaces on employees.workplace_id = workplaces.id
 where employees.id = $1 and employees.deleted_at is null;
 
 -- name: CreateEmployee :one
-insert into employees (name, workplace_id) values ($1, $2) returning *;
+insert into employees (name, workplace_id, code, name_kana, employment_type, hire_date, leave_date, hourly_wage)
+values ($1, $2, $3, $4, $5, $6, $7, $8)
+returning *;
 
 -- name: SoftDeleteEmployee :exec
 update employees set deleted_at = now() where id = $1;
 
 -- name: UpdateEmployeeWorkplace :exec
 update employees set workplace_id = $2 where id = $1 and deleted_at is null;
+
+-- name: UpdateEmployeeProfile :one
+update employees
+set name = $2,
+    code = $3,
+    name_kana = $4,
+    employment_type = $5,
+    hire_date = $6,
+    leave_date = $7,
+    hourly_wage = $8,
+    updated_at = now()
+where id = $1 and deleted_at is null
+returning *;
+
+-- name: GetDeletedEmployees :many
+select employees.*
+from employees
+join workplaces on employees.workplace_id = workplaces.id
+where workplaces.office_id = $1 and employees.deleted_at >= @deleted_since
+order by employees.deleted_at desc, employees.id desc
+limit @page_limit offset @page_offset;
+
+-- name: CountDeletedEmployees :one
+select count(*)
+from employees
+join workplaces on employees.workplace_id = workplaces.id
+where workplaces.office_id = $1 and employees.deleted_at >= @deleted_since;
+
+-- name: GetDeletedEmployee :one
+select employees.*, workplaces.office_id, workplaces.deleted_at as workplace_deleted_at
+from employees
+join workplaces on employees.workplace_id = workplaces.id
+where employees.id = $1 and employees.deleted_at is not null;
+
+-- name: RestoreEmployee :one
+update employees set deleted_at = null, updated_at = now()
+where id = $1 and deleted_at is not null
+returning *;
+
+-- name: PurgeUsersOfDeletedEmployees :execrows
+delete from users
+where employee_id in (
+    select employees.id from employees
+    where employees.deleted_at < @deleted_before
+        and not exists (select 1 from work_entries where work_entries.employee_id = employees.id)
+);
+
+-- name: PurgeEmployees :execrows
+delete from employees
+where deleted_at < @deleted_before
+    and not exists (select 1 from work_entries where work_entries.employee_id = employees.id)
+    and not exists (select 1 from users where users.employee_id = employees.id);
+
+-- name: MoveEmployeesToWorkplace :many
+update employees set workplace_id = @to_workplace_id, updated_at = now()
+where workplace_id = @from_workplace_id and deleted_at is null
+returning *;
+
+-- name: SoftDeleteEmployeesByWorkplace :many
+update employees set deleted_at = now()
+where workplace_id = $1 and deleted_at is null
+returning *;
+
+-- name: GetEmployeeForUpdate :one
+select * from employees where id = $1 and deleted_at is null for update;
@@ -7,4 +7,32 @@ where workplaces.id = $1
     and work_entries.date >= @min_date
     and work_entries.date <= @max_date
     and work_entries.deleted_at is null
-order by employee_name;
+order by coalesce(employees.name_kana, employees.name), employees.id, work_entries.date;
+
+-- name: OutputEmployeesByWorkplaceAndDate :many
+select employees.*
+from employees
+where employees.deleted_at is null
+    and (
+        exists (
+            select 1 from employee_assignments
+            where employee_assignments.employee_id = employees.id
+                and employee_assignments.workplace_id = @workplace_id
+                and (employee_assignments.effective_from is null or employee_assignments.effective_from <= @max_date)
+                and (employee_assignments.effective_to is null or employee_assignments.effective_to >= @min_date)
+        )
+        or (
+            employees.workplace_id = @workplace_id
+            and not exists (select 1 from employee_assignments where employee_assignments.employee_id = employees.id)
+        )
+    )
+order by coalesce(employees.name_kana, employees.name), employees.id;
+
+-- name: OutputApprovedLeaves :many
+select leave_requests.employee_id, leave_requests.date, leave_types.marker, leave_types.paid
+from leave_requests
+    join leave_types on leave_requests.leave_type_id = leave_types.id
+where leave_types.office_id = @office_id
+    and leave_requests.status = 'approved'
+    and leave_requests.date between @min_date and @max_date
+order by leave_requests.employee_id, leave_requests.date;
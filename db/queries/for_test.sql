@@ -48,4 +48,15 @@ select deleted_at from work_entries where id = $1;
 insert into users (id, office_id, name, password, role, employee_id) values ($1, $2, $3, $4, $5, $6) returning *;
 
 -- name: TestDeleteUser :exec
-delete from users where id = $1;
\ No newline at end of file
+delete from users where id = $1 and office_id = $2;
+-- name: TestDeleteOvertimeRules :exec
+delete from overtime_rules where office_id = $1;
+
+-- name: TestDeleteOfficeClosures :exec
+delete from office_closures where office_id = $1;
+
+-- name: TestDeleteLeaveTypes :exec
+delete from leave_types where office_id = $1;
+
+-- name: TestDeleteShiftTemplates :exec
+delete from shift_templates where workplace_id = $1;
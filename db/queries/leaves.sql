@@ -0,0 +1,59 @@
+-- name: GetLeaveTypes :many
+select * from leave_types where office_id = $1 order by id;
+
+-- name: GetLeaveType :one
+select * from leave_types where id = $1;
+
+-- name: CreateLeaveType :one
+insert into leave_types (office_id, name, paid, marker)
+values ($1, $2, $3, $4)
+returning *;
+
+-- name: GetLeaveGrants :many
+select * from leave_grants where employee_id = $1 order by granted_on;
+
+-- name: CreateLeaveGrant :one
+insert into leave_grants (employee_id, granted_on, expires_on, days)
+values ($1, $2, $3, $4)
+on conflict (employee_id, granted_on) do nothing
+returning *;
+
+-- name: GetPaidLeaveDates :many
+select leave_requests.date, leave_requests.status
+from leave_requests
+    join leave_types on leave_requests.leave_type_id = leave_types.id
+where leave_requests.employee_id = $1
+    and leave_types.paid
+    and leave_requests.status in ('pending', 'approved')
+order by leave_requests.date;
+
+-- name: GetLeaveRequests :many
+select leave_requests.*, employees.name as employee_name, leave_types.name as leave_type_name, leave_types.paid
+from leave_requests
+    join employees on leave_requests.employee_id = employees.id
+    join leave_types on leave_requests.leave_type_id = leave_types.id
+where leave_types.office_id = @office_id
+    and leave_requests.date between @from_date and @to_date
+    and (sqlc.narg(employee_id)::bigint is null or leave_requests.employee_id = sqlc.narg(employee_id))
+    and (sqlc.narg(workplace_id)::bigint is null or employees.workplace_id = sqlc.narg(workplace_id))
+    and (sqlc.narg(status)::leave_status is null or leave_requests.status = sqlc.narg(status))
+order by leave_requests.date, leave_requests.id;
+
+-- name: GetLeaveRequestForUpdate :one
+select leave_requests.*, employees.workplace_id, leave_types.office_id, leave_types.paid
+from leave_requests
+    join employees on leave_requests.employee_id = employees.id
+    join leave_types on leave_requests.leave_type_id = leave_types.id
+where leave_requests.id = $1
+for update of leave_requests;
+
+-- name: CreateLeaveRequest :one
+insert into leave_requests (employee_id, leave_type_id, date, comment)
+values ($1, $2, $3, $4)
+returning *;
+
+-- name: DecideLeaveRequest :one
+update leave_requests
+set status = $2, decided_by = $3, decided_at = now(), updated_at = now()
+where id = $1
+returning *;
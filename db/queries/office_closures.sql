@@ -0,0 +1,14 @@
+-- name: GetOfficeClosures :many
+select * from office_closures
+where office_id = @office_id and date between @from_date and @to_date
+order by date;
+
+-- name: CreateOfficeClosure :one
+insert into office_closures (office_id, date, name)
+values ($1, $2, $3)
+returning *;
+
+-- name: DeleteOfficeClosure :one
+delete from office_closures
+where id = $1 and office_id = $2
+returning *;
@@ -9,3 +9,6 @@ insert into offices (name) values ($1) returning *;
 
 -- name: SoftDeleteOffice :exec
 update offices set deleted_at = now() where id = $1;
+
+-- name: UpdateOfficeTimeZone :one
+update offices set time_zone = $2, updated_at = now() where id = $1 and deleted_at is null returning *;
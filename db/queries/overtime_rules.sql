@@ -0,0 +1,15 @@
+-- name: GetOvertimeRules :one
+select * from overtime_rules where office_id = $1;
+
+-- name: UpsertOvertimeRules :one
+insert into overtime_rules (office_id, daily_limit_minutes, weekly_limit_minutes, night_start, night_end, legal_holiday, week_start)
+values ($1, $2, $3, $4, $5, $6, $7)
+on conflict (office_id) do update
+set daily_limit_minutes = excluded.daily_limit_minutes,
+    weekly_limit_minutes = excluded.weekly_limit_minutes,
+    night_start = excluded.night_start,
+    night_end = excluded.night_end,
+    legal_holiday = excluded.legal_holiday,
+    week_start = excluded.week_start,
+    updated_at = now()
+returning *;
@@ -0,0 +1,55 @@
+-- name: GetShiftTemplates :many
+select * from shift_templates where workplace_id = $1 order by start_time, id;
+
+-- name: GetShiftTemplate :one
+select * from shift_templates where id = $1;
+
+-- name: CreateShiftTemplate :one
+insert into shift_templates (workplace_id, name, start_time, end_time)
+values ($1, $2, $3, $4)
+returning *;
+
+-- name: DeleteShiftTemplate :one
+delete from shift_templates
+where id = $1 and workplace_id = $2
+returning *;
+
+-- name: GetShifts :many
+select shifts.*, employees.name as employee_name, shift_templates.name as shift_template_name
+from shifts
+    join employees on shifts.employee_id = employees.id
+    join workplaces on shifts.workplace_id = workplaces.id
+    left join shift_templates on shifts.shift_template_id = shift_templates.id
+where workplaces.office_id = @office_id
+    and shifts.date between @from_date and @to_date
+    and (sqlc.narg(workplace_id)::bigint is null or shifts.workplace_id = sqlc.narg(workplace_id))
+    and (sqlc.narg(employee_id)::bigint is null or shifts.employee_id = sqlc.narg(employee_id))
+    and (not @published_only::boolean or shifts.published_at is not null)
+    and employees.deleted_at is null
+order by shifts.date, shifts.start_time, shifts.id;
+
+-- name: GetShift :one
+select * from shifts where id = $1;
+
+-- name: CreateShift :one
+insert into shifts (workplace_id, employee_id, date, start_time, end_time, shift_template_id)
+values ($1, $2, $3, $4, $5, $6)
+returning *;
+
+-- name: DeleteShift :exec
+delete from shifts where id = $1;
+
+-- name: PublishShifts :execrows
+update shifts
+set published_at = now(), updated_at = now()
+where workplace_id = @workplace_id
+    and date between @from_date and @to_date
+    and published_at is null;
+
+-- name: PurgeShiftsOfDeletedWorkplaces :execrows
+delete from shifts
+where workplace_id in (select id from workplaces where deleted_at < @deleted_before);
+
+-- name: PurgeShiftTemplatesOfDeletedWorkplaces :execrows
+delete from shift_templates
+where workplace_id in (select id from workplaces where deleted_at < @deleted_before);
@@ -8,20 +8,36 @@ join employees on work_entries.employee_id = employees.id
 join workplaces on work_entries.workplace_id = workplaces.id
 where employees.id = $1 and work_entries.deleted_at is null;
 
--- name: GetWorkEntriesByOffice :many
+-- name: ListWorkEntries :many
 select workplaces.name as workplace_name, employees.name as employee_name, work_entries.*
 from work_entries
 join employees on work_entries.employee_id = employees.id
 join workplaces on work_entries.workplace_id = workplaces.id
-join offices on workplaces.office_id = offices.id
-where offices.id = $1 and work_entries.deleted_at is null;
+where workplaces.office_id = @office_id
+    and work_entries.deleted_at is null
+    and (sqlc.narg(workplace_id)::bigint is null or work_entries.workplace_id = sqlc.narg(workplace_id))
+    and (sqlc.narg(employee_id)::bigint is null or work_entries.employee_id = sqlc.narg(employee_id))
+    and (sqlc.narg(from_date)::date is null or work_entries.date >= sqlc.narg(from_date))
+    and (sqlc.narg(to_date)::date is null or work_entries.date <= sqlc.narg(to_date))
+order by
+    case when @sort::text = 'date' then work_entries.date end asc,
+    case when @sort::text = '-date' then work_entries.date end desc,
+    case when @sort::text = 'employee' then coalesce(employees.name_kana, employees.name) end asc,
+    case when @sort::text = '-employee' then coalesce(employees.name_kana, employees.name) end desc,
+    work_entries.date desc,
+    work_entries.id desc
+limit @page_limit offset @page_offset;
 
--- name: GetWorkEntriesByWorkplace :many
-select workplaces.name as workplace_name, employees.name as employee_name, work_entries.*
+-- name: CountWorkEntries :one
+select count(*)
 from work_entries
-join employees on work_entries.employee_id = employees.id
 join workplaces on work_entries.workplace_id = workplaces.id
-where workplaces.id = $1 and work_entries.deleted_at is null;
+where workplaces.office_id = @office_id
+    and work_entries.deleted_at is null
+    and (sqlc.narg(workplace_id)::bigint is null or work_entries.workplace_id = sqlc.narg(workplace_id))
+    and (sqlc.narg(employee_id)::bigint is null or work_entries.employee_id = sqlc.narg(employee_id))
+    and (sqlc.narg(from_date)::date is null or work_entries.date >= sqlc.narg(from_date))
+    and (sqlc.narg(to_date)::date is null or work_entries.date <= sqlc.narg(to_date));
 
 -- name: CreateWorkEntry :one
 insert into work_entries (employee_id, workplace_id, date, hours, start_time, end_time, attendance, comment)
@@ -29,7 +45,69 @@ values ($1, $2, $3, $4, $5, $6, $7, $8)
 returning *;
 
 -- name: SoftDeleteWorkEntry :exec
-update work_entries set deleted_at = now() where id = $1;
+update work_entries set deleted_at = now(), deleted_with_employee = false where id = $1;
 
 -- name: SoftDeleteWorkEntriesByEmployee :exec
-update work_entries set deleted_at = now() where employee_id = $1 and deleted_at is null;
\ No newline at end of file
+update work_entries set deleted_at = now(), deleted_with_employee = true where employee_id = $1 and deleted_at is null;
+
+-- name: GetDeletedWorkEntries :many
+select workplaces.name as workplace_name, employees.name as employee_name, work_entries.*
+from work_entries
+join employees on work_entries.employee_id = employees.id
+join workplaces on work_entries.workplace_id = workplaces.id
+where workplaces.office_id = $1 and work_entries.deleted_at >= @deleted_since
+order by work_entries.deleted_at desc, work_entries.id desc;
+
+-- name: GetDeletedWorkEntry :one
+select workplaces.name as workplace_name, employees.name as employee_name, work_entries.*,
+    workplaces.office_id, employees.deleted_at as employee_deleted_at, workplaces.deleted_at as workplace_deleted_at
+from work_entries
+join employees on work_entries.employee_id = employees.id
+join workplaces on work_entries.workplace_id = workplaces.id
+where work_entries.id = $1 and work_entries.deleted_at is not null;
+
+-- name: RestoreWorkEntry :one
+update work_entries set deleted_at = null, deleted_with_employee = false, updated_at = now()
+where id = $1 and deleted_at is not null
+returning *;
+
+-- name: RestoreWorkEntriesByEmployee :execrows
+update work_entries set deleted_at = null, deleted_with_employee = false, updated_at = now()
+where employee_id = $1 and deleted_with_employee;
+
+-- name: PurgeWorkEntries :execrows
+delete from work_entries where deleted_at < @deleted_before;
+
+-- name: SoftDeleteWorkEntriesByEmployees :execrows
+update work_entries set deleted_at = now(), deleted_with_employee = true
+where employee_id = any(@employee_ids::bigint[]) and deleted_at is null;
+
+-- name: GetWorkEntriesForOvertime :many
+select work_entries.employee_id, employees.name as employee_name, work_entries.workplace_id,
+    work_entries.date, work_entries.hours, work_entries.start_time, work_entries.end_time
+from work_entries
+join employees on work_entries.employee_id = employees.id
+join workplaces on work_entries.workplace_id = workplaces.id
+where workplaces.office_id = $1
+    and work_entries.deleted_at is null
+    and work_entries.date >= @from_date
+    and work_entries.date <= @to_date
+    and (sqlc.narg(employee_id)::bigint is null or work_entries.employee_id = sqlc.narg(employee_id))
+order by coalesce(employees.name_kana, employees.name), work_entries.employee_id, work_entries.date, work_entries.start_time;
+
+-- name: GetWorkEntriesForSummary :many
+select work_entries.employee_id, work_entries.workplace_id, work_entries.date,
+    work_entries.hours, work_entries.start_time, work_entries.end_time, work_entries.attendance
+from work_entries
+join workplaces on work_entries.workplace_id = workplaces.id
+where workplaces.office_id = @office_id
+    and work_entries.deleted_at is null
+    and work_entries.date >= @from_date
+    and work_entries.date <= @to_date
+    and (sqlc.narg(workplace_id)::bigint is null or work_entries.workplace_id = sqlc.narg(workplace_id))
+    and (sqlc.narg(employee_id)::bigint is null or work_entries.employee_id = sqlc.narg(employee_id));
+
+-- name: GetWorkEntriesByEmployeeAndDate :many
+select * from work_entries
+where employee_id = $1 and date = $2 and deleted_at is null
+order by id;
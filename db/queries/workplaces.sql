@@ -4,10 +4,58 @@ select * from workplaces where id = $1 and deleted_at is null;
 -- name: GetWorkplaces :many
 select * from workplaces where office_id = $1 and deleted_at is null;
 
+-- name: ListWorkplaces :many
+select * from workplaces
+where office_id = @office_id and deleted_at is null
+order by id
+limit @page_limit offset @page_offset;
+
+-- name: CountWorkplaces :one
+select count(*) from workplaces where office_id = $1 and deleted_at is null;
+
 -- name: CreateWorkplace :one
-insert into workplaces (name, office_id, work_type)
-values ($1, $2, $3)
+insert into workplaces (name, office_id, work_type, address, default_start_time, default_end_time)
+values ($1, $2, $3, $4, $5, $6)
 returning *;
 
 -- name: SoftDeleteWorkplace :exec
 update workplaces set deleted_at = now() where id = $1;
+
+-- name: UpdateWorkplaceProfile :one
+update workplaces
+set name = $2,
+    address = $3,
+    default_start_time = $4,
+    default_end_time = $5,
+    allow_multiple_entries = $6,
+    updated_at = now()
+where id = $1 and deleted_at is null
+returning *;
+
+-- name: GetDeletedWorkplaces :many
+select * from workplaces
+where office_id = $1 and deleted_at >= @deleted_since
+order by deleted_at desc, id desc
+limit @page_limit offset @page_offset;
+
+-- name: CountDeletedWorkplaces :one
+select count(*) from workplaces
+where office_id = $1 and deleted_at >= @deleted_since;
+
+-- name: GetDeletedWorkplace :one
+select * from workplaces where id = $1 and deleted_at is not null;
+
+-- name: RestoreWorkplace :one
+update workplaces set deleted_at = null, updated_at = now()
+where id = $1 and deleted_at is not null
+returning *;
+
+-- name: PurgeWorkplaces :execrows
+delete from workplaces
+where deleted_at < @deleted_before
+    and not exists (select 1 from employees where employees.workplace_id = workplaces.id)
+    and not exists (select 1 from employee_assignments where employee_assignments.workplace_id = workplaces.id)
+    and not exists (select 1 from work_entries where work_entries.workplace_id = workplaces.id);
+
+-- name: GetWorkplaceForUpdate :one
+select * from workplaces where id = $1 and deleted_at is null for update;
@@ -1,12 +1,25 @@
 package main
 
 import (
+	"context"
+	"os"
+
+	"github.com/mio256/wplus-server/pkg/config"
 	"github.com/mio256/wplus-server/pkg/ui"
+	"github.com/mio256/wplus-server/pkg/util"
 )
 
 func main() {
-	r := ui.SetupRouter()
-	if err := r.Run(":8080"); err != nil {
-		panic(err)
+	if err := run(); err != nil {
+		util.LogError(context.Background(), err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		return err
 	}
+	return ui.Run(context.Background(), cfg)
 }
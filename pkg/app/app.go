@@ -0,0 +1,55 @@
+// Package app holds what the server shares between requests.
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mio256/wplus-server/pkg/config"
+	"github.com/mio256/wplus-server/pkg/infra"
+	"github.com/mio256/wplus-server/pkg/metrics"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/taxio/errors"
+)
+
+// App is created once at startup and given to the handlers of every request.
+type App struct {
+	Config *config.Config
+	DB     *pgxpool.Pool
+	// Metrics are collected by the router and the handlers.
+	Metrics *metrics.Metrics
+	// Now is the clock of the handlers, replaced in tests.
+	Now func() time.Time
+}
+
+// New connects to the database and fails when it does not answer within the startup timeout.
+// cfg must be validated.
+func New(ctx context.Context, cfg *config.Config) (*App, error) {
+	pc, err := cfg.Database.PoolConfig()
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	db, err := infra.OpenDB(ctx, pc, cfg.Database.StartupTimeout)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.WithMessage("database is unreachable"))
+	}
+	util.SetSecretKey(cfg.Auth.SecretKey)
+
+	return &App{
+		Config:  cfg,
+		DB:      db,
+		Metrics: metrics.New(db),
+		Now:     time.Now,
+	}, nil
+}
+
+// Ready checks that the database answers within the readiness timeout.
+func (a *App) Ready(ctx context.Context) error {
+	return infra.PingDB(ctx, a.DB, a.Config.Database.ReadyTimeout)
+}
+
+// Close closes the pool after the connections in use are returned.
+func (a *App) Close() {
+	a.DB.Close()
+}
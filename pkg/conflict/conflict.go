@@ -0,0 +1,112 @@
+// Package conflict finds work entries of an employee on the same day that cannot both be right.
+package conflict
+
+import (
+	"sort"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+const (
+	// KindOverlap is two entries whose clock times overlap.
+	KindOverlap = "overlap"
+	// KindDuplicateAttendance is two attendance marks.
+	KindDuplicateAttendance = "duplicate_attendance"
+	// KindMultipleEntries is a second entry at a workplace that allows one entry per day.
+	KindMultipleEntries = "multiple_entries"
+)
+
+// Entry is a work entry of an employee.
+type Entry struct {
+	ID         int64
+	EmployeeID int64
+	// Date is the day of the entry, at midnight UTC.
+	Date time.Time
+	// Start and End are set for entries with clock times. End before Start means the entry runs past midnight.
+	Start, End time.Duration
+	Timed      bool
+	// Attendance is set for entries that mark attendance or absence.
+	Attendance bool
+}
+
+// Conflict is a pair of entries of the same employee and day.
+type Conflict struct {
+	Kind       string
+	EmployeeID int64
+	Date       time.Time
+	// EntryID is the later entry and OtherID the earlier one it conflicts with.
+	EntryID, OtherID int64
+}
+
+func span(e Entry) (time.Duration, time.Duration) {
+	if e.End <= e.Start {
+		return e.Start, e.End + day
+	}
+	return e.Start, e.End
+}
+
+func kind(a, b Entry, allowMultiple bool) (string, bool) {
+	if a.Attendance && b.Attendance {
+		return KindDuplicateAttendance, true
+	}
+	if a.Timed && b.Timed {
+		as, ae := span(a)
+		bs, be := span(b)
+		if as < be && bs < ae {
+			return KindOverlap, true
+		}
+	}
+	if !allowMultiple {
+		return KindMultipleEntries, true
+	}
+	return "", false
+}
+
+// Check returns the first conflict of entry with the entries already recorded for its employee and day.
+// Several entries that do not overlap are accepted only if allowMultiple is set.
+func Check(existing []Entry, entry Entry, allowMultiple bool) (Conflict, bool) {
+	for _, e := range existing {
+		if e.EmployeeID != entry.EmployeeID || !e.Date.Equal(entry.Date) || e.ID == entry.ID {
+			continue
+		}
+		if k, ok := kind(e, entry, allowMultiple); ok {
+			return Conflict{Kind: k, EmployeeID: entry.EmployeeID, Date: entry.Date, EntryID: entry.ID, OtherID: e.ID}, true
+		}
+	}
+	return Conflict{}, false
+}
+
+// Find reports every conflicting pair of entries, ordered by date, employee and entry.
+func Find(entries []Entry, allowMultiple bool) []Conflict {
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		if a.EmployeeID != b.EmployeeID {
+			return a.EmployeeID < b.EmployeeID
+		}
+		return a.ID < b.ID
+	})
+
+	var res []Conflict
+	for i := 0; i < len(sorted); {
+		employeeID, date := sorted[i].EmployeeID, sorted[i].Date
+		j := i + 1
+		for j < len(sorted) && sorted[j].EmployeeID == employeeID && sorted[j].Date.Equal(date) {
+			j++
+		}
+		for l := i + 1; l < j; l++ {
+			for _, e := range sorted[i:l] {
+				if kd, ok := kind(e, sorted[l], allowMultiple); ok {
+					res = append(res, Conflict{Kind: kd, EmployeeID: employeeID, Date: date, EntryID: sorted[l].ID, OtherID: e.ID})
+				}
+			}
+		}
+		i = j
+	}
+	return res
+}
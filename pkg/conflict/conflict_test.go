@@ -0,0 +1,99 @@
+package conflict_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mio256/wplus-server/pkg/conflict"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestCheck(t *testing.T) {
+	h := time.Hour
+	d := date("2024-04-01")
+	morning := conflict.Entry{ID: 1, EmployeeID: 1, Date: d, Start: 9 * h, End: 12 * h, Timed: true}
+	night := conflict.Entry{ID: 2, EmployeeID: 1, Date: d, Start: 22 * h, End: 2 * h, Timed: true}
+	present := conflict.Entry{ID: 3, EmployeeID: 1, Date: d, Attendance: true}
+
+	tests := map[string]struct {
+		Existing      []conflict.Entry
+		Entry         conflict.Entry
+		AllowMultiple bool
+		Want          string
+	}{
+		"first": {
+			Entry: conflict.Entry{EmployeeID: 1, Date: d, Start: 9 * h, End: 17 * h, Timed: true},
+		},
+		"overlap": {
+			Existing:      []conflict.Entry{morning},
+			Entry:         conflict.Entry{EmployeeID: 1, Date: d, Start: 11 * h, End: 15 * h, Timed: true},
+			AllowMultiple: true,
+			Want:          conflict.KindOverlap,
+		},
+		"adjacent": {
+			Existing:      []conflict.Entry{morning},
+			Entry:         conflict.Entry{EmployeeID: 1, Date: d, Start: 12 * h, End: 15 * h, Timed: true},
+			AllowMultiple: true,
+		},
+		"past-midnight": {
+			Existing:      []conflict.Entry{night},
+			Entry:         conflict.Entry{EmployeeID: 1, Date: d, Start: 23 * h, End: 1 * h, Timed: true},
+			AllowMultiple: true,
+			Want:          conflict.KindOverlap,
+		},
+		"multiple": {
+			Existing: []conflict.Entry{morning},
+			Entry:    conflict.Entry{EmployeeID: 1, Date: d, Start: 13 * h, End: 17 * h, Timed: true},
+			Want:     conflict.KindMultipleEntries,
+		},
+		"duplicate-attendance": {
+			Existing:      []conflict.Entry{present},
+			Entry:         conflict.Entry{EmployeeID: 1, Date: d, Attendance: true},
+			AllowMultiple: true,
+			Want:          conflict.KindDuplicateAttendance,
+		},
+		"other-day": {
+			Existing: []conflict.Entry{morning},
+			Entry:    conflict.Entry{EmployeeID: 1, Date: d.AddDate(0, 0, 1), Start: 9 * h, End: 12 * h, Timed: true},
+		},
+		"other-employee": {
+			Existing: []conflict.Entry{morning},
+			Entry:    conflict.Entry{EmployeeID: 2, Date: d, Start: 9 * h, End: 12 * h, Timed: true},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := conflict.Check(tt.Existing, tt.Entry, tt.AllowMultiple)
+			assert.Equal(t, tt.Want != "", ok)
+			assert.Equal(t, tt.Want, got.Kind)
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	h := time.Hour
+	entries := []conflict.Entry{
+		{ID: 4, EmployeeID: 1, Date: date("2024-04-02"), Attendance: true},
+		{ID: 1, EmployeeID: 1, Date: date("2024-04-01"), Start: 9 * h, End: 12 * h, Timed: true},
+		{ID: 2, EmployeeID: 1, Date: date("2024-04-01"), Start: 11 * h, End: 13 * h, Timed: true},
+		{ID: 3, EmployeeID: 1, Date: date("2024-04-01"), Start: 13 * h, End: 17 * h, Timed: true},
+		{ID: 5, EmployeeID: 1, Date: date("2024-04-02"), Attendance: true},
+		{ID: 6, EmployeeID: 2, Date: date("2024-04-01"), Start: 9 * h, End: 12 * h, Timed: true},
+	}
+
+	assert.Equal(t, []conflict.Conflict{
+		{Kind: conflict.KindOverlap, EmployeeID: 1, Date: date("2024-04-01"), EntryID: 2, OtherID: 1},
+		{Kind: conflict.KindDuplicateAttendance, EmployeeID: 1, Date: date("2024-04-02"), EntryID: 5, OtherID: 4},
+	}, conflict.Find(entries, true))
+
+	assert.Len(t, conflict.Find(entries, false), 4)
+}
@@ -0,0 +1,58 @@
+package export
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/holiday"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/taxio/errors"
+)
+
+const (
+	HolidayKindNational = "national"
+	HolidayKindClosure  = "closure"
+)
+
+// Holiday is a national holiday or a closure day of an office.
+type Holiday struct {
+	Date pgtype.Date `json:"date"`
+	Name string      `json:"name"`
+	Kind string      `json:"kind"`
+}
+
+// Holidays returns the national holidays and the closure days of an office between from and to, inclusive, in date order.
+// A closure day on a national holiday is returned after the holiday.
+func Holidays(ctx context.Context, repo *rdb.Queries, officeID int64, from, to pgtype.Date) ([]Holiday, error) {
+	closures, err := repo.GetOfficeClosures(ctx, rdb.GetOfficeClosuresParams{
+		OfficeID: officeID,
+		FromDate: from,
+		ToDate:   to,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	var res []Holiday
+	for _, h := range holiday.Between(from.Time, to.Time) {
+		res = append(res, Holiday{Date: pgtype.Date{Time: h.Date, Valid: true}, Name: h.Name, Kind: HolidayKindNational})
+	}
+	for _, c := range closures {
+		res = append(res, Holiday{Date: c.Date, Name: c.Name, Kind: HolidayKindClosure})
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Date.Time.Before(res[j].Date.Time) })
+	return res, nil
+}
+
+// IsHoliday reports whether the office is closed on date, either for a national holiday or a closure day.
+func IsHoliday(ctx context.Context, repo *rdb.Queries, officeID int64, date pgtype.Date) (Holiday, bool, error) {
+	res, err := Holidays(ctx, repo, officeID, date, date)
+	if err != nil {
+		return Holiday{}, false, errors.Wrap(err)
+	}
+	if len(res) == 0 {
+		return Holiday{}, false, nil
+	}
+	return res[0], true, nil
+}
@@ -0,0 +1,405 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/overtime"
+	"github.com/mio256/wplus-server/pkg/summary"
+	"github.com/mio256/wplus-server/pkg/tracing"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/taxio/errors"
+	"github.com/xuri/excelize/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const Template = "resource/template.xlsx"
+const Sheet = "Sheet1"
+
+// overtimeColumn is the first column (AK) of the overtime breakdown, next to the totals of the template.
+const overtimeColumn = 37
+
+var tracer = tracing.Tracer("export")
+
+var overtimeHeaders = []string{"法定外(日)", "法定外(週)", "深夜", "法定休日"}
+
+// paidLeaveColumn is the column (AO) of the paid leave days, after the overtime breakdown.
+var paidLeaveColumn = overtimeColumn + len(overtimeHeaders)
+
+// holidayFills are the background colors of the day columns of holidays by kind.
+var holidayFills = map[string]string{
+	HolidayKindNational: "FCE4D6",
+	HolidayKindClosure:  "E2EFDA",
+}
+
+// Row is an employee line of the monthly sheet.
+type Row struct {
+	EmployeeID int64
+	Name       string
+	// Hours is the number of hours worked, indexed by day of month. Days with only an attendance mark have zero,
+	// so that the day counts of the template match summary.Totals.DaysWorked.
+	Hours map[int]float64
+	// Overtime is the categorized time of the employee in the month, including the other workplaces.
+	Overtime overtime.Result
+	// Leave is the marker of the approved leave type, indexed by day of month.
+	Leave map[int]string
+	// PaidLeave is the number of approved paid leave days in the month.
+	PaidLeave int
+}
+
+// OvertimeRules returns the overtime rules of an office, or the statutory rules when it has none.
+func OvertimeRules(ctx context.Context, repo *rdb.Queries, officeID int64) (overtime.Rules, error) {
+	row, err := repo.GetOvertimeRules(ctx, officeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return overtime.DefaultRules(), nil
+	} else if err != nil {
+		return overtime.Rules{}, errors.Wrap(err)
+	}
+	return overtime.Rules{
+		DailyLimit:   time.Duration(row.DailyLimitMinutes) * time.Minute,
+		WeeklyLimit:  time.Duration(row.WeeklyLimitMinutes) * time.Minute,
+		NightStart:   util.ClockDuration(row.NightStart.Time),
+		NightEnd:     util.ClockDuration(row.NightEnd.Time),
+		LegalHoliday: time.Weekday(row.LegalHoliday),
+		WeekStart:    time.Weekday(row.WeekStart),
+	}, nil
+}
+
+// Overtime categorizes the worked time of the employees of an office between from and to, inclusive.
+// employeeID narrows it down to one employee when it is valid.
+func Overtime(ctx context.Context, repo *rdb.Queries, officeID int64, employeeID pgtype.Int8, rules overtime.Rules, from, to pgtype.Date) (map[int64]overtime.Result, error) {
+	entries, err := repo.GetWorkEntriesForOvertime(ctx, rdb.GetWorkEntriesForOvertimeParams{
+		OfficeID:   officeID,
+		FromDate:   util.NewDate(rules.WeekStartOf(from.Time)),
+		ToDate:     to,
+		EmployeeID: employeeID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	byEmployee := map[int64][]overtime.Entry{}
+	for _, e := range entries {
+		byEmployee[e.EmployeeID] = append(byEmployee[e.EmployeeID], overtime.NewEntry(e.Date, e.Hours, e.StartTime, e.EndTime))
+	}
+	res := make(map[int64]overtime.Result, len(byEmployee))
+	for id, es := range byEmployee {
+		res[id] = overtime.Calculate(rules, es, from.Time, to.Time)
+	}
+	return res, nil
+}
+
+// WorkplaceRows returns a line for every employee who was assigned to the workplace at some point of the month,
+// ordered by the reading of their names, with the hours they worked there.
+func WorkplaceRows(ctx context.Context, repo *rdb.Queries, workplace rdb.Workplace, year int, month time.Month) ([]Row, error) {
+	minDate, maxDate := util.MonthRange(year, month)
+
+	employees, err := repo.OutputEmployeesByWorkplaceAndDate(ctx, rdb.OutputEmployeesByWorkplaceAndDateParams{
+		WorkplaceID: workplace.ID,
+		MaxDate:     maxDate,
+		MinDate:     minDate,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	entries, err := repo.OutputWorkEntriesByWorkplaceAndDate(ctx, rdb.OutputWorkEntriesByWorkplaceAndDateParams{
+		ID:      workplace.ID,
+		MinDate: minDate,
+		MaxDate: maxDate,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	var rows []Row
+	index := map[int64]int{}
+	add := func(id int64, name string) {
+		if _, ok := index[id]; ok {
+			return
+		}
+		index[id] = len(rows)
+		rows = append(rows, Row{EmployeeID: id, Name: name, Hours: map[int]float64{}, Leave: map[int]string{}})
+	}
+	for _, e := range employees {
+		add(e.ID, e.Name)
+	}
+	// entries of employees without a matching assignment are still exported after the others
+	worked := make([]summary.Entry, 0, len(entries))
+	for _, e := range entries {
+		add(e.EmployeeID, e.EmployeeName)
+		worked = append(worked, summary.NewEntry(e.EmployeeID, e.WorkplaceID, e.Date, e.Hours, e.StartTime, e.EndTime, e.Attendance))
+	}
+	for id, days := range summary.Days(worked) {
+		for date, d := range days {
+			rows[index[id]].Hours[date.Day()] = toHours(int(d / time.Minute))
+		}
+	}
+
+	rules, err := OvertimeRules(ctx, repo, workplace.OfficeID)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	results, err := Overtime(ctx, repo, workplace.OfficeID, pgtype.Int8{}, rules, minDate, maxDate)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	for i := range rows {
+		rows[i].Overtime = results[rows[i].EmployeeID]
+	}
+
+	leaves, err := repo.OutputApprovedLeaves(ctx, rdb.OutputApprovedLeavesParams{
+		OfficeID: workplace.OfficeID,
+		MinDate:  minDate,
+		MaxDate:  maxDate,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	for _, l := range leaves {
+		i, ok := index[l.EmployeeID]
+		if !ok {
+			continue
+		}
+		rows[i].Leave[l.Date.Time.Day()] = l.Marker
+		if l.Paid {
+			rows[i].PaidLeave++
+		}
+	}
+
+	return rows, nil
+}
+
+// WorkplaceMonth fills the monthly template with the work hours of a workplace.
+// Loading the rows, loading the holidays and rendering the sheet are traced as spans of their own.
+func WorkplaceMonth(ctx context.Context, repo *rdb.Queries, workplace rdb.Workplace, year int, month time.Month) (f *excelize.File, err error) {
+	ctx, span := tracer.Start(ctx, "export.WorkplaceMonth", trace.WithAttributes(
+		attribute.Int64("wplus.workplace_id", workplace.ID),
+		attribute.Int("wplus.year", year),
+		attribute.Int("wplus.month", int(month)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	rowsCtx, rowsSpan := tracer.Start(ctx, "export.rows")
+	rows, err := WorkplaceRows(rowsCtx, repo, workplace, year, month)
+	rowsSpan.SetAttributes(attribute.Int("wplus.rows", len(rows)))
+	tracing.End(rowsSpan, err)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	minDate, maxDate := util.MonthRange(year, month)
+	holidaysCtx, holidaysSpan := tracer.Start(ctx, "export.holidays")
+	holidays, err := Holidays(holidaysCtx, repo, workplace.OfficeID, minDate, maxDate)
+	tracing.End(holidaysSpan, err)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	_, renderSpan := tracer.Start(ctx, "export.render")
+	f, err = render(year, month, rows, holidays)
+	tracing.End(renderSpan, err)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return f, nil
+}
+
+// render fills the template with the rows and marks the holidays.
+func render(year int, month time.Month, rows []Row, holidays []Holiday) (*excelize.File, error) {
+	f, err := excelize.OpenFile(Template)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	if err := f.SetCellValue(Sheet, "C4", year); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if err := f.SetCellValue(Sheet, "E4", int(month)); err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	for i, header := range append(overtimeHeaders, "有給") {
+		// AK5-AO5
+		cell, err := excelize.CoordinatesToCellName(overtimeColumn+i, 5)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if err := f.SetCellValue(Sheet, cell, header); err != nil {
+			return nil, errors.Wrap(err)
+		}
+	}
+
+	for i, row := range rows {
+		// B{7+i*2}
+		nameCell, err := excelize.CoordinatesToCellName(2, 7+i*2)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if err := f.SetCellValue(Sheet, nameCell, row.Name); err != nil {
+			return nil, errors.Wrap(err)
+		}
+		for day, hours := range row.Hours {
+			// C{7+i*2}-AG{7+i*2}
+			hourCell, err := excelize.CoordinatesToCellName(3+day-1, 7+i*2)
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+			if err := f.SetCellValue(Sheet, hourCell, hours); err != nil {
+				return nil, errors.Wrap(err)
+			}
+		}
+		for day, marker := range row.Leave {
+			if _, ok := row.Hours[day]; ok {
+				continue
+			}
+			// the marker is text, so the sums and counts of the template leave it out
+			leaveCell, err := excelize.CoordinatesToCellName(3+day-1, 7+i*2)
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+			if err := f.SetCellValue(Sheet, leaveCell, marker); err != nil {
+				return nil, errors.Wrap(err)
+			}
+		}
+		for _, d := range row.Overtime.Days {
+			if d.Overtime() == 0 {
+				continue
+			}
+			// C{8+i*2}-AG{8+i*2}
+			overtimeCell, err := excelize.CoordinatesToCellName(3+d.Date.Day()-1, 8+i*2)
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+			if err := f.SetCellValue(Sheet, overtimeCell, toHours(d.Overtime())); err != nil {
+				return nil, errors.Wrap(err)
+			}
+		}
+		total := row.Overtime.Total
+		for j, m := range []int{total.DailyOvertime, total.WeeklyOvertime, total.LateNight, total.LegalHoliday} {
+			// AK{7+i*2}-AN{7+i*2}
+			cell, err := excelize.CoordinatesToCellName(overtimeColumn+j, 7+i*2)
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+			if err := f.SetCellValue(Sheet, cell, toHours(m)); err != nil {
+				return nil, errors.Wrap(err)
+			}
+		}
+		// AO{7+i*2}
+		paidLeaveCell, err := excelize.CoordinatesToCellName(paidLeaveColumn, 7+i*2)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if err := f.SetCellValue(Sheet, paidLeaveCell, row.PaidLeave); err != nil {
+			return nil, errors.Wrap(err)
+		}
+	}
+
+	// the header rows and the two rows of every employee
+	if err := markHolidays(f, holidays, 6+len(rows)*2); err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	if f.WorkBook != nil && f.WorkBook.CalcPr != nil {
+		f.WorkBook.CalcPr.FullCalcOnLoad = true
+	}
+	return f, nil
+}
+
+// Encode writes f out as a span of its own and closes it.
+func Encode(ctx context.Context, f *excelize.File) (b *bytes.Buffer, err error) {
+	_, span := tracer.Start(ctx, "export.write")
+	defer func() { tracing.End(span, err) }()
+
+	b = new(bytes.Buffer)
+	if err := f.Write(b); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	span.SetAttributes(attribute.Int("wplus.size", b.Len()))
+	return b, nil
+}
+
+// markHolidays fills the day columns of the holidays from row 5 to lastRow and notes their names on the date cells.
+// A closure day on a national holiday is colored as a closure.
+func markHolidays(f *excelize.File, holidays []Holiday, lastRow int) error {
+	kinds := map[int]string{}
+	names := map[int][]string{}
+	var days []int
+	for _, h := range holidays {
+		day := h.Date.Time.Day()
+		if _, ok := kinds[day]; !ok {
+			days = append(days, day)
+		}
+		if kinds[day] != HolidayKindClosure {
+			kinds[day] = h.Kind
+		}
+		names[day] = append(names[day], h.Name)
+	}
+
+	type shade struct {
+		base int
+		kind string
+	}
+	styles := map[shade]int{}
+	for _, day := range days {
+		col := 3 + day - 1
+		for row := 5; row <= lastRow; row++ {
+			cell, err := excelize.CoordinatesToCellName(col, row)
+			if err != nil {
+				return errors.Wrap(err)
+			}
+			base, err := f.GetCellStyle(Sheet, cell)
+			if err != nil {
+				return errors.Wrap(err)
+			}
+			key := shade{base: base, kind: kinds[day]}
+			styleID, ok := styles[key]
+			if !ok {
+				// keep the borders and number format of the template
+				style, err := f.GetStyle(base)
+				if err != nil {
+					return errors.Wrap(err)
+				}
+				style.Fill = excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{holidayFills[key.kind]}}
+				if styleID, err = f.NewStyle(style); err != nil {
+					return errors.Wrap(err)
+				}
+				styles[key] = styleID
+			}
+			if err := f.SetCellStyle(Sheet, cell, cell, styleID); err != nil {
+				return errors.Wrap(err)
+			}
+		}
+
+		// C5-AG5
+		cell, err := excelize.CoordinatesToCellName(col, 5)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		if err := f.AddComment(Sheet, excelize.Comment{Cell: cell, Author: "wplus", Text: strings.Join(names[day], "\n")}); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// FileName returns the download name of a monthly sheet, stamped with the time it was made in loc.
+func FileName(workplaceName string, year int, month time.Month, now time.Time, loc *time.Location) string {
+	return fmt.Sprintf("%s-%d-%d_%s.xlsx", workplaceName, year, month, now.In(loc).Format("20060102150405"))
+}
+
+// toHours converts minutes to hours rounded to two decimal places.
+func toHours(minutes int) float64 {
+	return math.Round(float64(minutes)/60*100) / 100
+}
@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mio256/wplus-server/pkg/app"
+)
+
+// appOf returns the application the router was set up with.
+func appOf(c *gin.Context) *app.App {
+	return c.MustGet("app").(*app.App)
+}
+
+// now is the current time on the clock of the application.
+func now(c *gin.Context) time.Time {
+	return appOf(c).Now()
+}
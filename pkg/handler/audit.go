@@ -0,0 +1,328 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/metrics"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/taxio/errors"
+)
+
+const (
+	AuditActionCreate      = "create"
+	AuditActionUpdate      = "update"
+	AuditActionDelete      = "delete"
+	AuditActionRestore     = "restore"
+	AuditActionMove        = "move"
+	AuditActionLogin       = "login"
+	AuditActionLoginFailed = "login_failed"
+	AuditActionExport      = "export"
+)
+
+const (
+	AuditResourceWorkEntry          = "work_entry"
+	AuditResourceEmployee           = "employee"
+	AuditResourceEmployeeAssignment = "employee_assignment"
+	AuditResourceWorkplace          = "workplace"
+	AuditResourceUser               = "user"
+	AuditResourceLeaveGrant         = "leave_grant"
+	AuditResourceAuditLog           = "audit_log"
+)
+
+// redactedFields are left out of the before and after states.
+var redactedFields = []string{"password"}
+
+// auditEvent is a change to record in the audit log. Before and After are marshaled to JSON.
+type auditEvent struct {
+	Action     string
+	Resource   string
+	ResourceID int64
+	Before     any
+	After      any
+}
+
+// writeAudit records event by the user of the request. Pass the repository of the transaction that makes
+// the change so that the change is committed with its record or not at all.
+func writeAudit(c *gin.Context, repo *rdb.Queries, event auditEvent) error {
+	user := c.MustGet("user").(*util.UserClaims)
+	return writeAuditAs(c, repo, user, int64(user.OfficeID), event)
+}
+
+// writeAuditAs records event by actor in an office. actor is nil when nobody is signed in.
+func writeAuditAs(c *gin.Context, repo *rdb.Queries, actor *util.UserClaims, officeID int64, event auditEvent) error {
+	p := rdb.CreateAuditLogParams{
+		OfficeID: officeID,
+		Action:   event.Action,
+		Resource: event.Resource,
+	}
+	if actor != nil {
+		p.UserID = pgtype.Int8{Int64: int64(actor.UserID), Valid: true}
+		p.UserName = pgtype.Text{String: actor.Name, Valid: true}
+		p.UserRole = pgtype.Text{String: actor.Role, Valid: true}
+	}
+	if event.ResourceID != 0 {
+		p.ResourceID = pgtype.Int8{Int64: event.ResourceID, Valid: true}
+	}
+	var err error
+	if p.Before, err = auditState(event.Before); err != nil {
+		return errors.Wrap(err)
+	}
+	if p.After, err = auditState(event.After); err != nil {
+		return errors.Wrap(err)
+	}
+	if id := requestID(c); id != "" {
+		p.RequestID = pgtype.Text{String: id, Valid: true}
+	}
+
+	if err := repo.CreateAuditLog(c, p); err != nil {
+		return errors.Wrap(err)
+	}
+	return nil
+}
+
+// auditState marshals a state of a resource without the redacted fields. A nil state stays null.
+func auditState(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(b, &fields) != nil {
+		// not an object
+		return b, nil
+	}
+	for _, f := range redactedFields {
+		delete(fields, f)
+	}
+	b, err = json.Marshal(fields)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return b, nil
+}
+
+// AuditLogResponse is an audit log row with its time in the time zone of the office.
+type AuditLogResponse struct {
+	rdb.AuditLog
+	CreatedAt string `json:"created_at"`
+}
+
+// parseAuditLogsQuery reads the filters of the audit log. from and to are dates in the time zone of the office, inclusive.
+func parseAuditLogsQuery(c *gin.Context, officeID int64, loc *time.Location) (rdb.GetAuditLogsParams, error) {
+	p := rdb.GetAuditLogsParams{OfficeID: officeID}
+
+	var err error
+	p.PageLimit, p.PageOffset, err = parsePage(c)
+	if err != nil {
+		return p, errors.Wrap(err)
+	}
+
+	for name, v := range map[string]*pgtype.Int8{"user_id": &p.UserID, "resource_id": &p.ResourceID} {
+		if s := c.Query(name); s != "" {
+			id, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return p, errors.Wrap(err)
+			}
+			*v = pgtype.Int8{Int64: id, Valid: true}
+		}
+	}
+	if s := c.Query("action"); s != "" {
+		p.Action = pgtype.Text{String: s, Valid: true}
+	}
+	if s := c.Query("resource"); s != "" {
+		p.Resource = pgtype.Text{String: s, Valid: true}
+	}
+
+	// timestamps are stored in UTC
+	if s := c.Query("from"); s != "" {
+		from, err := util.ParseLocalDate(s, loc)
+		if err != nil {
+			return p, errors.Wrap(err)
+		}
+		t := from.Time
+		p.FromTime = pgtype.Timestamp{Time: time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc).UTC(), Valid: true}
+	}
+	if s := c.Query("to"); s != "" {
+		to, err := util.ParseLocalDate(s, loc)
+		if err != nil {
+			return p, errors.Wrap(err)
+		}
+		t := to.Time
+		p.ToTime = pgtype.Timestamp{Time: time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc).UTC(), Valid: true}
+	}
+	if p.FromTime.Valid && p.ToTime.Valid && !p.FromTime.Time.Before(p.ToTime.Time) {
+		return p, errors.New("from is after to")
+	}
+
+	return p, nil
+}
+
+func countAuditLogs(c *gin.Context, repo *rdb.Queries, p rdb.GetAuditLogsParams) (int64, error) {
+	return repo.CountAuditLogs(c, rdb.CountAuditLogsParams{
+		OfficeID:   p.OfficeID,
+		UserID:     p.UserID,
+		Action:     p.Action,
+		Resource:   p.Resource,
+		ResourceID: p.ResourceID,
+		FromTime:   p.FromTime,
+		ToTime:     p.ToTime,
+	})
+}
+
+// GetAuditLogs lists the audit log of the office, newest first, filtered by user_id, action, resource,
+// resource_id, from and to.
+func GetAuditLogs(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	if user.Role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin",
+		})
+		return
+	}
+
+	loc, err := officeLocation(c, repo, int64(user.OfficeID))
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	p, err := parseAuditLogsQuery(c, int64(user.OfficeID), loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+
+	logs, err := repo.GetAuditLogs(c, p)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	total, err := countAuditLogs(c, repo, p)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	items := make([]AuditLogResponse, 0, len(logs))
+	for _, l := range logs {
+		items = append(items, AuditLogResponse{AuditLog: l, CreatedAt: util.FormatTimestamp(l.CreatedAt, loc)})
+	}
+	c.JSON(http.StatusOK, ListResponse[AuditLogResponse]{
+		Items:      items,
+		Pagination: NewPagination(p.PageLimit, p.PageOffset, total),
+	})
+}
+
+var auditLogCSVHeader = []string{"id", "created_at", "user_id", "user_name", "user_role", "action", "resource", "resource_id", "request_id", "before", "after"}
+
+// GetAuditLogsCSV downloads every row of the audit log that matches the filters of GetAuditLogs, newest first.
+// limit and offset are ignored.
+func GetAuditLogsCSV(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	if user.Role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin",
+		})
+		return
+	}
+
+	loc, err := officeLocation(c, repo, int64(user.OfficeID))
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	p, err := parseAuditLogsQuery(c, int64(user.OfficeID), loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+	// the log only grows and is read newest first, so pin the end to keep new rows from shifting the pages
+	if !p.ToTime.Valid {
+		p.ToTime = pgtype.Timestamp{Time: now(c).UTC(), Valid: true}
+	}
+	p.PageLimit, p.PageOffset = MaxPageLimit, 0
+
+	// the download is recorded before it starts, as the log can no longer answer with an error once it streams
+	if err := writeAudit(c, repo, auditEvent{
+		Action:   AuditActionExport,
+		Resource: AuditResourceAuditLog,
+		After:    c.Request.URL.Query(),
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	name := fmt.Sprintf("audit_logs_%s.csv", now(c).In(loc).Format("20060102150405"))
+	c.Header("Content-Description", "File Transfer")
+	c.Header("Content-Disposition", "attachment; filename="+name)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	start := time.Now()
+	w := csv.NewWriter(c.Writer)
+	if err := w.Write(auditLogCSVHeader); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	for {
+		logs, err := repo.GetAuditLogs(c, p)
+		if err != nil {
+			// the header is already sent, so the download ends short
+			c.Error(errors.Wrap(err))
+			return
+		}
+		for _, l := range logs {
+			if err := w.Write([]string{
+				strconv.FormatInt(l.ID, 10),
+				util.FormatTimestamp(l.CreatedAt, loc),
+				csvInt8(l.UserID),
+				l.UserName.String,
+				l.UserRole.String,
+				l.Action,
+				l.Resource,
+				csvInt8(l.ResourceID),
+				l.RequestID.String,
+				string(l.Before),
+				string(l.After),
+			}); err != nil {
+				c.Error(errors.Wrap(err))
+				return
+			}
+		}
+		if len(logs) < int(p.PageLimit) {
+			break
+		}
+		p.PageOffset += p.PageLimit
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	appOf(c).Metrics.ObserveExport(metrics.ExportAuditLogsCSV, time.Since(start), c.Writer.Size())
+}
+
+func csvInt8(v pgtype.Int8) string {
+	if !v.Valid {
+		return ""
+	}
+	return strconv.FormatInt(v.Int64, 10)
+}
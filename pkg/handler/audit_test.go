@@ -0,0 +1,110 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-faker/faker/v4"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/handler"
+	"github.com/mio256/wplus-server/pkg/infra"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/test"
+	"github.com/mio256/wplus-server/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogs(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	dbConn := infra.ConnectDB(c)
+
+	office := test.CreateOffice(t, c, dbConn, nil)
+	workplace := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+	})
+	employee := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+		v.WorkplaceID = workplace.ID
+	})
+	admin, adminToken, _ := test.CreateUserWithToken(t, c, dbConn, func(v *rdb.User) {
+		v.OfficeID = office.ID
+		v.Role = rdb.UserTypeAdmin
+	})
+	_, managerToken, _ := test.CreateUserWithToken(t, c, dbConn, func(v *rdb.User) {
+		v.OfficeID = office.ID
+		v.Role = rdb.UserTypeManager
+		v.EmployeeID = pgtype.Int8{Int64: employee.ID, Valid: true}
+	})
+
+	do := func(method, path, token string, body []byte) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, path, bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		req.Header.Set("X-Request-ID", "audit-test")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	b, err := json.Marshal(rdb.CreateWorkplaceParams{
+		OfficeID: office.ID,
+		Name:     faker.Username(),
+		WorkType: rdb.WorkTypeHours,
+	})
+	require.NoError(t, err)
+	w = do("POST", ui.WorkplacePath, adminToken, b)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created rdb.Workplace
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	t.Cleanup(func() {
+		require.NoError(t, rdb.New(dbConn).TestDeleteWorkplace(c, created.ID))
+	})
+
+	w = do("GET", ui.AuditLogPath+"?resource=workplace&action=create", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var res handler.ListResponse[handler.AuditLogResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Len(t, res.Items, 1)
+	log := res.Items[0]
+	assert.Equal(t, created.ID, log.ResourceID.Int64)
+	assert.Equal(t, admin.ID, log.UserID.Int64)
+	assert.Equal(t, "audit-test", log.RequestID.String)
+	assert.JSONEq(t, "null", string(log.Before))
+	var after rdb.Workplace
+	require.NoError(t, json.Unmarshal(log.After, &after))
+	assert.Equal(t, created.Name, after.Name)
+	assert.NotEmpty(t, log.CreatedAt)
+	assert.Equal(t, int64(1), res.Pagination.Total)
+
+	w = do("GET", ui.AuditLogPath+"csv/?resource=workplace", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "id", records[0][0])
+	assert.Equal(t, "create", records[1][5])
+	// the download is recorded like the other exports
+	w = do("GET", ui.AuditLogPath+"?resource=audit_log&action=export", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Len(t, res.Items, 1)
+	assert.Equal(t, admin.ID, res.Items[0].UserID.Int64)
+	assert.JSONEq(t, `{"resource": ["workplace"]}`, string(res.Items[0].After))
+
+	w = do("GET", ui.AuditLogPath+"?from=2024-04-02&to=2024-04-01", adminToken, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = do("GET", ui.AuditLogPath, managerToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = do("GET", ui.AuditLogPath+"csv/", managerToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
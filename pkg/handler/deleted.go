@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/taxio/errors"
+)
+
+// deletedSince reads the days query parameter of the deleted item lists.
+// Items older than the retention period are purged, so days cannot go beyond it.
+func deletedSince(c *gin.Context) (pgtype.Timestamp, error) {
+	days := util.DeletedRetentionDays
+	if s := c.Query("days"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return pgtype.Timestamp{}, errors.Wrap(err)
+		}
+		if v < 1 || v > util.DeletedRetentionDays {
+			return pgtype.Timestamp{}, errors.New("days is out of range", errors.WithAttrs(errors.Attr("days", v)))
+		}
+		days = v
+	}
+	return util.DeletedSince(now(c), days), nil
+}
@@ -5,29 +5,35 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/mio256/wplus-server/pkg/infra/rdb"
 	"github.com/mio256/wplus-server/pkg/util"
 	"github.com/taxio/errors"
 )
 
 func GetEmployeesByOffice(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
+	repo := queries(c)
 
 	user := c.MustGet("user").(*util.UserClaims)
 
-	employees, err := repo.GetEmployeesByOffice(c, int64(user.OfficeID))
+	limit, offset, err := parsePage(c)
 	if err != nil {
-		c.Error(errors.Wrap(err))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, employees)
+	listEmployees(c, repo, rdb.ListEmployeesParams{
+		OfficeID:   int64(user.OfficeID),
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
 }
 
 func GetEmployees(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
+	repo := queries(c)
 
 	user := c.MustGet("user").(*util.UserClaims)
 
@@ -50,18 +56,43 @@ func GetEmployees(c *gin.Context) {
 		return
 	}
 
-	employees, err := repo.GetEmployees(c, workplaceID)
+	limit, offset, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+
+	listEmployees(c, repo, rdb.ListEmployeesParams{
+		OfficeID:    workplace.OfficeID,
+		WorkplaceID: pgtype.Int8{Int64: workplaceID, Valid: true},
+		PageLimit:   limit,
+		PageOffset:  offset,
+	})
+}
+
+func listEmployees(c *gin.Context, repo *rdb.Queries, p rdb.ListEmployeesParams) {
+	employees, err := repo.ListEmployees(c, p)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	total, err := repo.CountEmployees(c, rdb.CountEmployeesParams{
+		OfficeID:    p.OfficeID,
+		WorkplaceID: p.WorkplaceID,
+	})
 	if err != nil {
 		c.Error(errors.Wrap(err))
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, employees)
+	c.JSON(http.StatusOK, NewListResponse(employees, p.PageLimit, p.PageOffset, total))
 }
 
 func GetEmployee(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
+	repo := queries(c)
 
 	user := c.MustGet("user").(*util.UserClaims)
 
@@ -94,8 +125,7 @@ func GetEmployee(c *gin.Context) {
 }
 
 func PostEmployee(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
+	repo := queries(c)
 
 	user := c.MustGet("user").(*util.UserClaims)
 
@@ -111,6 +141,15 @@ func PostEmployee(c *gin.Context) {
 		c.Error(errors.Wrap(err))
 		return
 	}
+	if input.EmploymentType == "" {
+		input.EmploymentType = rdb.EmploymentTypeFullTime
+	}
+	if !validEmployeeProfile(input.Name, input.EmploymentType, input.HireDate, input.LeaveDate, input.HourlyWage) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid input",
+		})
+		return
+	}
 
 	workplace, err := repo.GetWorkplace(c, input.WorkplaceID)
 	if workplace.OfficeID != int64(user.OfficeID) {
@@ -125,14 +164,21 @@ func PostEmployee(c *gin.Context) {
 		c.Error(errors.Wrap(err))
 		return
 	}
-
+	if err := writeAudit(c, repo, auditEvent{
+		Action:     AuditActionCreate,
+		Resource:   AuditResourceEmployee,
+		ResourceID: employee.ID,
+		After:      employee,
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
 	c.IndentedJSON(http.StatusOK, employee)
 }
 
+// ChangeEmployeeWorkplace transfers an employee to another workplace from effective_from (today by default).
+// A future date schedules the transfer; the current workplace is switched by the assignment sync command on that day.
 func ChangeEmployeeWorkplace(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
-
 	user := c.MustGet("user").(*util.UserClaims)
 
 	if user.Role != "admin" {
@@ -147,6 +193,18 @@ func ChangeEmployeeWorkplace(c *gin.Context) {
 		c.Error(errors.Wrap(err))
 		return
 	}
+
+	var input struct {
+		WorkplaceID   int64  `json:"workplace_id"`
+		EffectiveFrom string `json:"effective_from"`
+	}
+	if err := c.BindJSON(&input); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	repo := queries(c)
+
 	officeID, err := repo.GetEmployeeOffice(c, id)
 	if err != nil {
 		c.Error(errors.Wrap(err))
@@ -159,46 +217,181 @@ func ChangeEmployeeWorkplace(c *gin.Context) {
 		return
 	}
 
-	var input struct {
-		WorkplaceID int64 `json:"workplace_id"`
+	workplace, err := repo.GetWorkplace(c, input.WorkplaceID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
 	}
-	if err := c.BindJSON(&input); err != nil {
+	if workplace.OfficeID != int64(user.OfficeID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "your office is different",
+		})
+		return
+	}
+
+	loc, err := officeLocation(c, repo, officeID)
+	if err != nil {
 		c.Error(errors.Wrap(err))
 		return
 	}
+	today := util.Today(loc)
+	effectiveFrom := today
+	if input.EffectiveFrom != "" {
+		effectiveFrom, err = util.ParseLocalDate(input.EffectiveFrom, loc)
+		if err != nil || effectiveFrom.Time.Before(today.Time) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid effective_from",
+			})
+			return
+		}
+	}
 
-	workplace, err := repo.GetWorkplace(c, input.WorkplaceID)
+	// locking the employee serializes transfers so that the history stays contiguous
+	employee, err := repo.GetEmployeeForUpdate(c, id)
 	if err != nil {
 		c.Error(errors.Wrap(err))
 		return
 	}
-	if workplace.OfficeID != int64(user.OfficeID) {
+	latest, err := latestAssignment(c, repo, employee)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if latest.WorkplaceID == workplace.ID {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "already assigned to the workplace",
+		})
+		return
+	}
+	if latest.EffectiveFrom.Valid && !latest.EffectiveFrom.Time.Before(effectiveFrom.Time) {
+		c.JSON(http.StatusConflict, gin.H{
+			"message": "a transfer is already scheduled on or after effective_from",
+		})
+		return
+	}
+
+	if _, err := transferEmployee(c, repo, employee, latest, workplace.ID, effectiveFrom); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	if !effectiveFrom.Time.After(today.Time) {
+		if err := repo.UpdateEmployeeWorkplace(c, rdb.UpdateEmployeeWorkplaceParams{
+			ID:          id,
+			WorkplaceID: workplace.ID,
+		}); err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+	}
+
+	employee, err = repo.GetEmployee(c, id)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, employee)
+}
+
+// PatchEmployee updates the profile of an employee. Fields that are not in the request body are left unchanged,
+// and fields given as null are cleared. Use ChangeEmployeeWorkplace to move an employee.
+func PatchEmployee(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+
+	if user.Role != "admin" {
 		c.JSON(http.StatusForbidden, gin.H{
-			"message": "your office is different",
+			"message": "you are not admin",
 		})
 		return
 	}
 
-	if err := repo.UpdateEmployeeWorkplace(c, rdb.UpdateEmployeeWorkplaceParams{
-		ID:          id,
-		WorkplaceID: input.WorkplaceID,
-	}); err != nil {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
 		c.Error(errors.Wrap(err))
 		return
 	}
 
+	employeeOfficeID, err := repo.GetEmployeeOffice(c, id)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if employeeOfficeID != int64(user.OfficeID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "your office is different",
+		})
+		return
+	}
+
 	employee, err := repo.GetEmployee(c, id)
 	if err != nil {
 		c.Error(errors.Wrap(err))
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, employee)
+	input := rdb.UpdateEmployeeProfileParams{
+		ID:             employee.ID,
+		Name:           employee.Name,
+		Code:           employee.Code,
+		NameKana:       employee.NameKana,
+		EmploymentType: employee.EmploymentType,
+		HireDate:       employee.HireDate,
+		LeaveDate:      employee.LeaveDate,
+		HourlyWage:     employee.HourlyWage,
+	}
+	if err := c.BindJSON(&input); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	input.ID = employee.ID
+	if !validEmployeeProfile(input.Name, input.EmploymentType, input.HireDate, input.LeaveDate, input.HourlyWage) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid input",
+		})
+		return
+	}
+
+	updated, err := repo.UpdateEmployeeProfile(c, input)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if err := writeAudit(c, repo, auditEvent{
+		Action:     AuditActionUpdate,
+		Resource:   AuditResourceEmployee,
+		ResourceID: updated.ID,
+		Before:     employee,
+		After:      updated,
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	c.IndentedJSON(http.StatusOK, updated)
+}
+
+func validEmployeeProfile(name string, employmentType rdb.EmploymentType, hireDate, leaveDate pgtype.Date, hourlyWage pgtype.Int4) bool {
+	if name == "" {
+		return false
+	}
+	switch employmentType {
+	case rdb.EmploymentTypeFullTime, rdb.EmploymentTypePartTime, rdb.EmploymentTypeContract, rdb.EmploymentTypeTemporary:
+	default:
+		return false
+	}
+	if hireDate.Valid && leaveDate.Valid && leaveDate.Time.Before(hireDate.Time) {
+		return false
+	}
+	if hourlyWage.Valid && hourlyWage.Int32 < 0 {
+		return false
+	}
+	return true
 }
 
 func DeleteEmployee(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
+	repo := queries(c)
 
 	user := c.MustGet("user").(*util.UserClaims)
 	if user.Role != "admin" {
@@ -226,6 +419,11 @@ func DeleteEmployee(c *gin.Context) {
 		return
 	}
 
+	employee, err := repo.GetEmployeeForUpdate(c, id)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
 	if err := repo.SoftDeleteEmployee(c, id); err != nil {
 		c.Error(errors.Wrap(err))
 		return
@@ -235,6 +433,139 @@ func DeleteEmployee(c *gin.Context) {
 		c.Error(errors.Wrap(err))
 		return
 	}
-
+	if err := writeAudit(c, repo, auditEvent{
+		Action:     AuditActionDelete,
+		Resource:   AuditResourceEmployee,
+		ResourceID: employee.ID,
+		Before:     employee,
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
 	c.Status(http.StatusNoContent)
 }
+
+type RestoreEmployeeResponse struct {
+	Employee rdb.Employee `json:"employee"`
+	// RestoredWorkEntries is the number of work entries that were deleted together with the employee.
+	RestoredWorkEntries int64 `json:"restored_work_entries"`
+}
+
+func GetDeletedEmployees(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	if user.Role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin",
+		})
+		return
+	}
+
+	since, err := deletedSince(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+
+	limit, offset, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+
+	employees, err := repo.GetDeletedEmployees(c, rdb.GetDeletedEmployeesParams{
+		OfficeID:     int64(user.OfficeID),
+		DeletedSince: since,
+		PageLimit:    limit,
+		PageOffset:   offset,
+	})
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	total, err := repo.CountDeletedEmployees(c, rdb.CountDeletedEmployeesParams{
+		OfficeID:     int64(user.OfficeID),
+		DeletedSince: since,
+	})
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewListResponse(employees, limit, offset, total))
+}
+
+// RestoreEmployee restores a deleted employee and the work entries that were deleted with them.
+// Work entries deleted on their own stay deleted.
+func RestoreEmployee(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	if user.Role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin",
+		})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	deleted, err := repo.GetDeletedEmployee(c, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "deleted employee not found",
+		})
+		return
+	} else if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	if deleted.OfficeID != int64(user.OfficeID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "your office is different",
+		})
+		return
+	}
+	if deleted.WorkplaceDeletedAt.Valid {
+		c.JSON(http.StatusConflict, gin.H{
+			"message": "workplace is deleted",
+		})
+		return
+	}
+
+	employee, err := repo.RestoreEmployee(c, id)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	restored, err := repo.RestoreWorkEntriesByEmployee(c, id)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if err := writeAudit(c, repo, auditEvent{
+		Action:     AuditActionRestore,
+		Resource:   AuditResourceEmployee,
+		ResourceID: employee.ID,
+		After:      employee,
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	c.IndentedJSON(http.StatusOK, RestoreEmployeeResponse{
+		Employee:            employee,
+		RestoredWorkEntries: restored,
+	})
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/taxio/errors"
+)
+
+// latestAssignment returns the open-ended assignment of an employee.
+// Employees without history get one that starts from the beginning at their current workplace.
+func latestAssignment(c *gin.Context, repo *rdb.Queries, employee rdb.Employee) (rdb.EmployeeAssignment, error) {
+	assignments, err := repo.GetEmployeeAssignments(c, employee.ID)
+	if err != nil {
+		return rdb.EmployeeAssignment{}, errors.Wrap(err)
+	}
+	if len(assignments) > 0 {
+		return assignments[len(assignments)-1], nil
+	}
+	a, err := repo.CreateEmployeeAssignment(c, rdb.CreateEmployeeAssignmentParams{
+		EmployeeID:  employee.ID,
+		WorkplaceID: employee.WorkplaceID,
+	})
+	if err != nil {
+		return rdb.EmployeeAssignment{}, errors.Wrap(err)
+	}
+	return a, nil
+}
+
+// transferEmployee closes the latest assignment of an employee the day before effectiveFrom and assigns them to
+// the workplace from then on. The employee must be locked, and latest must start before effectiveFrom.
+func transferEmployee(c *gin.Context, repo *rdb.Queries, employee rdb.Employee, latest rdb.EmployeeAssignment, workplaceID int64, effectiveFrom pgtype.Date) (rdb.EmployeeAssignment, error) {
+	if err := repo.UpdateEmployeeAssignmentEnd(c, rdb.UpdateEmployeeAssignmentEndParams{
+		ID:          latest.ID,
+		EffectiveTo: util.NewDate(effectiveFrom.Time.AddDate(0, 0, -1)),
+	}); err != nil {
+		return rdb.EmployeeAssignment{}, errors.Wrap(err)
+	}
+	assignment, err := repo.CreateEmployeeAssignment(c, rdb.CreateEmployeeAssignmentParams{
+		EmployeeID:    employee.ID,
+		WorkplaceID:   workplaceID,
+		EffectiveFrom: effectiveFrom,
+	})
+	if err != nil {
+		return rdb.EmployeeAssignment{}, errors.Wrap(err)
+	}
+	if err := writeAudit(c, repo, auditEvent{
+		Action:     AuditActionMove,
+		Resource:   AuditResourceEmployee,
+		ResourceID: employee.ID,
+		Before:     latest,
+		After:      assignment,
+	}); err != nil {
+		return rdb.EmployeeAssignment{}, errors.Wrap(err)
+	}
+	return assignment, nil
+}
+
+// employeeWorkplaceOn returns the workplace the employee was assigned to on the date.
+func employeeWorkplaceOn(c *gin.Context, repo *rdb.Queries, employee rdb.Employee, date pgtype.Date) (int64, error) {
+	workplaceID, err := repo.GetEmployeeWorkplaceOnDate(c, rdb.GetEmployeeWorkplaceOnDateParams{
+		EmployeeID: employee.ID,
+		OnDate:     date,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return employee.WorkplaceID, nil
+	} else if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	return workplaceID, nil
+}
+
+func GetEmployeeAssignments(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	employeeOfficeID, err := repo.GetEmployeeOffice(c, id)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if employeeOfficeID != int64(user.OfficeID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "your office is different",
+		})
+		return
+	}
+
+	assignments, err := repo.GetEmployeeAssignments(c, id)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if len(assignments) == 0 {
+		employee, err := repo.GetEmployee(c, id)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		assignments = append(assignments, rdb.EmployeeAssignment{
+			EmployeeID:  employee.ID,
+			WorkplaceID: employee.WorkplaceID,
+		})
+	}
+
+	c.IndentedJSON(http.StatusOK, assignments)
+}
+
+// CancelEmployeeAssignment cancels a scheduled transfer that has not started yet.
+func CancelEmployeeAssignment(c *gin.Context) {
+	user := c.MustGet("user").(*util.UserClaims)
+	if user.Role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin",
+		})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	assignmentID, err := strconv.ParseInt(c.Param("assignment_id"), 10, 64)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	repo := queries(c)
+
+	officeID, err := repo.GetEmployeeOffice(c, id)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if officeID != int64(user.OfficeID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "your office is different",
+		})
+		return
+	}
+	loc, err := officeLocation(c, repo, officeID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	if _, err := repo.GetEmployeeForUpdate(c, id); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	assignments, err := repo.GetEmployeeAssignments(c, id)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	// only the last assignment can be scheduled, and it always has a previous one
+	n := len(assignments)
+	if n < 2 || assignments[n-1].ID != assignmentID {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "scheduled transfer not found",
+		})
+		return
+	}
+	if !assignments[n-1].EffectiveFrom.Time.After(util.Today(loc).Time) {
+		c.JSON(http.StatusConflict, gin.H{
+			"message": "the transfer has already started",
+		})
+		return
+	}
+
+	if err := repo.DeleteEmployeeAssignment(c, assignmentID); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if err := repo.UpdateEmployeeAssignmentEnd(c, rdb.UpdateEmployeeAssignmentEndParams{
+		ID: assignments[n-2].ID,
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if err := writeAudit(c, repo, auditEvent{
+		Action:     AuditActionDelete,
+		Resource:   AuditResourceEmployeeAssignment,
+		ResourceID: assignmentID,
+		Before:     assignments[n-1],
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
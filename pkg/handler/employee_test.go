@@ -8,20 +8,23 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-faker/faker/v4"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/handler"
 	"github.com/mio256/wplus-server/pkg/infra"
 	"github.com/mio256/wplus-server/pkg/infra/rdb"
 	"github.com/mio256/wplus-server/pkg/test"
 	"github.com/mio256/wplus-server/pkg/ui"
+	"github.com/mio256/wplus-server/pkg/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestGetEmployeesByOffice(t *testing.T) {
-	router := ui.SetupRouter()
+	router := ui.SetupRouter(test.NewApp(t))
 	ctx := context.Background()
 	dbConn := infra.ConnectDB(ctx)
 
@@ -65,17 +68,17 @@ func TestGetEmployeesByOffice(t *testing.T) {
 			router.ServeHTTP(w, c.Request)
 
 			assert.Equal(t, http.StatusOK, w.Code)
-			var res []rdb.Employee
+			var res handler.ListResponse[rdb.Employee]
 			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
-			assert.NotEmpty(t, res)
-			assert.Contains(t, res, *tt.OursEmployee)
-			assert.NotContains(t, res, *tt.OthersEmployee)
+			assert.NotEmpty(t, res.Items)
+			assert.Contains(t, res.Items, *tt.OursEmployee)
+			assert.NotContains(t, res.Items, *tt.OthersEmployee)
 		})
 	}
 }
 
 func TestGetEmployees(t *testing.T) {
-	router := ui.SetupRouter()
+	router := ui.SetupRouter(test.NewApp(t))
 	ctx := context.Background()
 	dbConn := infra.ConnectDB(ctx)
 
@@ -130,17 +133,104 @@ func TestGetEmployees(t *testing.T) {
 			router.ServeHTTP(w, c.Request)
 
 			assert.Equal(t, http.StatusOK, w.Code)
-			var res []rdb.Employee
+			var res handler.ListResponse[rdb.Employee]
 			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
-			assert.NotEmpty(t, res)
-			assert.Contains(t, res, *tt.OursEmployee)
-			assert.NotContains(t, res, *tt.OthersEmployee)
+			assert.NotEmpty(t, res.Items)
+			assert.Contains(t, res.Items, *tt.OursEmployee)
+			assert.NotContains(t, res.Items, *tt.OthersEmployee)
+		})
+	}
+}
+
+func TestListEmployeesPagination(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+	ctx := context.Background()
+	dbConn := infra.ConnectDB(ctx)
+
+	workplace := test.CreateWorkplace(t, ctx, dbConn, nil)
+	var ids []int64
+	for _, name := range []string{"employee-a", "employee-b", "employee-c"} {
+		e := test.CreateEmployee(t, ctx, dbConn, func(v *rdb.Employee) {
+			v.Name = name
+			v.WorkplaceID = workplace.ID
+		})
+		ids = append(ids, e.ID)
+	}
+	_, token, _ := test.CreateUserWithToken(t, ctx, dbConn, func(v *rdb.User) {
+		v.OfficeID = workplace.OfficeID
+	})
+
+	tests := map[string]struct {
+		Path      string
+		WantCode  int
+		WantIDs   []int64
+		WantTotal int64
+		WantNext  bool
+	}{
+		"office": {
+			Path:      ui.EmployeePath + "?limit=2",
+			WantCode:  http.StatusOK,
+			WantIDs:   ids[:2],
+			WantTotal: 3,
+			WantNext:  true,
+		},
+		"office-last-page": {
+			Path:      ui.EmployeePath + "?limit=2&offset=2",
+			WantCode:  http.StatusOK,
+			WantIDs:   ids[2:],
+			WantTotal: 3,
+		},
+		"workplace": {
+			Path:      fmt.Sprintf("%sworkplace/%d/?offset=1", ui.EmployeePath, workplace.ID),
+			WantCode:  http.StatusOK,
+			WantIDs:   ids[1:],
+			WantTotal: 3,
+		},
+		"past-the-end": {
+			Path:      ui.EmployeePath + "?offset=10",
+			WantCode:  http.StatusOK,
+			WantIDs:   nil,
+			WantTotal: 3,
+		},
+		"invalid-limit": {
+			Path:     ui.EmployeePath + "?limit=1001",
+			WantCode: http.StatusBadRequest,
+		},
+		"invalid-offset": {
+			Path:     fmt.Sprintf("%sworkplace/%d/?offset=-1", ui.EmployeePath, workplace.ID),
+			WantCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", tt.Path, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.WantCode, w.Code)
+			if tt.WantCode != http.StatusOK {
+				return
+			}
+			var res handler.ListResponse[rdb.Employee]
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.NotNil(t, res.Items)
+			var got []int64
+			for _, e := range res.Items {
+				got = append(got, e.ID)
+			}
+			assert.Equal(t, tt.WantIDs, got)
+			assert.Equal(t, tt.WantTotal, res.Pagination.Total)
+			assert.Equal(t, tt.WantNext, res.Pagination.HasNext)
 		})
 	}
 }
 
 func TestGetEmployee(t *testing.T) {
-	router := ui.SetupRouter()
+	router := ui.SetupRouter(test.NewApp(t))
 	ctx := context.Background()
 	dbConn := infra.ConnectDB(ctx)
 
@@ -187,7 +277,7 @@ func TestGetEmployee(t *testing.T) {
 }
 
 func TestPostEmployee(t *testing.T) {
-	router := ui.SetupRouter()
+	router := ui.SetupRouter(test.NewApp(t))
 
 	tests := map[string]struct {
 		Role        rdb.UserType
@@ -271,7 +361,7 @@ func TestPostEmployee(t *testing.T) {
 }
 
 func TestChangeEmployeeWorkplace(t *testing.T) {
-	router := ui.SetupRouter()
+	router := ui.SetupRouter(test.NewApp(t))
 
 	tests := map[string]struct {
 		Role            rdb.UserType
@@ -370,8 +460,95 @@ func TestChangeEmployeeWorkplace(t *testing.T) {
 	}
 }
 
+func TestPatchEmployee(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	tests := map[string]struct {
+		Role        rdb.UserType
+		OtherOffice bool
+		Body        string
+		WantCode    int
+	}{
+		"admin": {
+			Role:     rdb.UserTypeAdmin,
+			Body:     `{"name": "山田 太郎", "name_kana": "やまだ たろう", "code": "E001", "employment_type": "part_time", "hire_date": "2024-04-01", "hourly_wage": 1100}`,
+			WantCode: http.StatusOK,
+		},
+		"admin-invalid-leave-date": {
+			Role:     rdb.UserTypeAdmin,
+			Body:     `{"hire_date": "2024-04-01", "leave_date": "2024-03-31"}`,
+			WantCode: http.StatusBadRequest,
+		},
+		"admin-invalid-employment-type": {
+			Role:     rdb.UserTypeAdmin,
+			Body:     `{"employment_type": "volunteer"}`,
+			WantCode: http.StatusBadRequest,
+		},
+		"admin-other-office": {
+			Role:        rdb.UserTypeAdmin,
+			OtherOffice: true,
+			Body:        `{"name": "山田 太郎"}`,
+			WantCode:    http.StatusForbidden,
+		},
+		"manager": {
+			Role:     rdb.UserTypeManager,
+			Body:     `{"name": "山田 太郎"}`,
+			WantCode: http.StatusForbidden,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			dbConn := infra.ConnectDB(c)
+
+			office := test.CreateOffice(t, c, dbConn, nil)
+			workplace := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+				v.OfficeID = office.ID
+			})
+			employee := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+				v.WorkplaceID = workplace.ID
+			})
+			_, token, _ := test.CreateUserWithToken(t, c, dbConn, func(v *rdb.User) {
+				if tt.OtherOffice {
+					v.OfficeID = test.CreateOffice(t, c, dbConn, nil).ID
+				} else {
+					v.OfficeID = office.ID
+				}
+				v.Role = tt.Role
+				if tt.Role == rdb.UserTypeManager || tt.Role == rdb.UserTypeEmployee {
+					v.EmployeeID = pgtype.Int8{Int64: employee.ID, Valid: true}
+				}
+			})
+
+			var err error
+			c.Request, err = http.NewRequest("PATCH", fmt.Sprintf("%s%d/", ui.EmployeePath, employee.ID), bytes.NewBufferString(tt.Body))
+			require.NoError(t, err)
+			c.Request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			router.ServeHTTP(w, c.Request)
+
+			require.Equal(t, tt.WantCode, w.Code)
+			if tt.WantCode == http.StatusOK {
+				var res rdb.Employee
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+				assert.Equal(t, employee.ID, res.ID)
+				assert.Equal(t, employee.WorkplaceID, res.WorkplaceID)
+				assert.Equal(t, "山田 太郎", res.Name)
+				assert.Equal(t, "やまだ たろう", res.NameKana.String)
+				assert.Equal(t, "E001", res.Code.String)
+				assert.Equal(t, rdb.EmploymentTypePartTime, res.EmploymentType)
+				assert.Equal(t, "2024-04-01", res.HireDate.Time.Format("2006-01-02"))
+				assert.False(t, res.LeaveDate.Valid)
+				assert.Equal(t, int32(1100), res.HourlyWage.Int32)
+			}
+		})
+	}
+}
+
 func TestDeleteEmployee(t *testing.T) {
-	router := ui.SetupRouter()
+	router := ui.SetupRouter(test.NewApp(t))
 
 	tests := map[string]struct {
 		Role        rdb.UserType
@@ -444,3 +621,204 @@ func TestDeleteEmployee(t *testing.T) {
 		})
 	}
 }
+
+func TestRestoreEmployee(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	tests := map[string]struct {
+		Role             rdb.UserType
+		OtherOffice      bool
+		DeleteWorkplace  bool
+		WantCode         int
+		WantRestoredRows int64
+	}{
+		"admin": {
+			Role:             rdb.UserTypeAdmin,
+			WantCode:         http.StatusOK,
+			WantRestoredRows: 1,
+		},
+		"admin-other-office": {
+			Role:        rdb.UserTypeAdmin,
+			OtherOffice: true,
+			WantCode:    http.StatusForbidden,
+		},
+		"admin-workplace-deleted": {
+			Role:            rdb.UserTypeAdmin,
+			DeleteWorkplace: true,
+			WantCode:        http.StatusConflict,
+		},
+		"manager": {
+			Role:     rdb.UserTypeManager,
+			WantCode: http.StatusForbidden,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			dbConn := infra.ConnectDB(c)
+			repo := rdb.New(dbConn)
+
+			office := test.CreateOffice(t, c, dbConn, nil)
+			workplace := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+				v.OfficeID = office.ID
+			})
+			employee := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+				v.WorkplaceID = workplace.ID
+			})
+			_, token, _ := test.CreateUserWithToken(t, c, dbConn, func(v *rdb.User) {
+				if tt.OtherOffice {
+					v.OfficeID = test.CreateOffice(t, c, dbConn, nil).ID
+				} else {
+					v.OfficeID = office.ID
+				}
+				v.Role = tt.Role
+				if tt.Role == rdb.UserTypeManager {
+					v.EmployeeID = pgtype.Int8{Int64: employee.ID, Valid: true}
+				}
+			})
+
+			// deleted on its own before the employee, so it must stay deleted
+			separate := test.CreateWorkEntries(t, c, dbConn, func(v *rdb.WorkEntry) {
+				v.EmployeeID = employee.ID
+				v.WorkplaceID = workplace.ID
+			})
+			require.NoError(t, repo.SoftDeleteWorkEntry(c, separate.ID))
+			cascaded := test.CreateWorkEntries(t, c, dbConn, func(v *rdb.WorkEntry) {
+				v.EmployeeID = employee.ID
+				v.WorkplaceID = workplace.ID
+			})
+			require.NoError(t, repo.SoftDeleteEmployee(c, employee.ID))
+			require.NoError(t, repo.SoftDeleteWorkEntriesByEmployee(c, employee.ID))
+			if tt.DeleteWorkplace {
+				require.NoError(t, repo.SoftDeleteWorkplace(c, workplace.ID))
+			}
+
+			var err error
+			c.Request, err = http.NewRequest("POST", fmt.Sprintf("%s%d/restore/", ui.EmployeePath, employee.ID), nil)
+			require.NoError(t, err)
+			c.Request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			router.ServeHTTP(w, c.Request)
+
+			assert.Equal(t, tt.WantCode, w.Code)
+			if tt.WantCode != http.StatusOK {
+				assert.NotEmpty(t, test.GetDeletedAtEmployee(t, c, dbConn, employee.ID))
+				return
+			}
+
+			var res handler.RestoreEmployeeResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			assert.Equal(t, employee.ID, res.Employee.ID)
+			assert.Equal(t, tt.WantRestoredRows, res.RestoredWorkEntries)
+
+			_, err = repo.TestGetEmployee(c, employee.ID)
+			assert.NoError(t, err)
+			deletedAt, err := repo.TestGetDeletedAtWorkEntry(c, cascaded.ID)
+			require.NoError(t, err)
+			assert.False(t, deletedAt.Valid)
+			assert.NotEmpty(t, test.GetDeletedAtWorkEntry(t, c, dbConn, separate.ID))
+		})
+	}
+}
+
+func TestScheduleEmployeeTransfer(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	dbConn := infra.ConnectDB(c)
+
+	office := test.CreateOffice(t, c, dbConn, nil)
+	newWp := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+		v.WorkType = rdb.WorkTypeHours
+	})
+	workplace := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+		v.WorkType = rdb.WorkTypeHours
+	})
+	employee := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+		v.WorkplaceID = workplace.ID
+	})
+	_, token, _ := test.CreateUserWithToken(t, c, dbConn, func(v *rdb.User) {
+		v.OfficeID = office.ID
+		v.Role = rdb.UserTypeAdmin
+	})
+
+	loc, err := util.LoadLocation(office.TimeZone)
+	require.NoError(t, err)
+	today := time.Now().In(loc)
+	tomorrow := today.AddDate(0, 0, 1)
+
+	serve := func(method, path string, body any) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		req, err := http.NewRequest(method, path, bytes.NewBuffer(b))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		router.ServeHTTP(w, req)
+		return w
+	}
+	postEntry := func(workplaceID int64, date time.Time) int {
+		w := serve("POST", ui.WorkEntryPath, handler.PostWorkEntryParams{
+			EmployeeID:  employee.ID,
+			WorkplaceID: workplaceID,
+			Date:        date.Format(util.DateLayout),
+			Hours:       8,
+		})
+		if w.Code == http.StatusOK {
+			var res handler.WorkEntryResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			t.Cleanup(func() {
+				require.NoError(t, rdb.New(dbConn).TestDeleteWorkEntry(c, res.ID))
+			})
+		}
+		return w.Code
+	}
+
+	// schedule the transfer for tomorrow
+	res := serve("PUT", fmt.Sprintf("%s%d/", ui.EmployeePath, employee.ID), gin.H{
+		"workplace_id":   newWp.ID,
+		"effective_from": tomorrow.Format(util.DateLayout),
+	})
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+	var updated rdb.Employee
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &updated))
+	assert.Equal(t, workplace.ID, updated.WorkplaceID)
+
+	res = serve("GET", fmt.Sprintf("%s%d/assignments/", ui.EmployeePath, employee.ID), nil)
+	require.Equal(t, http.StatusOK, res.Code)
+	var assignments []rdb.EmployeeAssignment
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &assignments))
+	require.Len(t, assignments, 2)
+	assert.Equal(t, workplace.ID, assignments[0].WorkplaceID)
+	assert.False(t, assignments[0].EffectiveFrom.Valid)
+	assert.Equal(t, today.Format(util.DateLayout), util.FormatDate(assignments[0].EffectiveTo))
+	assert.Equal(t, newWp.ID, assignments[1].WorkplaceID)
+	assert.Equal(t, tomorrow.Format(util.DateLayout), util.FormatDate(assignments[1].EffectiveFrom))
+
+	// entries follow the assignment valid on their date
+	assert.Equal(t, http.StatusOK, postEntry(workplace.ID, today))
+	assert.Equal(t, http.StatusBadRequest, postEntry(newWp.ID, today))
+	assert.Equal(t, http.StatusOK, postEntry(newWp.ID, tomorrow))
+	assert.Equal(t, http.StatusBadRequest, postEntry(workplace.ID, tomorrow))
+
+	// a transfer in the past cannot be made
+	res = serve("PUT", fmt.Sprintf("%s%d/", ui.EmployeePath, employee.ID), gin.H{
+		"workplace_id":   newWp.ID,
+		"effective_from": today.AddDate(0, 0, -1).Format(util.DateLayout),
+	})
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+
+	// cancel the scheduled transfer
+	res = serve("DELETE", fmt.Sprintf("%s%d/assignments/%d/", ui.EmployeePath, employee.ID, assignments[1].ID), nil)
+	require.Equal(t, http.StatusNoContent, res.Code, res.Body.String())
+	res = serve("GET", fmt.Sprintf("%s%d/assignments/", ui.EmployeePath, employee.ID), nil)
+	require.Equal(t, http.StatusOK, res.Code)
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &assignments))
+	require.Len(t, assignments, 1)
+	assert.False(t, assignments[0].EffectiveTo.Valid)
+}
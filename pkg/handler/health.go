@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taxio/errors"
+)
+
+// GetLiveness answers as long as the process serves requests. It does not touch the database,
+// so a database outage does not get the server restarted.
+func GetLiveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+	})
+}
+
+// GetReadiness answers 503 while the database does not answer within the readiness timeout,
+// so that no requests are routed to the server.
+func GetReadiness(c *gin.Context) {
+	if err := appOf(c).Ready(c); err != nil {
+		c.Error(errors.Wrap(err))
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "unavailable",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+	})
+}
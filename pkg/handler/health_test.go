@@ -0,0 +1,62 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mio256/wplus-server/pkg/app"
+	"github.com/mio256/wplus-server/pkg/config"
+	"github.com/mio256/wplus-server/pkg/metrics"
+	"github.com/mio256/wplus-server/pkg/test"
+	"github.com/mio256/wplus-server/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth(t *testing.T) {
+	// nothing listens on the port, so the pool cannot connect
+	down, err := pgxpool.New(context.Background(), "host=127.0.0.1 port=1 user=nobody connect_timeout=1")
+	require.NoError(t, err)
+	t.Cleanup(down.Close)
+
+	tests := map[string]struct {
+		App           *app.App
+		WantLiveness  int
+		WantReadiness int
+	}{
+		"up": {
+			App:           test.NewApp(t),
+			WantLiveness:  http.StatusOK,
+			WantReadiness: http.StatusOK,
+		},
+		"database-down": {
+			App:           &app.App{Config: &config.Config{Database: config.Database{ReadyTimeout: time.Second}}, DB: down, Metrics: metrics.New(down), Now: time.Now},
+			WantLiveness:  http.StatusOK,
+			WantReadiness: http.StatusServiceUnavailable,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			router := ui.SetupRouter(tt.App)
+
+			for path, want := range map[string]int{
+				ui.LivenessPath:  tt.WantLiveness,
+				ui.ReadinessPath: tt.WantReadiness,
+				ui.PingPath:      tt.WantLiveness,
+				ui.DBPingPath:    tt.WantReadiness,
+			} {
+				req, err := http.NewRequest("GET", path, nil)
+				require.NoError(t, err)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				assert.Equal(t, want, w.Code, path)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/export"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/taxio/errors"
+)
+
+// maxPeriodDays limits the period of holiday and leave lists.
+const maxPeriodDays = 366
+
+// isUniqueViolation reports whether err was caused by a unique constraint.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// periodQuery reads the from and to queries, which default to the current year of the office.
+func periodQuery(c *gin.Context, loc *time.Location) (pgtype.Date, pgtype.Date, bool) {
+	today := util.Today(loc).Time
+	from := util.NewDate(time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, time.UTC))
+	to := util.NewDate(time.Date(today.Year(), time.December, 31, 0, 0, 0, 0, time.UTC))
+	var err error
+	if s := c.Query("from"); s != "" {
+		if from, err = util.ParseLocalDate(s, loc); err != nil {
+			return from, to, false
+		}
+	}
+	if s := c.Query("to"); s != "" {
+		if to, err = util.ParseLocalDate(s, loc); err != nil {
+			return from, to, false
+		}
+	}
+	if to.Time.Before(from.Time) || to.Time.Sub(from.Time) >= maxPeriodDays*24*time.Hour {
+		return from, to, false
+	}
+	return from, to, true
+}
+
+// GetHolidays returns the national holidays and the closure days of the office between from and to (this year by default).
+func GetHolidays(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	officeID := int64(user.OfficeID)
+
+	loc, err := officeLocation(c, repo, officeID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	from, to, ok := periodQuery(c, loc)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+
+	holidays, err := export.Holidays(c, repo, officeID, from, to)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if holidays == nil {
+		holidays = []export.Holiday{}
+	}
+
+	c.JSON(http.StatusOK, holidays)
+}
+
+type HolidayResponse struct {
+	Date    pgtype.Date `json:"date"`
+	Holiday bool        `json:"holiday"`
+	Name    string      `json:"name"`
+	Kind    string      `json:"kind"`
+}
+
+// GetHoliday tells whether the office is closed on a day.
+func GetHoliday(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	officeID := int64(user.OfficeID)
+
+	loc, err := officeLocation(c, repo, officeID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	date, err := util.ParseLocalDate(c.Param("date"), loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+
+	h, ok, err := export.IsHoliday(c, repo, officeID, date)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, HolidayResponse{
+		Date:    date,
+		Holiday: ok,
+		Name:    h.Name,
+		Kind:    h.Kind,
+	})
+}
+
+func GetOfficeClosures(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	officeID := int64(user.OfficeID)
+
+	loc, err := officeLocation(c, repo, officeID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	from, to, ok := periodQuery(c, loc)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+
+	closures, err := repo.GetOfficeClosures(c, rdb.GetOfficeClosuresParams{
+		OfficeID: officeID,
+		FromDate: from,
+		ToDate:   to,
+	})
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if closures == nil {
+		closures = []rdb.OfficeClosure{}
+	}
+
+	c.JSON(http.StatusOK, closures)
+}
+
+func PostOfficeClosure(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	if user.Role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin",
+		})
+		return
+	}
+	officeID := int64(user.OfficeID)
+
+	var input struct {
+		Date string `json:"date"`
+		Name string `json:"name"`
+	}
+	if err := c.BindJSON(&input); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	loc, err := officeLocation(c, repo, officeID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	date, err := util.ParseLocalDate(input.Date, loc)
+	if err != nil || input.Name == "" || len(input.Name) > 255 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid input",
+		})
+		return
+	}
+
+	closure, err := repo.CreateOfficeClosure(c, rdb.CreateOfficeClosureParams{
+		OfficeID: officeID,
+		Date:     date,
+		Name:     input.Name,
+	})
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{
+			"message": "closure already exists",
+		})
+		return
+	} else if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.IndentedJSON(http.StatusCreated, closure)
+}
+
+func DeleteOfficeClosure(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	if user.Role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin",
+		})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	closure, err := repo.DeleteOfficeClosure(c, rdb.DeleteOfficeClosureParams{
+		ID:       id,
+		OfficeID: int64(user.OfficeID),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "closure not found",
+		})
+		return
+	} else if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, closure)
+}
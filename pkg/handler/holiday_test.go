@@ -0,0 +1,138 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mio256/wplus-server/pkg/export"
+	"github.com/mio256/wplus-server/pkg/handler"
+	"github.com/mio256/wplus-server/pkg/infra"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/test"
+	"github.com/mio256/wplus-server/pkg/ui"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostOfficeClosure(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	tests := map[string]struct {
+		Role     rdb.UserType
+		Body     map[string]any
+		WantCode int
+	}{
+		"admin": {
+			Role:     rdb.UserTypeAdmin,
+			Body:     map[string]any{"date": "2024-08-14", "name": "夏季休業"},
+			WantCode: http.StatusCreated,
+		},
+		"admin-duplicate": {
+			Role:     rdb.UserTypeAdmin,
+			Body:     map[string]any{"date": "2024-12-30", "name": "年末休業"},
+			WantCode: http.StatusConflict,
+		},
+		"admin-no-name": {
+			Role:     rdb.UserTypeAdmin,
+			Body:     map[string]any{"date": "2024-08-14"},
+			WantCode: http.StatusBadRequest,
+		},
+		"manager": {
+			Role:     rdb.UserTypeManager,
+			Body:     map[string]any{"date": "2024-08-14", "name": "夏季休業"},
+			WantCode: http.StatusForbidden,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			dbConn := infra.ConnectDB(c)
+
+			office := test.CreateOffice(t, c, dbConn, nil)
+			test.CreateOfficeClosure(t, c, dbConn, func(v *rdb.OfficeClosure) {
+				v.OfficeID = office.ID
+				v.Date = util.NewDate(time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC))
+			})
+			token, err := util.GenerateToken(util.UserClaims{
+				OfficeID: uint64(office.ID),
+				Role:     string(tt.Role),
+			})
+			require.NoError(t, err)
+
+			b, err := json.Marshal(tt.Body)
+			require.NoError(t, err)
+			c.Request, err = http.NewRequest("POST", ui.OfficePath+"closures/", bytes.NewBuffer(b))
+			require.NoError(t, err)
+			c.Request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			router.ServeHTTP(w, c.Request)
+
+			require.Equal(t, tt.WantCode, w.Code)
+			if tt.WantCode == http.StatusCreated {
+				var res rdb.OfficeClosure
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+				assert.Equal(t, office.ID, res.OfficeID)
+				assert.Equal(t, "2024-08-14", util.FormatDate(res.Date))
+				assert.Equal(t, "夏季休業", res.Name)
+			}
+		})
+	}
+}
+
+func TestGetHolidays(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	dbConn := infra.ConnectDB(c)
+
+	office := test.CreateOffice(t, c, dbConn, nil)
+	test.CreateOfficeClosure(t, c, dbConn, func(v *rdb.OfficeClosure) {
+		v.OfficeID = office.ID
+		v.Date = util.NewDate(time.Date(2024, 9, 20, 0, 0, 0, 0, time.UTC))
+	})
+	token, err := util.GenerateToken(util.UserClaims{
+		OfficeID: uint64(office.ID),
+		Role:     string(rdb.UserTypeEmployee),
+	})
+	require.NoError(t, err)
+
+	c.Request, err = http.NewRequest("GET", ui.HolidayPath+"?from=2024-09-01&to=2024-09-30", nil)
+	require.NoError(t, err)
+	c.Request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	router.ServeHTTP(w, c.Request)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var res []export.Holiday
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Len(t, res, 4)
+	assert.Equal(t, "2024-09-16", util.FormatDate(res[0].Date))
+	assert.Equal(t, export.HolidayKindNational, res[0].Kind)
+	assert.Equal(t, "2024-09-20", util.FormatDate(res[1].Date))
+	assert.Equal(t, export.HolidayKindClosure, res[1].Kind)
+	assert.Equal(t, "2024-09-22", util.FormatDate(res[2].Date))
+	assert.Equal(t, "2024-09-23", util.FormatDate(res[3].Date))
+	assert.Equal(t, "振替休日", res[3].Name)
+
+	for date, want := range map[string]bool{"2024-09-20": true, "2024-09-23": true, "2024-09-24": false} {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", ui.HolidayPath+date+"/", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var res handler.HolidayResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		assert.Equal(t, want, res.Holiday, date)
+	}
+}
@@ -0,0 +1,749 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/export"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/leave"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/taxio/errors"
+)
+
+// maxLeaveRequestDays limits the days of a leave request.
+const maxLeaveRequestDays = 31
+
+// authorizeEmployee responds with 403 and returns false unless the user may see the employee:
+// admins see their office, managers their workplace and employees themselves.
+func authorizeEmployee(c *gin.Context, repo *rdb.Queries, user *util.UserClaims, employee rdb.Employee) bool {
+	switch user.Role {
+	case "admin":
+		officeID, err := repo.GetEmployeeOffice(c, employee.ID)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return false
+		}
+		if officeID != int64(user.OfficeID) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": "your office is different",
+			})
+			return false
+		}
+	case "manager":
+		if user.EmployeeID == 0 {
+			c.Error(errors.New("user is not manager: employee_id is not set"))
+			return false
+		}
+		me, err := repo.GetEmployee(c, int64(user.EmployeeID))
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return false
+		}
+		if employee.WorkplaceID != me.WorkplaceID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": "your workplace is different",
+			})
+			return false
+		}
+	default:
+		if user.EmployeeID == 0 {
+			c.Error(errors.New("user is not employee: employee_id is not set"))
+			return false
+		}
+		if employee.ID != int64(user.EmployeeID) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": "your employee is different",
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// syncLeaveGrants returns all grants of an employee, oldest first, with the statutory grants that are due by today.
+// The due grants are recorded and audited when the request runs in a transaction, and only returned otherwise with
+// no ID, so that reading a balance writes nothing. Part-time employees only have the grants added by an admin.
+func syncLeaveGrants(c *gin.Context, repo *rdb.Queries, employee rdb.Employee, today pgtype.Date) ([]rdb.LeaveGrant, error) {
+	grants, err := repo.GetLeaveGrants(c, employee.ID)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if !employee.HireDate.Valid || employee.EmploymentType == rdb.EmploymentTypePartTime {
+		return grants, nil
+	}
+
+	until := today.Time
+	if employee.LeaveDate.Valid && employee.LeaveDate.Time.Before(until) {
+		until = employee.LeaveDate.Time
+	}
+	granted := map[time.Time]bool{}
+	for _, g := range grants {
+		granted[g.GrantedOn.Time] = true
+	}
+	_, inTx := c.Get(txKey)
+	added := false
+	for _, g := range leave.Schedule(employee.HireDate.Time, until) {
+		if granted[g.GrantedOn] {
+			continue
+		}
+		p := rdb.CreateLeaveGrantParams{
+			EmployeeID: employee.ID,
+			GrantedOn:  util.NewDate(g.GrantedOn),
+			ExpiresOn:  util.NewDate(g.ExpiresOn),
+			Days:       int16(g.Days),
+		}
+		if !inTx {
+			grants = append(grants, rdb.LeaveGrant{EmployeeID: p.EmployeeID, GrantedOn: p.GrantedOn, ExpiresOn: p.ExpiresOn, Days: p.Days})
+			continue
+		}
+		grant, err := repo.CreateLeaveGrant(c, p)
+		// another request has added it in the meantime
+		if errors.Is(err, pgx.ErrNoRows) {
+			added = true
+			continue
+		} else if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if err := writeAudit(c, repo, auditEvent{
+			Action:     AuditActionCreate,
+			Resource:   AuditResourceLeaveGrant,
+			ResourceID: grant.ID,
+			After:      grant,
+		}); err != nil {
+			return nil, errors.Wrap(err)
+		}
+		added = true
+	}
+	if !added {
+		sort.Slice(grants, func(i, j int) bool { return grants[i].GrantedOn.Time.Before(grants[j].GrantedOn.Time) })
+		return grants, nil
+	}
+	grants, err = repo.GetLeaveGrants(c, employee.ID)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return grants, nil
+}
+
+// paidLeave returns the paid leave balance of an employee on today.
+// Pending requests are counted as taken when withPending is set, and extra days are taken on top of the requests.
+func paidLeave(c *gin.Context, repo *rdb.Queries, employee rdb.Employee, today pgtype.Date, withPending bool, extra ...time.Time) (leave.Balance, []rdb.LeaveGrant, int, error) {
+	grants, err := syncLeaveGrants(c, repo, employee, today)
+	if err != nil {
+		return leave.Balance{}, nil, 0, errors.Wrap(err)
+	}
+	dates, err := repo.GetPaidLeaveDates(c, employee.ID)
+	if err != nil {
+		return leave.Balance{}, nil, 0, errors.Wrap(err)
+	}
+
+	gs := make([]leave.Grant, 0, len(grants))
+	for _, g := range grants {
+		gs = append(gs, leave.Grant{GrantedOn: g.GrantedOn.Time, ExpiresOn: g.ExpiresOn.Time, Days: int(g.Days)})
+	}
+	var taken []time.Time
+	pending := 0
+	for _, d := range dates {
+		if d.Status == rdb.LeaveStatusPending {
+			pending++
+			if !withPending {
+				continue
+			}
+		}
+		taken = append(taken, d.Date.Time)
+	}
+	taken = append(taken, extra...)
+	return leave.Calculate(gs, taken, today.Time), grants, pending, nil
+}
+
+func GetLeaveTypes(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+
+	types, err := repo.GetLeaveTypes(c, int64(user.OfficeID))
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if types == nil {
+		types = []rdb.LeaveType{}
+	}
+
+	c.JSON(http.StatusOK, types)
+}
+
+// PostLeaveType adds a leave type to the office. The marker defaults to the first letter of the name.
+func PostLeaveType(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	if user.Role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin",
+		})
+		return
+	}
+
+	var input struct {
+		Name   string `json:"name"`
+		Paid   bool   `json:"paid"`
+		Marker string `json:"marker"`
+	}
+	if err := c.BindJSON(&input); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if input.Marker == "" && input.Name != "" {
+		r, _ := utf8.DecodeRuneInString(input.Name)
+		input.Marker = string(r)
+	}
+	if input.Name == "" || len(input.Name) > 255 || utf8.RuneCountInString(input.Marker) > 4 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid input",
+		})
+		return
+	}
+
+	leaveType, err := repo.CreateLeaveType(c, rdb.CreateLeaveTypeParams{
+		OfficeID: int64(user.OfficeID),
+		Name:     input.Name,
+		Paid:     input.Paid,
+		Marker:   input.Marker,
+	})
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{
+			"message": "leave type already exists",
+		})
+		return
+	} else if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.IndentedJSON(http.StatusCreated, leaveType)
+}
+
+type LeaveGrantResponse struct {
+	ID        int64       `json:"id"`
+	GrantedOn pgtype.Date `json:"granted_on"`
+	ExpiresOn pgtype.Date `json:"expires_on"`
+	Days      int         `json:"days"`
+	Used      int         `json:"used"`
+	Remaining int         `json:"remaining"`
+	Expired   bool        `json:"expired"`
+}
+
+type LeaveBalanceResponse struct {
+	EmployeeID int64       `json:"employee_id"`
+	Date       pgtype.Date `json:"date"`
+	// Available is the number of paid leave days left after the approved requests.
+	Available int `json:"available"`
+	// Pending is the number of paid leave days waiting for approval.
+	Pending int                  `json:"pending"`
+	Grants  []LeaveGrantResponse `json:"grants"`
+}
+
+// GetLeaveBalance returns the paid leave of an employee today, counting the statutory grants that became due.
+func GetLeaveBalance(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	employee, err := repo.GetEmployee(c, id)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if !authorizeEmployee(c, repo, user, employee) {
+		return
+	}
+
+	loc, err := officeLocation(c, repo, int64(user.OfficeID))
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	today := util.Today(loc)
+
+	balance, grants, pending, err := paidLeave(c, repo, employee, today, false)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	res := LeaveBalanceResponse{
+		EmployeeID: employee.ID,
+		Date:       today,
+		Available:  balance.Available,
+		Pending:    pending,
+		Grants:     make([]LeaveGrantResponse, 0, len(grants)),
+	}
+	// both are ordered by the grant date
+	for i, u := range balance.Grants {
+		res.Grants = append(res.Grants, LeaveGrantResponse{
+			ID:        grants[i].ID,
+			GrantedOn: grants[i].GrantedOn,
+			ExpiresOn: grants[i].ExpiresOn,
+			Days:      u.Days,
+			Used:      u.Used,
+			Remaining: u.Remaining(),
+			Expired:   today.Time.After(u.ExpiresOn),
+		})
+	}
+
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// PostLeaveGrant adds paid leave to an employee by hand, such as the proportional grants of part-time employees.
+// The grant expires two years later unless expires_on is given.
+func PostLeaveGrant(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	if user.Role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin",
+		})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	employee, err := repo.GetEmployee(c, id)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if !authorizeEmployee(c, repo, user, employee) {
+		return
+	}
+
+	var input struct {
+		GrantedOn string `json:"granted_on"`
+		ExpiresOn string `json:"expires_on"`
+		Days      int16  `json:"days"`
+	}
+	if err := c.BindJSON(&input); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	loc, err := officeLocation(c, repo, int64(user.OfficeID))
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	grantedOn, err := util.ParseLocalDate(input.GrantedOn, loc)
+	if err != nil || input.Days <= 0 || input.Days > 40 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid input",
+		})
+		return
+	}
+	expiresOn := util.NewDate(leave.Expiry(grantedOn.Time))
+	if input.ExpiresOn != "" {
+		if expiresOn, err = util.ParseLocalDate(input.ExpiresOn, loc); err != nil || expiresOn.Time.Before(grantedOn.Time) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid input",
+			})
+			return
+		}
+	}
+
+	grant, err := repo.CreateLeaveGrant(c, rdb.CreateLeaveGrantParams{
+		EmployeeID: employee.ID,
+		GrantedOn:  grantedOn,
+		ExpiresOn:  expiresOn,
+		Days:       input.Days,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{
+			"message": "leave is already granted on the date",
+		})
+		return
+	} else if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if err := writeAudit(c, repo, auditEvent{
+		Action:     AuditActionCreate,
+		Resource:   AuditResourceLeaveGrant,
+		ResourceID: grant.ID,
+		After:      grant,
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.IndentedJSON(http.StatusCreated, grant)
+}
+
+// GetLeaveRequests returns the leave requests between from and to (this year by default).
+// Admins see their office, managers their workplace and employees themselves. status and employee_id narrow them down.
+func GetLeaveRequests(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+
+	loc, err := officeLocation(c, repo, int64(user.OfficeID))
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	from, to, ok := periodQuery(c, loc)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+
+	p := rdb.GetLeaveRequestsParams{
+		OfficeID: int64(user.OfficeID),
+		FromDate: from,
+		ToDate:   to,
+	}
+	if s := c.Query("status"); s != "" {
+		switch status := rdb.LeaveStatus(s); status {
+		case rdb.LeaveStatusPending, rdb.LeaveStatusApproved, rdb.LeaveStatusRejected, rdb.LeaveStatusCancelled:
+			p.Status = rdb.NullLeaveStatus{LeaveStatus: status, Valid: true}
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid query",
+			})
+			return
+		}
+	}
+	if s := c.Query("employee_id"); s != "" {
+		employeeID, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid query",
+			})
+			return
+		}
+		p.EmployeeID = pgtype.Int8{Int64: employeeID, Valid: true}
+	}
+
+	switch user.Role {
+	case "admin":
+	case "manager":
+		if user.EmployeeID == 0 {
+			c.Error(errors.New("user is not manager: employee_id is not set"))
+			return
+		}
+		me, err := repo.GetEmployee(c, int64(user.EmployeeID))
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		p.WorkplaceID = pgtype.Int8{Int64: me.WorkplaceID, Valid: true}
+	default:
+		if user.EmployeeID == 0 {
+			c.Error(errors.New("user is not employee: employee_id is not set"))
+			return
+		}
+		p.EmployeeID = pgtype.Int8{Int64: int64(user.EmployeeID), Valid: true}
+	}
+
+	requests, err := repo.GetLeaveRequests(c, p)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if requests == nil {
+		requests = []rdb.GetLeaveRequestsRow{}
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+// PostLeaveRequest requests leave for the days from from to to, skipping holidays, closure days and the legal holiday.
+// Employees request for themselves, and paid leave must be covered by the grants valid on each day.
+func PostLeaveRequest(c *gin.Context) {
+	user := c.MustGet("user").(*util.UserClaims)
+
+	var input struct {
+		EmployeeID  int64  `json:"employee_id"`
+		LeaveTypeID int64  `json:"leave_type_id"`
+		From        string `json:"from"`
+		To          string `json:"to"`
+		Comment     string `json:"comment"`
+	}
+	if err := c.BindJSON(&input); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if input.EmployeeID == 0 {
+		input.EmployeeID = int64(user.EmployeeID)
+	}
+
+	repo := queries(c)
+
+	// locking the employee serializes the requests that draw on the same balance
+	employee, err := repo.GetEmployeeForUpdate(c, input.EmployeeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid input",
+		})
+		return
+	} else if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if !authorizeEmployee(c, repo, user, employee) {
+		return
+	}
+
+	leaveType, err := repo.GetLeaveType(c, input.LeaveTypeID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && leaveType.OfficeID != int64(user.OfficeID)) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid input",
+		})
+		return
+	} else if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	loc, err := officeLocation(c, repo, int64(user.OfficeID))
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	from, err := util.ParseLocalDate(input.From, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid input",
+		})
+		return
+	}
+	to := from
+	if input.To != "" {
+		if to, err = util.ParseLocalDate(input.To, loc); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid input",
+			})
+			return
+		}
+	}
+	if to.Time.Before(from.Time) || to.Time.Sub(from.Time) >= maxLeaveRequestDays*24*time.Hour || len(input.Comment) > 255 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid input",
+		})
+		return
+	}
+
+	holidays, err := export.Holidays(c, repo, int64(user.OfficeID), from, to)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	rules, err := export.OvertimeRules(c, repo, int64(user.OfficeID))
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	off := map[time.Time]bool{}
+	for _, h := range holidays {
+		off[h.Date.Time] = true
+	}
+	var days []time.Time
+	for d := from.Time; !d.After(to.Time); d = d.AddDate(0, 0, 1) {
+		if off[d] || d.Weekday() == rules.LegalHoliday {
+			continue
+		}
+		days = append(days, d)
+	}
+	if len(days) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "no working days in the period",
+		})
+		return
+	}
+
+	if leaveType.Paid {
+		today := util.Today(loc)
+		before, _, _, err := paidLeave(c, repo, employee, today, true)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		after, _, _, err := paidLeave(c, repo, employee, today, true, days...)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		if after.Overdrawn > before.Overdrawn {
+			c.JSON(http.StatusConflict, gin.H{
+				"message":   "not enough paid leave",
+				"available": before.Available,
+			})
+			return
+		}
+	}
+
+	comment := pgtype.Text{String: input.Comment, Valid: input.Comment != ""}
+	res := make([]rdb.LeaveRequest, 0, len(days))
+	for _, d := range days {
+		r, err := repo.CreateLeaveRequest(c, rdb.CreateLeaveRequestParams{
+			EmployeeID:  employee.ID,
+			LeaveTypeID: leaveType.ID,
+			Date:        util.NewDate(d),
+			Comment:     comment,
+		})
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{
+				"message": "leave is already requested",
+				"date":    util.NewDate(d),
+			})
+			return
+		} else if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		res = append(res, r)
+	}
+
+	c.IndentedJSON(http.StatusCreated, res)
+}
+
+func ApproveLeaveRequest(c *gin.Context) {
+	decideLeaveRequest(c, rdb.LeaveStatusApproved)
+}
+
+func RejectLeaveRequest(c *gin.Context) {
+	decideLeaveRequest(c, rdb.LeaveStatusRejected)
+}
+
+func CancelLeaveRequest(c *gin.Context) {
+	decideLeaveRequest(c, rdb.LeaveStatusCancelled)
+}
+
+// decideLeaveRequest moves a request to status. Managers of the workplace and admins approve or reject pending
+// requests, but not their own. Employees cancel their own requests and admins cancel any, even after approval.
+func decideLeaveRequest(c *gin.Context, status rdb.LeaveStatus) {
+	user := c.MustGet("user").(*util.UserClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	repo := queries(c)
+
+	request, err := repo.GetLeaveRequestForUpdate(c, id)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && request.OfficeID != int64(user.OfficeID)) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "leave request not found",
+		})
+		return
+	} else if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	own := request.EmployeeID == int64(user.EmployeeID)
+	if status == rdb.LeaveStatusCancelled {
+		if !own && user.Role != "admin" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": "you cannot cancel this request",
+			})
+			return
+		}
+		if request.Status != rdb.LeaveStatusPending && request.Status != rdb.LeaveStatusApproved {
+			c.JSON(http.StatusConflict, gin.H{
+				"message": "leave request is already closed",
+			})
+			return
+		}
+	} else {
+		switch {
+		case user.Role == "admin":
+		case user.Role == "manager" && !own:
+			me, err := repo.GetEmployee(c, int64(user.EmployeeID))
+			if err != nil {
+				c.Error(errors.Wrap(err))
+				return
+			}
+			if request.WorkplaceID != me.WorkplaceID {
+				c.JSON(http.StatusForbidden, gin.H{
+					"message": "your workplace is different",
+				})
+				return
+			}
+		default:
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": "you cannot decide this request",
+			})
+			return
+		}
+		if request.Status != rdb.LeaveStatusPending {
+			c.JSON(http.StatusConflict, gin.H{
+				"message": "leave request is not pending",
+			})
+			return
+		}
+	}
+
+	if status == rdb.LeaveStatusApproved && request.Paid {
+		// grants may have expired since the request was made
+		employee, err := repo.GetEmployeeForUpdate(c, request.EmployeeID)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		loc, err := officeLocation(c, repo, int64(user.OfficeID))
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		today := util.Today(loc)
+		before, _, _, err := paidLeave(c, repo, employee, today, false)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		after, _, _, err := paidLeave(c, repo, employee, today, false, request.Date.Time)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		if after.Overdrawn > before.Overdrawn {
+			c.JSON(http.StatusConflict, gin.H{
+				"message":   "not enough paid leave",
+				"available": before.Available,
+			})
+			return
+		}
+	}
+
+	decided, err := repo.DecideLeaveRequest(c, rdb.DecideLeaveRequestParams{
+		ID:        request.ID,
+		Status:    status,
+		DecidedBy: pgtype.Int8{Int64: int64(user.UserID), Valid: true},
+	})
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, decided)
+}
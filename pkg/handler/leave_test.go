@@ -0,0 +1,165 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mio256/wplus-server/pkg/handler"
+	"github.com/mio256/wplus-server/pkg/infra"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/test"
+	"github.com/mio256/wplus-server/pkg/ui"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaveRequest(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	dbConn := infra.ConnectDB(c)
+
+	loc, err := util.LoadLocation(util.DefaultTimeZone)
+	require.NoError(t, err)
+	today := util.Today(loc).Time
+
+	office := test.CreateOffice(t, c, dbConn, nil)
+	workplace := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+	})
+	employee := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+		v.WorkplaceID = workplace.ID
+	})
+	// hired three years ago: the grants after 6 months (expired), 1.5 years and 2.5 years
+	_, err = rdb.New(dbConn).UpdateEmployeeProfile(c, rdb.UpdateEmployeeProfileParams{
+		ID:             employee.ID,
+		Name:           employee.Name,
+		EmploymentType: rdb.EmploymentTypeFullTime,
+		HireDate:       util.NewDate(today.AddDate(-3, 0, 0)),
+	})
+	require.NoError(t, err)
+	newcomer := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+		v.WorkplaceID = workplace.ID
+	})
+	manager := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+		v.WorkplaceID = workplace.ID
+	})
+
+	token := func(claims util.UserClaims) string {
+		claims.OfficeID = uint64(office.ID)
+		s, err := util.GenerateToken(claims)
+		require.NoError(t, err)
+		return s
+	}
+	adminToken := token(util.UserClaims{Role: string(rdb.UserTypeAdmin)})
+	employeeToken := token(util.UserClaims{Role: string(rdb.UserTypeEmployee), EmployeeID: uint64(employee.ID), WorkplaceID: uint64(workplace.ID)})
+	newcomerToken := token(util.UserClaims{Role: string(rdb.UserTypeEmployee), EmployeeID: uint64(newcomer.ID), WorkplaceID: uint64(workplace.ID)})
+	managerToken := token(util.UserClaims{Role: string(rdb.UserTypeManager), EmployeeID: uint64(manager.ID), WorkplaceID: uint64(workplace.ID)})
+
+	do := func(method, path, token string, body any) *httptest.ResponseRecorder {
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		req, err := http.NewRequest(method, path, bytes.NewBuffer(b))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w = do("POST", ui.LeaveTypePath, adminToken, map[string]any{"name": "年次有給休暇", "paid": true})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var leaveType rdb.LeaveType
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &leaveType))
+	assert.Equal(t, "年", leaveType.Marker)
+
+	// reading the balance counts the due grants without recording them
+	w = do("GET", ui.EmployeePath+fmt.Sprintf("%d/leave_balance/", employee.ID), employeeToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var balance handler.LeaveBalanceResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &balance))
+	assert.Equal(t, 23, balance.Available)
+	require.Len(t, balance.Grants, 3)
+	assert.Zero(t, balance.Grants[2].ID)
+	grants, err := rdb.New(dbConn).GetLeaveGrants(c, employee.ID)
+	require.NoError(t, err)
+	assert.Empty(t, grants)
+
+	from, to := today.AddDate(0, 0, 7).Format(util.DateLayout), today.AddDate(0, 0, 13).Format(util.DateLayout)
+	w = do("POST", ui.LeaveRequestPath, employeeToken, map[string]any{"leave_type_id": leaveType.ID, "from": from, "to": to})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var requests []rdb.LeaveRequest
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &requests))
+	require.GreaterOrEqual(t, len(requests), 2)
+
+	// requesting leave records the due grants
+	grants, err = rdb.New(dbConn).GetLeaveGrants(c, employee.ID)
+	require.NoError(t, err)
+	assert.Len(t, grants, 3)
+	w = do("GET", ui.AuditLogPath+"?resource=leave_grant&action=create", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var logs handler.ListResponse[handler.AuditLogResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &logs))
+	assert.Len(t, logs.Items, 3)
+
+	w = do("GET", ui.EmployeePath+fmt.Sprintf("%d/leave_balance/", employee.ID), employeeToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &balance))
+	assert.Equal(t, 23, balance.Available)
+	assert.NotZero(t, balance.Grants[2].ID)
+	assert.Equal(t, len(requests), balance.Pending)
+	require.Len(t, balance.Grants, 3)
+	assert.True(t, balance.Grants[0].Expired)
+
+	// the same day twice
+	w = do("POST", ui.LeaveRequestPath, employeeToken, map[string]any{"leave_type_id": leaveType.ID, "from": util.FormatDate(requests[0].Date)})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// employees cannot approve their own requests
+	w = do("POST", ui.LeaveRequestPath+fmt.Sprintf("%d/approve/", requests[0].ID), employeeToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = do("POST", ui.LeaveRequestPath+fmt.Sprintf("%d/approve/", requests[0].ID), managerToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var decided rdb.LeaveRequest
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &decided))
+	assert.Equal(t, rdb.LeaveStatusApproved, decided.Status)
+
+	w = do("POST", ui.LeaveRequestPath+fmt.Sprintf("%d/reject/", requests[0].ID), managerToken, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = do("POST", ui.LeaveRequestPath+fmt.Sprintf("%d/cancel/", requests[1].ID), employeeToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &decided))
+	assert.Equal(t, rdb.LeaveStatusCancelled, decided.Status)
+
+	w = do("GET", ui.EmployeePath+fmt.Sprintf("%d/leave_balance/", employee.ID), employeeToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &balance))
+	assert.Equal(t, 22, balance.Available)
+	assert.Equal(t, len(requests)-2, balance.Pending)
+
+	// other employees do not see the balance
+	w = do("GET", ui.EmployeePath+fmt.Sprintf("%d/leave_balance/", employee.ID), newcomerToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// nothing is granted in the first six months
+	w = do("POST", ui.LeaveRequestPath, newcomerToken, map[string]any{"leave_type_id": leaveType.ID, "from": from, "to": to})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = do("GET", ui.LeaveRequestPath+"?status=approved&from="+from+"&to="+to, managerToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed []rdb.GetLeaveRequestsRow
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed, 1)
+	assert.Equal(t, requests[0].ID, listed[0].ID)
+	assert.Equal(t, employee.Name, listed[0].EmployeeName)
+	assert.True(t, listed[0].Paid)
+}
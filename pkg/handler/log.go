@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mio256/wplus-server/pkg/util"
+)
+
+// requestIDHeader carries the ID of a request to correlate the logs and the audit log with other records.
+const requestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key of the ID of a request.
+const requestIDKey = "requestID"
+
+// validRequestID keeps IDs that are too long or not printable out of the logs; they are replaced.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// RequestID takes the ID of the request from X-Request-ID, as given by a client or a proxy, or assigns one,
+// and returns it in the response. The ID and the route are attached to the log lines of the request.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(requestIDHeader, id)
+		c.Request = c.Request.WithContext(util.WithLogAttrs(c.Request.Context(),
+			slog.String("request_id", id),
+			slog.String("route", c.FullPath()),
+		))
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	// crypto/rand does not fail on the supported platforms
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// requestID is the ID of the request given by RequestID, or empty without it.
+func requestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// RequestLogger writes a line for every request, after one for each error that its handlers recorded with c.Error.
+// The query is left out, as it can carry secrets.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		for _, e := range c.Errors {
+			util.LogError(c, e.Err)
+		}
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		} else if status >= http.StatusBadRequest {
+			level = slog.LevelWarn
+		}
+		slog.Log(c.Request.Context(), level, "request",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Int("size", c.Writer.Size()),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
+
+// Recovery answers a panic of a handler with 500 and logs it with the stack.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		slog.ErrorContext(c.Request.Context(), "panic",
+			slog.Any("panic", err),
+			slog.String("stack", string(debug.Stack())),
+		)
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
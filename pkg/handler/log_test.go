@@ -0,0 +1,89 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mio256/wplus-server/pkg/handler"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taxio/errors"
+)
+
+func TestRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(util.NewLogger(&buf, slog.LevelInfo))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	r := gin.New()
+	r.Use(handler.RequestID(), handler.RequestLogger(), handler.Recovery())
+	r.GET("/items/:id/", func(c *gin.Context) {
+		c.Error(errors.New("lookup failed"))
+		c.Status(http.StatusInternalServerError)
+	})
+	r.GET("/panic/", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	tests := map[string]struct {
+		Path      string
+		RequestID string
+		WantID    string
+		WantLines []string
+	}{
+		"propagated": {
+			Path:      "/items/1/?token=abc",
+			RequestID: "proxy-123",
+			WantID:    "proxy-123",
+			WantLines: []string{"lookup failed", "request"},
+		},
+		"assigned": {
+			Path:      "/items/1/",
+			RequestID: strings.Repeat("x", 65),
+			WantLines: []string{"lookup failed", "request"},
+		},
+		"panic": {
+			Path:      "/panic/",
+			WantLines: []string{"panic", "request"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			buf.Reset()
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", tt.Path, nil)
+			if tt.RequestID != "" {
+				req.Header.Set("X-Request-ID", tt.RequestID)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+			id := w.Header().Get("X-Request-ID")
+			if tt.WantID != "" {
+				assert.Equal(t, tt.WantID, id)
+			} else {
+				assert.Len(t, id, 32)
+			}
+
+			lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+			require.Len(t, lines, len(tt.WantLines))
+			for i, l := range lines {
+				var m map[string]any
+				require.NoError(t, json.Unmarshal(l, &m))
+				assert.Equal(t, tt.WantLines[i], m["msg"])
+				assert.Equal(t, id, m["request_id"])
+				assert.NotEmpty(t, m["route"])
+				assert.NotContains(t, string(l), "abc")
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"log/slog"
 	"net/http"
 	"os"
 
@@ -11,8 +12,7 @@ import (
 )
 
 func PostLogin(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
+	repo := queries(c)
 
 	var input struct {
 		OfficeID uint64 `json:"office_id"`
@@ -26,21 +26,41 @@ func PostLogin(c *gin.Context) {
 		return
 	}
 
+	// failed attempts are recorded without an actor, and refused even if the record cannot be written. The office
+	// is the client's word until the user is found in it, so attempts on unknown users go to the log only.
+	refuse := func(known bool) {
+		appOf(c).Metrics.ObserveLogin(false)
+		if known {
+			if err := writeAuditAs(c, repo, nil, int64(input.OfficeID), auditEvent{
+				Action:     AuditActionLoginFailed,
+				Resource:   AuditResourceUser,
+				ResourceID: int64(input.UserID),
+			}); err != nil {
+				c.Error(errors.Wrap(err))
+			}
+		} else {
+			slog.WarnContext(c.Request.Context(), "login of an unknown user",
+				slog.Uint64("office_id", input.OfficeID),
+				slog.Uint64("user_id", input.UserID),
+				slog.String("client_ip", c.ClientIP()),
+			)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"message": "Unauthorized",
+		})
+	}
+
 	user, err := repo.GetUser(c, rdb.GetUserParams{
 		OfficeID: int64(input.OfficeID),
 		ID:       int64(input.UserID),
 	})
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"message": "Unauthorized",
-		})
+		refuse(false)
 		return
 	}
 
 	if err = util.CompareHashAndPassword(user.Password, input.Password); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"message": "Unauthorized",
-		})
+		refuse(true)
 		return
 	}
 
@@ -54,19 +74,29 @@ func PostLogin(c *gin.Context) {
 		workplaceID = employee.WorkplaceID
 	}
 
-	token, err := util.GenerateToken(util.UserClaims{
+	claims := util.UserClaims{
 		UserID:      uint64(user.ID),
 		OfficeID:    uint64(user.OfficeID),
 		WorkplaceID: uint64(workplaceID),
 		EmployeeID:  uint64(user.EmployeeID.Int64),
 		Name:        user.Name,
 		Role:        string(user.Role),
-	})
+	}
+	token, err := util.GenerateToken(claims)
 	if err != nil {
 		c.Error(errors.Wrap(err))
 		return
 	}
+	if err := writeAuditAs(c, repo, &claims, user.OfficeID, auditEvent{
+		Action:     AuditActionLogin,
+		Resource:   AuditResourceUser,
+		ResourceID: user.ID,
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
 
+	appOf(c).Metrics.ObserveLogin(true)
 	domain := os.Getenv("DOMAIN")
 	c.SetCookie("token", token, 3600, "/", domain, false, true)
 	c.JSON(http.StatusOK, gin.H{
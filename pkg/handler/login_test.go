@@ -20,7 +20,7 @@ import (
 )
 
 func TestLogin(t *testing.T) {
-	router := ui.SetupRouter()
+	router := ui.SetupRouter(test.NewApp(t))
 
 	tests := map[string]struct {
 		Role           rdb.UserType
@@ -110,3 +110,55 @@ func TestLogin(t *testing.T) {
 		})
 	}
 }
+
+func TestLoginFailed(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	dbConn := infra.ConnectDB(c)
+	repo := rdb.New(dbConn)
+
+	user, _ := test.CreateUser(t, c, dbConn, nil)
+	// an office that nobody has created
+	unknownOffice := user.OfficeID + 1<<40
+
+	tests := map[string]struct {
+		OfficeID, UserID int64
+		WantLogged       bool
+	}{
+		"wrong-password": {OfficeID: user.OfficeID, UserID: user.ID, WantLogged: true},
+		"unknown-user":   {OfficeID: user.OfficeID, UserID: 0},
+		"unknown-office": {OfficeID: unknownOffice, UserID: user.ID},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			count := func() int64 {
+				n, err := repo.CountAuditLogs(c, rdb.CountAuditLogsParams{
+					OfficeID:   tt.OfficeID,
+					Action:     pgtype.Text{String: "login_failed", Valid: true},
+					ResourceID: pgtype.Int8{Int64: tt.UserID, Valid: tt.UserID != 0},
+				})
+				require.NoError(t, err)
+				return n
+			}
+			before := count()
+
+			b, err := json.Marshal(map[string]any{"office_id": tt.OfficeID, "user_id": tt.UserID, "password": "wrong"})
+			require.NoError(t, err)
+			req, err := http.NewRequest("POST", ui.LoginPath, bytes.NewBuffer(b))
+			require.NoError(t, err)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+			if tt.WantLogged {
+				assert.Equal(t, before+1, count())
+			} else {
+				assert.Equal(t, before, count())
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mio256/wplus-server/pkg/infra/rdb"
@@ -10,8 +11,7 @@ import (
 )
 
 func GetOffice(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
+	repo := queries(c)
 
 	user := c.MustGet("user").(*util.UserClaims)
 
@@ -23,3 +23,51 @@ func GetOffice(c *gin.Context) {
 
 	c.IndentedJSON(http.StatusOK, office)
 }
+
+func ChangeOfficeTimeZone(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+
+	if user.Role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin",
+		})
+		return
+	}
+
+	var input struct {
+		TimeZone string `json:"time_zone"`
+	}
+	if err := c.BindJSON(&input); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	if _, err := util.LoadLocation(input.TimeZone); err != nil || input.TimeZone == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid time zone",
+		})
+		return
+	}
+
+	office, err := repo.UpdateOfficeTimeZone(c, rdb.UpdateOfficeTimeZoneParams{
+		ID:       int64(user.OfficeID),
+		TimeZone: input.TimeZone,
+	})
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, office)
+}
+
+// officeLocation returns the time zone of the office in which dates and times are interpreted.
+func officeLocation(c *gin.Context, repo *rdb.Queries, officeID int64) (*time.Location, error) {
+	office, err := repo.GetOffice(c, officeID)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return util.LoadLocation(office.TimeZone)
+}
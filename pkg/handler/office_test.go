@@ -1,6 +1,7 @@
 package handler_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -18,7 +19,7 @@ import (
 )
 
 func TestGetOffice(t *testing.T) {
-	router := ui.SetupRouter()
+	router := ui.SetupRouter(test.NewApp(t))
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	dbConn := infra.ConnectDB(c)
@@ -43,3 +44,61 @@ func TestGetOffice(t *testing.T) {
 	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
 	assert.Equal(t, *created, res)
 }
+
+func TestChangeOfficeTimeZone(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	tests := map[string]struct {
+		Role     rdb.UserType
+		TimeZone string
+		WantCode int
+	}{
+		"admin": {
+			Role:     rdb.UserTypeAdmin,
+			TimeZone: "America/New_York",
+			WantCode: http.StatusOK,
+		},
+		"admin-invalid": {
+			Role:     rdb.UserTypeAdmin,
+			TimeZone: "Mars/Olympus_Mons",
+			WantCode: http.StatusBadRequest,
+		},
+		"manager": {
+			Role:     rdb.UserTypeManager,
+			TimeZone: "America/New_York",
+			WantCode: http.StatusForbidden,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			dbConn := infra.ConnectDB(c)
+
+			office := test.CreateOffice(t, c, dbConn, nil)
+			require.Equal(t, util.DefaultTimeZone, office.TimeZone)
+
+			token, err := util.GenerateToken(util.UserClaims{
+				OfficeID: uint64(office.ID),
+				Role:     string(tt.Role),
+			})
+			require.NoError(t, err)
+
+			b, err := json.Marshal(map[string]string{"time_zone": tt.TimeZone})
+			require.NoError(t, err)
+			c.Request, err = http.NewRequest("PUT", ui.OfficePath, bytes.NewBuffer(b))
+			require.NoError(t, err)
+			c.Request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			router.ServeHTTP(w, c.Request)
+
+			require.Equal(t, tt.WantCode, w.Code)
+			if tt.WantCode == http.StatusOK {
+				var res rdb.Office
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+				assert.Equal(t, tt.TimeZone, res.TimeZone)
+			}
+		})
+	}
+}
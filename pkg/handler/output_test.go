@@ -0,0 +1,72 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mio256/wplus-server/pkg/infra"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/test"
+	"github.com/mio256/wplus-server/pkg/ui"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOutputByWorkplace(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	dbConn := infra.ConnectDB(c)
+
+	office := test.CreateOffice(t, c, dbConn, nil)
+	workplace := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+	})
+	token, err := util.GenerateToken(util.UserClaims{OfficeID: uint64(office.ID), Role: string(rdb.UserTypeAdmin)})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		Path     string
+		Body     string
+		WantCode int
+	}{
+		"ok":           {Body: `{"year": 2024, "month": 4}`, WantCode: http.StatusOK},
+		"month-zero":   {Body: `{"year": 2024, "month": 0}`, WantCode: http.StatusBadRequest},
+		"month-13":     {Body: `{"year": 2024, "month": 13}`, WantCode: http.StatusBadRequest},
+		"no-year":      {Body: `{"month": 4}`, WantCode: http.StatusBadRequest},
+		"empty":        {Body: `{}`, WantCode: http.StatusBadRequest},
+		"not-json":     {Body: `year=2024`, WantCode: http.StatusBadRequest},
+		"bad-id":       {Path: "x/", Body: `{"year": 2024, "month": 4}`, WantCode: http.StatusBadRequest},
+		"no-workplace": {Path: "0/", Body: `{"year": 2024, "month": 4}`, WantCode: http.StatusNotFound},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			path := tt.Path
+			if path == "" {
+				path = fmt.Sprintf("%d/", workplace.ID)
+			}
+			req, err := http.NewRequest("POST", ui.OutputPath+"workplace/"+path, bytes.NewBufferString(tt.Body))
+			require.NoError(t, err)
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.WantCode, w.Code, w.Body.String())
+			if tt.WantCode != http.StatusOK && w.Body.Len() > 0 {
+				var res map[string]any
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+				assert.Len(t, res, 1)
+				assert.Contains(t, res, "message")
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/export"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/overtime"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/taxio/errors"
+)
+
+// maxOvertimeDays limits the period of an overtime summary.
+const maxOvertimeDays = 366
+
+type OvertimeSummary struct {
+	EmployeeID   int64  `json:"employee_id"`
+	EmployeeName string `json:"employee_name"`
+	overtime.Minutes
+}
+
+// officeOvertimeRules returns the stored rules of an office, or the statutory ones when it has none.
+func officeOvertimeRules(c *gin.Context, repo *rdb.Queries, officeID int64) (rdb.OvertimeRule, error) {
+	rules, err := repo.GetOvertimeRules(c, officeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		d := overtime.DefaultRules()
+		return rdb.OvertimeRule{
+			OfficeID:           officeID,
+			DailyLimitMinutes:  int32(d.DailyLimit / time.Minute),
+			WeeklyLimitMinutes: int32(d.WeeklyLimit / time.Minute),
+			NightStart:         util.Clock{Time: util.NewClock(time.Time{}.Add(d.NightStart))},
+			NightEnd:           util.Clock{Time: util.NewClock(time.Time{}.Add(d.NightEnd))},
+			LegalHoliday:       int16(d.LegalHoliday),
+			WeekStart:          int16(d.WeekStart),
+		}, nil
+	} else if err != nil {
+		return rdb.OvertimeRule{}, errors.Wrap(err)
+	}
+	return rules, nil
+}
+
+func GetOvertimeRules(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+
+	rules, err := officeOvertimeRules(c, repo, int64(user.OfficeID))
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, rules)
+}
+
+// PutOvertimeRules replaces the overtime rules of the office. Fields that are not in the request body keep their current values.
+func PutOvertimeRules(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	if user.Role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin",
+		})
+		return
+	}
+
+	current, err := officeOvertimeRules(c, repo, int64(user.OfficeID))
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	p := rdb.UpsertOvertimeRulesParams{
+		DailyLimitMinutes:  current.DailyLimitMinutes,
+		WeeklyLimitMinutes: current.WeeklyLimitMinutes,
+		NightStart:         current.NightStart,
+		NightEnd:           current.NightEnd,
+		LegalHoliday:       current.LegalHoliday,
+		WeekStart:          current.WeekStart,
+	}
+	if err := c.BindJSON(&p); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	p.OfficeID = int64(user.OfficeID)
+
+	if p.DailyLimitMinutes <= 0 || p.DailyLimitMinutes > 24*60 ||
+		p.WeeklyLimitMinutes <= 0 || p.WeeklyLimitMinutes > 7*24*60 ||
+		!p.NightStart.Valid || !p.NightEnd.Valid ||
+		p.LegalHoliday < 0 || p.LegalHoliday > 6 || p.WeekStart < 0 || p.WeekStart > 6 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid input",
+		})
+		return
+	}
+
+	rules, err := repo.UpsertOvertimeRules(c, p)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, rules)
+}
+
+// GetOvertimeSummary returns the categorized minutes of each employee between from and to (this month by default).
+// Managers see the employees assigned to their workplace in the period, and employees see themselves.
+func GetOvertimeSummary(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	officeID := int64(user.OfficeID)
+
+	loc, err := officeLocation(c, repo, officeID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	today := util.Today(loc).Time
+	from, to := util.MonthRange(today.Year(), today.Month())
+	if s := c.Query("from"); s != "" {
+		if from, err = util.ParseLocalDate(s, loc); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid query",
+			})
+			return
+		}
+	}
+	if s := c.Query("to"); s != "" {
+		if to, err = util.ParseLocalDate(s, loc); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid query",
+			})
+			return
+		}
+	}
+	if to.Time.Before(from.Time) || to.Time.Sub(from.Time) >= maxOvertimeDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+
+	var workplaceID int64
+	if s := c.Query("workplace_id"); s != "" {
+		if workplaceID, err = strconv.ParseInt(s, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid query",
+			})
+			return
+		}
+	}
+	var employeeID pgtype.Int8
+	switch user.Role {
+	case "admin":
+	case "manager":
+		mine, err := managerWorkplaceID(c, repo, user)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		if workplaceID != 0 && workplaceID != mine {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": "your workplace is different",
+			})
+			return
+		}
+		workplaceID = mine
+	default:
+		if user.EmployeeID == 0 {
+			c.Error(errors.New("user is not employee: employee_id is not set"))
+			return
+		}
+		employeeID = pgtype.Int8{Int64: int64(user.EmployeeID), Valid: true}
+	}
+
+	var employees []rdb.Employee
+	if employeeID.Valid {
+		e, err := repo.GetEmployee(c, employeeID.Int64)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		employees = append(employees, e)
+	} else if workplaceID != 0 {
+		workplace, err := repo.GetWorkplace(c, workplaceID)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		if workplace.OfficeID != officeID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": "your office is different",
+			})
+			return
+		}
+		employees, err = repo.OutputEmployeesByWorkplaceAndDate(c, rdb.OutputEmployeesByWorkplaceAndDateParams{
+			WorkplaceID: workplaceID,
+			MaxDate:     to,
+			MinDate:     from,
+		})
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+	} else {
+		employees, err = repo.GetEmployeesByOffice(c, officeID)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+	}
+
+	rules, err := export.OvertimeRules(c, repo, officeID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	results, err := export.Overtime(c, repo, officeID, employeeID, rules, from, to)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	res := make([]OvertimeSummary, 0, len(employees))
+	for _, e := range employees {
+		res = append(res, OvertimeSummary{
+			EmployeeID:   e.ID,
+			EmployeeName: e.Name,
+			Minutes:      results[e.ID].Total,
+		})
+	}
+
+	c.IndentedJSON(http.StatusOK, res)
+}
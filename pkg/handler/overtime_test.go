@@ -0,0 +1,204 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/handler"
+	"github.com/mio256/wplus-server/pkg/infra"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/overtime"
+	"github.com/mio256/wplus-server/pkg/test"
+	"github.com/mio256/wplus-server/pkg/ui"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutOvertimeRules(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	tests := map[string]struct {
+		Role     rdb.UserType
+		Body     map[string]any
+		WantCode int
+	}{
+		"admin": {
+			Role:     rdb.UserTypeAdmin,
+			Body:     map[string]any{"daily_limit_minutes": 420, "legal_holiday": 6, "night_start": "23:00"},
+			WantCode: http.StatusOK,
+		},
+		"admin-invalid-weekday": {
+			Role:     rdb.UserTypeAdmin,
+			Body:     map[string]any{"legal_holiday": 7},
+			WantCode: http.StatusBadRequest,
+		},
+		"admin-null-night": {
+			Role:     rdb.UserTypeAdmin,
+			Body:     map[string]any{"night_end": nil},
+			WantCode: http.StatusBadRequest,
+		},
+		"manager": {
+			Role:     rdb.UserTypeManager,
+			Body:     map[string]any{"daily_limit_minutes": 420},
+			WantCode: http.StatusForbidden,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			dbConn := infra.ConnectDB(c)
+
+			office := test.CreateOffice(t, c, dbConn, nil)
+			token, err := util.GenerateToken(util.UserClaims{
+				OfficeID: uint64(office.ID),
+				Role:     string(tt.Role),
+			})
+			require.NoError(t, err)
+
+			b, err := json.Marshal(tt.Body)
+			require.NoError(t, err)
+			c.Request, err = http.NewRequest("PUT", ui.OfficePath+"overtime_rules/", bytes.NewBuffer(b))
+			require.NoError(t, err)
+			c.Request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			router.ServeHTTP(w, c.Request)
+
+			require.Equal(t, tt.WantCode, w.Code)
+			if tt.WantCode == http.StatusOK {
+				var res rdb.OvertimeRule
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+				assert.Equal(t, int32(420), res.DailyLimitMinutes)
+				assert.Equal(t, int32(2400), res.WeeklyLimitMinutes)
+				assert.Equal(t, int16(6), res.LegalHoliday)
+				assert.Equal(t, "23:00:00", util.FormatClock(res.NightStart.Time))
+				assert.Equal(t, "05:00:00", util.FormatClock(res.NightEnd.Time))
+			}
+		})
+	}
+}
+
+func TestGetOvertimeSummary(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	dbConn := infra.ConnectDB(c)
+
+	office := test.CreateOffice(t, c, dbConn, nil)
+	workplace := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+		v.WorkType = rdb.WorkTypeTime
+	})
+	employee := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+		v.WorkplaceID = workplace.ID
+	})
+	other := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+		v.WorkplaceID = workplace.ID
+	})
+	// the workplace of the manager before a transfer, which their token still carries
+	former := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+	})
+
+	clock := func(h int) pgtype.Time {
+		return util.NewClock(time.Date(2000, 1, 1, h, 0, 0, 0, time.UTC))
+	}
+	// 2024-04-01 is a Monday and 2024-04-07 a Sunday
+	for _, e := range []struct {
+		Date       string
+		Start, End int
+	}{
+		{"2024-04-01", 9, 20},
+		{"2024-04-02", 18, 23},
+		{"2024-04-07", 9, 12},
+	} {
+		d, err := time.Parse(util.DateLayout, e.Date)
+		require.NoError(t, err)
+		test.CreateWorkEntries(t, c, dbConn, func(v *rdb.WorkEntry) {
+			v.EmployeeID = employee.ID
+			v.WorkplaceID = workplace.ID
+			v.Date = util.NewDate(d)
+			v.Hours = pgtype.Int2{}
+			v.StartTime = clock(e.Start)
+			v.EndTime = clock(e.End)
+		})
+	}
+
+	tests := map[string]struct {
+		Claims   util.UserClaims
+		Query    string
+		WantCode int
+		Want     []handler.OvertimeSummary
+	}{
+		"admin": {
+			Claims:   util.UserClaims{Role: string(rdb.UserTypeAdmin)},
+			Query:    "from=2024-04-01&to=2024-04-30",
+			WantCode: http.StatusOK,
+			Want: []handler.OvertimeSummary{
+				{EmployeeID: employee.ID, EmployeeName: employee.Name, Minutes: overtime.Minutes{
+					Worked: 19 * 60, Regular: 13 * 60, DailyOvertime: 3 * 60, LateNight: 60, LegalHoliday: 3 * 60,
+				}},
+				{EmployeeID: other.ID, EmployeeName: other.Name},
+			},
+		},
+		"employee": {
+			Claims:   util.UserClaims{Role: string(rdb.UserTypeEmployee), EmployeeID: uint64(other.ID)},
+			Query:    "from=2024-04-01&to=2024-04-30",
+			WantCode: http.StatusOK,
+			Want:     []handler.OvertimeSummary{{EmployeeID: other.ID, EmployeeName: other.Name}},
+		},
+		"manager-transferred": {
+			Claims:   util.UserClaims{Role: string(rdb.UserTypeManager), EmployeeID: uint64(other.ID), WorkplaceID: uint64(former.ID)},
+			Query:    "from=2024-04-01&to=2024-04-30",
+			WantCode: http.StatusOK,
+			Want: []handler.OvertimeSummary{
+				{EmployeeID: employee.ID, EmployeeName: employee.Name, Minutes: overtime.Minutes{
+					Worked: 19 * 60, Regular: 13 * 60, DailyOvertime: 3 * 60, LateNight: 60, LegalHoliday: 3 * 60,
+				}},
+				{EmployeeID: other.ID, EmployeeName: other.Name},
+			},
+		},
+		"manager-former-workplace": {
+			Claims:   util.UserClaims{Role: string(rdb.UserTypeManager), EmployeeID: uint64(other.ID), WorkplaceID: uint64(former.ID)},
+			Query:    fmt.Sprintf("from=2024-04-01&to=2024-04-30&workplace_id=%d", former.ID),
+			WantCode: http.StatusForbidden,
+		},
+		"invalid-period": {
+			Claims:   util.UserClaims{Role: string(rdb.UserTypeAdmin)},
+			Query:    "from=2024-04-30&to=2024-04-01",
+			WantCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.Claims.OfficeID = uint64(office.ID)
+			token, err := util.GenerateToken(tt.Claims)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest("GET", ui.OvertimePath+"?"+tt.Query, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.WantCode, w.Code)
+			if tt.WantCode == http.StatusOK {
+				var res []handler.OvertimeSummary
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+				assert.ElementsMatch(t, tt.Want, res)
+			}
+		})
+	}
+}
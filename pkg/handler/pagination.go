@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taxio/errors"
+)
+
+const (
+	DefaultPageLimit = 100
+	MaxPageLimit     = 1000
+)
+
+type Pagination struct {
+	Limit   int32 `json:"limit"`
+	Offset  int32 `json:"offset"`
+	Total   int64 `json:"total"`
+	HasNext bool  `json:"has_next"`
+}
+
+type ListResponse[T any] struct {
+	Items      []T        `json:"items"`
+	Pagination Pagination `json:"pagination"`
+}
+
+func NewPagination(limit, offset int32, total int64) Pagination {
+	return Pagination{
+		Limit:   limit,
+		Offset:  offset,
+		Total:   total,
+		HasNext: int64(offset)+int64(limit) < total,
+	}
+}
+
+// NewListResponse is a page of items. An empty page is an empty list rather than null.
+func NewListResponse[T any](items []T, limit, offset int32, total int64) ListResponse[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return ListResponse[T]{Items: items, Pagination: NewPagination(limit, offset, total)}
+}
+
+// parsePage reads the limit and offset query parameters.
+func parsePage(c *gin.Context) (int32, int32, error) {
+	limit, offset := int32(DefaultPageLimit), int32(0)
+	if s := c.Query("limit"); s != "" {
+		v, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return 0, 0, errors.Wrap(err)
+		}
+		if v < 1 || v > MaxPageLimit {
+			return 0, 0, errors.New("limit is out of range")
+		}
+		limit = int32(v)
+	}
+	if s := c.Query("offset"); s != "" {
+		v, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return 0, 0, errors.Wrap(err)
+		}
+		if v < 0 {
+			return 0, 0, errors.New("offset is out of range")
+		}
+		offset = int32(v)
+	}
+	return limit, offset, nil
+}
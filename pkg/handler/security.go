@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"mime"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mio256/wplus-server/pkg/config"
+)
+
+// htmlPolicy is the Content-Security-Policy of HTML responses. The server has no pages of its own,
+// so HTML that ends up in a response may run nothing and load nothing.
+const htmlPolicy = "default-src 'none'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+
+// SecurityHeaders sets the security headers of every response, and strips the headers that tell
+// the software and the version of the server.
+func SecurityHeaders(cfg config.Security) gin.HandlerFunc {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds())) + "; includeSubDomains"
+	}
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		if hsts != "" {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "no-referrer")
+		w := &securityWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		// gin sends the headers of responses without a body after the handlers
+		w.finish()
+	}
+}
+
+// securityWriter finishes the headers of a response when they are sent, once its content type is known.
+type securityWriter struct {
+	gin.ResponseWriter
+}
+
+func (w *securityWriter) finish() {
+	if w.Written() {
+		return
+	}
+	h := w.Header()
+	h.Del("Server")
+	h.Del("X-Powered-By")
+	if t, _, err := mime.ParseMediaType(h.Get("Content-Type")); err == nil && t == "text/html" {
+		h.Set("Content-Security-Policy", htmlPolicy)
+	}
+}
+
+func (w *securityWriter) WriteHeaderNow() {
+	w.finish()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *securityWriter) Write(b []byte) (int, error) {
+	w.finish()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *securityWriter) WriteString(s string) (int, error) {
+	w.finish()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *securityWriter) Flush() {
+	w.finish()
+	w.ResponseWriter.Flush()
+}
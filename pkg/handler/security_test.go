@@ -0,0 +1,65 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mio256/wplus-server/pkg/config"
+	"github.com/mio256/wplus-server/pkg/handler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	r := gin.New()
+	r.Use(handler.SecurityHeaders(config.Security{HSTSMaxAge: 24 * time.Hour}))
+	r.GET("/json/", func(c *gin.Context) {
+		c.Header("Server", "gin/1.10.0")
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+	r.GET("/html/", func(c *gin.Context) {
+		c.Header("X-Powered-By", "Go")
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte("<p>hi</p>"))
+	})
+	r.GET("/empty/", func(c *gin.Context) {
+		c.Header("Server", "gin/1.10.0")
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := map[string]struct {
+		Path    string
+		WantCSP bool
+	}{
+		"json":  {Path: "/json/", WantCSP: false},
+		"html":  {Path: "/html/", WantCSP: true},
+		"empty": {Path: "/empty/", WantCSP: false},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", tt.Path, nil))
+
+			h := w.Header()
+			assert.Equal(t, "max-age=86400; includeSubDomains", h.Get("Strict-Transport-Security"))
+			assert.Equal(t, "nosniff", h.Get("X-Content-Type-Options"))
+			assert.Equal(t, "DENY", h.Get("X-Frame-Options"))
+			assert.Empty(t, h.Get("Server"))
+			assert.Empty(t, h.Get("X-Powered-By"))
+			if tt.WantCSP {
+				assert.Contains(t, h.Get("Content-Security-Policy"), "default-src 'none'")
+			} else {
+				assert.Empty(t, h.Get("Content-Security-Policy"))
+			}
+		})
+	}
+
+	r = gin.New()
+	r.Use(handler.SecurityHeaders(config.Security{}))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"))
+}
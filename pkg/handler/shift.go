@@ -0,0 +1,562 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/shift"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/taxio/errors"
+)
+
+// managerWorkplaceID returns the workplace that a manager is assigned to now. The workplace of the token
+// is the one of the login, which a transfer since has made stale.
+func managerWorkplaceID(c *gin.Context, repo *rdb.Queries, user *util.UserClaims) (int64, error) {
+	if user.EmployeeID == 0 {
+		return 0, errors.New("user is not manager: employee_id is not set")
+	}
+	me, err := repo.GetEmployee(c, int64(user.EmployeeID))
+	if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	return me.WorkplaceID, nil
+}
+
+// authorizeWorkplace responds with 403 and returns false unless the user manages the workplace:
+// admins manage every workplace of their office and managers their own.
+func authorizeWorkplace(c *gin.Context, repo *rdb.Queries, user *util.UserClaims, workplace rdb.Workplace) bool {
+	switch user.Role {
+	case "admin":
+		if workplace.OfficeID != int64(user.OfficeID) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": "your office is different",
+			})
+			return false
+		}
+	case "manager":
+		workplaceID, err := managerWorkplaceID(c, repo, user)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return false
+		}
+		if workplace.ID != workplaceID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": "your workplace is different",
+			})
+			return false
+		}
+	default:
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin or manager",
+		})
+		return false
+	}
+	return true
+}
+
+// managedWorkplace reads the workplace of the id parameter and checks that the user manages it.
+func managedWorkplace(c *gin.Context, repo *rdb.Queries, user *util.UserClaims, id string) (rdb.Workplace, bool) {
+	workplaceID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return rdb.Workplace{}, false
+	}
+	workplace, err := repo.GetWorkplace(c, workplaceID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return rdb.Workplace{}, false
+	}
+	return workplace, authorizeWorkplace(c, repo, user, workplace)
+}
+
+func GetShiftTemplates(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+
+	workplaceID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	workplace, err := repo.GetWorkplace(c, workplaceID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if workplace.OfficeID != int64(user.OfficeID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "your office is different",
+		})
+		return
+	}
+
+	templates, err := repo.GetShiftTemplates(c, workplace.ID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if templates == nil {
+		templates = []rdb.ShiftTemplate{}
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+func PostShiftTemplate(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+
+	workplace, ok := managedWorkplace(c, repo, user, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name      string     `json:"name"`
+		StartTime util.Clock `json:"start_time"`
+		EndTime   util.Clock `json:"end_time"`
+	}
+	if err := c.BindJSON(&input); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if input.Name == "" || len(input.Name) > 255 || !input.StartTime.Valid || !input.EndTime.Valid || input.StartTime == input.EndTime {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid input",
+		})
+		return
+	}
+
+	template, err := repo.CreateShiftTemplate(c, rdb.CreateShiftTemplateParams{
+		WorkplaceID: workplace.ID,
+		Name:        input.Name,
+		StartTime:   input.StartTime,
+		EndTime:     input.EndTime,
+	})
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{
+			"message": "shift template already exists",
+		})
+		return
+	} else if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.IndentedJSON(http.StatusCreated, template)
+}
+
+// DeleteShiftTemplate deletes a template. The shifts made from it keep their times.
+func DeleteShiftTemplate(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+
+	workplace, ok := managedWorkplace(c, repo, user, c.Param("id"))
+	if !ok {
+		return
+	}
+	templateID, err := strconv.ParseInt(c.Param("template_id"), 10, 64)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	template, err := repo.DeleteShiftTemplate(c, rdb.DeleteShiftTemplateParams{
+		ID:          templateID,
+		WorkplaceID: workplace.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "shift template not found",
+		})
+		return
+	} else if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, template)
+}
+
+// GetShifts returns the shifts between from and to (this year by default). Admins and managers see the drafts
+// of the workplaces they manage, narrowed down by workplace_id, and employees see their own published shifts.
+func GetShifts(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+
+	loc, err := officeLocation(c, repo, int64(user.OfficeID))
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	from, to, ok := periodQuery(c, loc)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+
+	p := rdb.GetShiftsParams{
+		OfficeID: int64(user.OfficeID),
+		FromDate: from,
+		ToDate:   to,
+	}
+	if s := c.Query("workplace_id"); s != "" {
+		workplaceID, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid query",
+			})
+			return
+		}
+		p.WorkplaceID = pgtype.Int8{Int64: workplaceID, Valid: true}
+	}
+
+	switch user.Role {
+	case "admin":
+	case "manager":
+		workplaceID, err := managerWorkplaceID(c, repo, user)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		if p.WorkplaceID.Valid && p.WorkplaceID.Int64 != workplaceID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": "your workplace is different",
+			})
+			return
+		}
+		p.WorkplaceID = pgtype.Int8{Int64: workplaceID, Valid: true}
+	default:
+		if user.EmployeeID == 0 {
+			c.Error(errors.New("user is not employee: employee_id is not set"))
+			return
+		}
+		p.EmployeeID = pgtype.Int8{Int64: int64(user.EmployeeID), Valid: true}
+		p.PublishedOnly = true
+	}
+
+	shifts, err := repo.GetShifts(c, p)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if shifts == nil {
+		shifts = []rdb.GetShiftsRow{}
+	}
+
+	c.JSON(http.StatusOK, shifts)
+}
+
+// PostShift plans a draft shift for an employee assigned to the workplace on the date.
+// The times are taken from shift_template_id unless start_time and end_time are given.
+func PostShift(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+
+	var input struct {
+		WorkplaceID     int64      `json:"workplace_id"`
+		EmployeeID      int64      `json:"employee_id"`
+		Date            string     `json:"date"`
+		ShiftTemplateID int64      `json:"shift_template_id"`
+		StartTime       util.Clock `json:"start_time"`
+		EndTime         util.Clock `json:"end_time"`
+	}
+	if err := c.BindJSON(&input); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	workplace, ok := managedWorkplace(c, repo, user, strconv.FormatInt(input.WorkplaceID, 10))
+	if !ok {
+		return
+	}
+
+	loc, err := officeLocation(c, repo, workplace.OfficeID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	date, err := util.ParseLocalDate(input.Date, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid date",
+		})
+		return
+	}
+
+	var templateID pgtype.Int8
+	if input.ShiftTemplateID != 0 {
+		template, err := repo.GetShiftTemplate(c, input.ShiftTemplateID)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && template.WorkplaceID != workplace.ID) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid input",
+			})
+			return
+		} else if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		templateID = pgtype.Int8{Int64: template.ID, Valid: true}
+		if !input.StartTime.Valid {
+			input.StartTime = template.StartTime
+		}
+		if !input.EndTime.Valid {
+			input.EndTime = template.EndTime
+		}
+	}
+	if !input.StartTime.Valid || !input.EndTime.Valid || input.StartTime == input.EndTime {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid input",
+		})
+		return
+	}
+
+	employee, err := repo.GetEmployee(c, input.EmployeeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid input",
+		})
+		return
+	} else if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	workplaceID, err := employeeWorkplaceOn(c, repo, employee, date)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if workplaceID != workplace.ID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "your workplace is different",
+		})
+		return
+	}
+
+	s, err := repo.CreateShift(c, rdb.CreateShiftParams{
+		WorkplaceID:     workplace.ID,
+		EmployeeID:      employee.ID,
+		Date:            date,
+		StartTime:       input.StartTime,
+		EndTime:         input.EndTime,
+		ShiftTemplateID: templateID,
+	})
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.IndentedJSON(http.StatusCreated, s)
+}
+
+func DeleteShift(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	s, err := repo.GetShift(c, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "shift not found",
+		})
+		return
+	} else if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if _, ok := managedWorkplace(c, repo, user, strconv.FormatInt(s.WorkplaceID, 10)); !ok {
+		return
+	}
+
+	if err := repo.DeleteShift(c, s.ID); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, s)
+}
+
+// PublishShifts makes the draft shifts of a workplace between from and to visible to the employees.
+func PublishShifts(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+
+	var input struct {
+		WorkplaceID int64  `json:"workplace_id"`
+		From        string `json:"from"`
+		To          string `json:"to"`
+	}
+	if err := c.BindJSON(&input); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	workplace, ok := managedWorkplace(c, repo, user, strconv.FormatInt(input.WorkplaceID, 10))
+	if !ok {
+		return
+	}
+
+	loc, err := officeLocation(c, repo, workplace.OfficeID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	from, err := util.ParseLocalDate(input.From, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid input",
+		})
+		return
+	}
+	to, err := util.ParseLocalDate(input.To, loc)
+	if err != nil || to.Time.Before(from.Time) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid input",
+		})
+		return
+	}
+
+	published, err := repo.PublishShifts(c, rdb.PublishShiftsParams{
+		WorkplaceID: workplace.ID,
+		FromDate:    from,
+		ToDate:      to,
+	})
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{
+		"published": published,
+	})
+}
+
+type ShiftFinding struct {
+	Kind         string      `json:"kind"`
+	EmployeeID   int64       `json:"employee_id"`
+	EmployeeName string      `json:"employee_name"`
+	Date         pgtype.Date `json:"date"`
+	ShiftID      int64       `json:"shift_id,omitempty"`
+	EntryID      int64       `json:"entry_id,omitempty"`
+	Minutes      int         `json:"minutes,omitempty"`
+}
+
+// GetShiftComparison matches the published shifts of a workplace between from and to (this year by default)
+// to its work entries. Lateness and early departure within grace_minutes (0 by default) are tolerated,
+// and shifts from today on are not reported as missing.
+func GetShiftComparison(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+
+	workplace, ok := managedWorkplace(c, repo, user, c.Query("workplace_id"))
+	if !ok {
+		return
+	}
+
+	loc, err := officeLocation(c, repo, workplace.OfficeID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	from, to, ok := periodQuery(c, loc)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+	var grace int
+	if s := c.Query("grace_minutes"); s != "" {
+		if grace, err = strconv.Atoi(s); err != nil || grace < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid query",
+			})
+			return
+		}
+	}
+
+	shifts, err := repo.GetShifts(c, rdb.GetShiftsParams{
+		OfficeID:      workplace.OfficeID,
+		FromDate:      from,
+		ToDate:        to,
+		WorkplaceID:   pgtype.Int8{Int64: workplace.ID, Valid: true},
+		PublishedOnly: true,
+	})
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	entries, err := repo.OutputWorkEntriesByWorkplaceAndDate(c, rdb.OutputWorkEntriesByWorkplaceAndDateParams{
+		ID:      workplace.ID,
+		MinDate: from,
+		MaxDate: to,
+	})
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	names := map[int64]string{}
+	planned := make([]shift.Planned, 0, len(shifts))
+	for _, s := range shifts {
+		names[s.EmployeeID] = s.EmployeeName
+		planned = append(planned, shift.Planned{
+			ID:         s.ID,
+			EmployeeID: s.EmployeeID,
+			Date:       s.Date.Time,
+			Start:      util.ClockDuration(s.StartTime.Time),
+			End:        util.ClockDuration(s.EndTime.Time),
+		})
+	}
+	actual := make([]shift.Actual, 0, len(entries))
+	for _, e := range entries {
+		// an absence is not work
+		if e.Attendance.Valid && !e.Attendance.Bool {
+			continue
+		}
+		names[e.EmployeeID] = e.EmployeeName
+		actual = append(actual, shift.Actual{
+			ID:         e.ID,
+			EmployeeID: e.EmployeeID,
+			Date:       e.Date.Time,
+			Start:      util.ClockDuration(e.StartTime),
+			End:        util.ClockDuration(e.EndTime),
+			Timed:      e.StartTime.Valid && e.EndTime.Valid,
+		})
+	}
+
+	findings := shift.Compare(planned, actual, time.Duration(grace)*time.Minute, util.Today(loc).Time)
+	res := make([]ShiftFinding, 0, len(findings))
+	for _, f := range findings {
+		res = append(res, ShiftFinding{
+			Kind:         f.Kind,
+			EmployeeID:   f.EmployeeID,
+			EmployeeName: names[f.EmployeeID],
+			Date:         util.NewDate(f.Date),
+			ShiftID:      f.ShiftID,
+			EntryID:      f.EntryID,
+			Minutes:      f.Minutes,
+		})
+	}
+
+	c.JSON(http.StatusOK, res)
+}
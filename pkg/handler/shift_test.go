@@ -0,0 +1,132 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/handler"
+	"github.com/mio256/wplus-server/pkg/infra"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/shift"
+	"github.com/mio256/wplus-server/pkg/test"
+	"github.com/mio256/wplus-server/pkg/ui"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShiftComparison(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	dbConn := infra.ConnectDB(c)
+
+	office := test.CreateOffice(t, c, dbConn, nil)
+	workplace := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+		v.WorkType = rdb.WorkTypeTime
+	})
+	employee := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+		v.WorkplaceID = workplace.ID
+	})
+	manager := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+		v.WorkplaceID = workplace.ID
+	})
+
+	token := func(claims util.UserClaims) string {
+		claims.OfficeID = uint64(office.ID)
+		s, err := util.GenerateToken(claims)
+		require.NoError(t, err)
+		return s
+	}
+	employeeToken := token(util.UserClaims{Role: string(rdb.UserTypeEmployee), EmployeeID: uint64(employee.ID), WorkplaceID: uint64(workplace.ID)})
+	managerToken := token(util.UserClaims{Role: string(rdb.UserTypeManager), EmployeeID: uint64(manager.ID), WorkplaceID: uint64(workplace.ID)})
+
+	do := func(method, path, token string, body any) *httptest.ResponseRecorder {
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		req, err := http.NewRequest(method, path, bytes.NewBuffer(b))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w = do("POST", ui.WorkplacePath+fmt.Sprintf("%d/shift_templates/", workplace.ID), employeeToken, map[string]any{"name": "早番", "start_time": "09:00", "end_time": "17:00"})
+	require.Equal(t, http.StatusForbidden, w.Code)
+	w = do("POST", ui.WorkplacePath+fmt.Sprintf("%d/shift_templates/", workplace.ID), managerToken, map[string]any{"name": "早番", "start_time": "09:00", "end_time": "17:00"})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var template rdb.ShiftTemplate
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &template))
+
+	var shifts []rdb.Shift
+	for _, date := range []string{"2024-04-01", "2024-04-02"} {
+		w = do("POST", ui.ShiftPath, managerToken, map[string]any{"workplace_id": workplace.ID, "employee_id": employee.ID, "date": date, "shift_template_id": template.ID})
+		require.Equal(t, http.StatusCreated, w.Code)
+		var s rdb.Shift
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &s))
+		assert.Equal(t, "09:00:00", util.FormatClock(s.StartTime.Time))
+		shifts = append(shifts, s)
+	}
+
+	// drafts are hidden from employees
+	w = do("GET", ui.ShiftPath+"?from=2024-04-01&to=2024-04-30", employeeToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed []rdb.GetShiftsRow
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Len(t, listed, 0)
+
+	w = do("POST", ui.ShiftPath+"publish/", managerToken, map[string]any{"workplace_id": workplace.ID, "from": "2024-04-01", "to": "2024-04-30"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = do("GET", ui.ShiftPath+"?from=2024-04-01&to=2024-04-30", employeeToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed, 2)
+	assert.Equal(t, "早番", listed[0].ShiftTemplateName.String)
+
+	clock := func(h, m int) pgtype.Time {
+		return util.NewClock(time.Date(2000, 1, 1, h, m, 0, 0, time.UTC))
+	}
+	// late on the first day, nothing on the second and unscheduled on the third
+	late := test.CreateWorkEntries(t, c, dbConn, func(v *rdb.WorkEntry) {
+		v.EmployeeID = employee.ID
+		v.WorkplaceID = workplace.ID
+		v.Date = util.NewDate(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC))
+		v.Hours = pgtype.Int2{}
+		v.StartTime = clock(9, 20)
+		v.EndTime = clock(17, 0)
+	})
+	extra := test.CreateWorkEntries(t, c, dbConn, func(v *rdb.WorkEntry) {
+		v.EmployeeID = employee.ID
+		v.WorkplaceID = workplace.ID
+		v.Date = util.NewDate(time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC))
+		v.Hours = pgtype.Int2{}
+		v.StartTime = clock(9, 0)
+		v.EndTime = clock(12, 0)
+	})
+
+	w = do("GET", ui.ShiftPath+fmt.Sprintf("comparison/?workplace_id=%d&from=2024-04-01&to=2024-04-30&grace_minutes=5", workplace.ID), managerToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var findings []handler.ShiftFinding
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &findings))
+	require.Len(t, findings, 3)
+	assert.Equal(t, shift.KindLateArrival, findings[0].Kind)
+	assert.Equal(t, shifts[0].ID, findings[0].ShiftID)
+	assert.Equal(t, late.ID, findings[0].EntryID)
+	assert.Equal(t, 20, findings[0].Minutes)
+	assert.Equal(t, shift.KindMissingPunch, findings[1].Kind)
+	assert.Equal(t, shifts[1].ID, findings[1].ShiftID)
+	assert.Equal(t, shift.KindUnscheduledWork, findings[2].Kind)
+	assert.Equal(t, extra.ID, findings[2].EntryID)
+	assert.Equal(t, employee.Name, findings[2].EmployeeName)
+}
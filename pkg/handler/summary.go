@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/summary"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/taxio/errors"
+)
+
+type EmployeeSummary struct {
+	EmployeeID   int64  `json:"employee_id"`
+	EmployeeName string `json:"employee_name"`
+	summary.Totals
+}
+
+type WorkplaceSummary struct {
+	WorkplaceID   int64  `json:"workplace_id"`
+	WorkplaceName string `json:"workplace_name"`
+	// Employees counts the employees with worked days at the workplace.
+	Employees int `json:"employees"`
+	summary.Totals
+}
+
+// summaryPeriod reads year and month, or from and to, which default to the current month of the office.
+func summaryPeriod(c *gin.Context, loc *time.Location) (pgtype.Date, pgtype.Date, bool) {
+	today := util.Today(loc).Time
+	from, to := util.MonthRange(today.Year(), today.Month())
+	if c.Query("year") != "" || c.Query("month") != "" {
+		year, err := strconv.Atoi(c.Query("year"))
+		if err != nil {
+			return from, to, false
+		}
+		month, err := strconv.Atoi(c.Query("month"))
+		if err != nil || month < 1 || month > 12 {
+			return from, to, false
+		}
+		from, to = util.MonthRange(year, time.Month(month))
+		return from, to, true
+	}
+	var err error
+	if s := c.Query("from"); s != "" {
+		if from, err = util.ParseLocalDate(s, loc); err != nil {
+			return from, to, false
+		}
+	}
+	if s := c.Query("to"); s != "" {
+		if to, err = util.ParseLocalDate(s, loc); err != nil {
+			return from, to, false
+		}
+	}
+	if to.Time.Before(from.Time) || to.Time.Sub(from.Time) >= maxPeriodDays*24*time.Hour {
+		return from, to, false
+	}
+	return from, to, true
+}
+
+// summaryScope is what a summary request covers.
+type summaryScope struct {
+	from, to pgtype.Date
+	// workplaceID and employeeID are valid when the request or the role of the user narrows the summary down.
+	workplaceID, employeeID pgtype.Int8
+	entries                 []summary.Entry
+}
+
+// readSummaryScope reads the period and the workplace_id query, narrows them down to what the user may see
+// and reads the entries to add up.
+func readSummaryScope(c *gin.Context, repo *rdb.Queries, user *util.UserClaims) (summaryScope, bool) {
+	var scope summaryScope
+	officeID := int64(user.OfficeID)
+
+	loc, err := officeLocation(c, repo, officeID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return scope, false
+	}
+	var ok bool
+	if scope.from, scope.to, ok = summaryPeriod(c, loc); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return scope, false
+	}
+
+	if s := c.Query("workplace_id"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid query",
+			})
+			return scope, false
+		}
+		scope.workplaceID = pgtype.Int8{Int64: id, Valid: true}
+	}
+	switch user.Role {
+	case "admin":
+	case "manager":
+		mine, err := managerWorkplaceID(c, repo, user)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return scope, false
+		}
+		if scope.workplaceID.Valid && scope.workplaceID.Int64 != mine {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": "your workplace is different",
+			})
+			return scope, false
+		}
+		scope.workplaceID = pgtype.Int8{Int64: mine, Valid: true}
+	default:
+		if user.EmployeeID == 0 {
+			c.Error(errors.New("user is not employee: employee_id is not set"))
+			return scope, false
+		}
+		scope.employeeID = pgtype.Int8{Int64: int64(user.EmployeeID), Valid: true}
+	}
+
+	scope.entries, err = readSummaryEntries(c, repo, officeID, scope, scope.workplaceID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return scope, false
+	}
+	return scope, true
+}
+
+// readSummaryEntries reads the entries of the period and employee of scope at workplaceID, or at every workplace
+// of the office when it is null.
+func readSummaryEntries(c *gin.Context, repo *rdb.Queries, officeID int64, scope summaryScope, workplaceID pgtype.Int8) ([]summary.Entry, error) {
+	rows, err := repo.GetWorkEntriesForSummary(c, rdb.GetWorkEntriesForSummaryParams{
+		OfficeID:    officeID,
+		FromDate:    scope.from,
+		ToDate:      scope.to,
+		WorkplaceID: workplaceID,
+		EmployeeID:  scope.employeeID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	entries := make([]summary.Entry, 0, len(rows))
+	for _, r := range rows {
+		entries = append(entries, summary.NewEntry(r.EmployeeID, r.WorkplaceID, r.Date, r.Hours, r.StartTime, r.EndTime, r.Attendance))
+	}
+	return entries, nil
+}
+
+// GetEmployeeSummaries returns the totals of each employee for a month (year and month) or between from and to,
+// this month by default. workplace_id counts the work at one workplace only. Managers see the employees
+// assigned to their workplace in the period, and employees see themselves.
+func GetEmployeeSummaries(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+
+	scope, ok := readSummaryScope(c, repo, user)
+	if !ok {
+		return
+	}
+
+	var employees []rdb.Employee
+	var err error
+	if scope.employeeID.Valid {
+		var e rdb.Employee
+		e, err = repo.GetEmployee(c, scope.employeeID.Int64)
+		employees = append(employees, e)
+	} else if scope.workplaceID.Valid {
+		employees, err = repo.OutputEmployeesByWorkplaceAndDate(c, rdb.OutputEmployeesByWorkplaceAndDateParams{
+			WorkplaceID: scope.workplaceID.Int64,
+			MaxDate:     scope.to,
+			MinDate:     scope.from,
+		})
+	} else {
+		employees, err = repo.GetEmployeesByOffice(c, int64(user.OfficeID))
+	}
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	totals := summary.ByEmployee(scope.entries)
+	res := make([]EmployeeSummary, 0, len(employees))
+	for _, e := range employees {
+		res = append(res, EmployeeSummary{
+			EmployeeID:   e.ID,
+			EmployeeName: e.Name,
+			Totals:       totals[e.ID],
+		})
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetWorkplaceSummaries returns the totals of each workplace of the office for a month (year and month) or between
+// from and to, this month by default. Managers see their workplace only.
+func GetWorkplaceSummaries(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	if user.Role != "admin" && user.Role != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin or manager",
+		})
+		return
+	}
+
+	scope, ok := readSummaryScope(c, repo, user)
+	if !ok {
+		return
+	}
+
+	workplaces, err := repo.GetWorkplaces(c, int64(user.OfficeID))
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	entries := scope.entries
+	if scope.workplaceID.Valid {
+		// the break of a day is split with the other workplaces worked at on the day
+		if entries, err = readSummaryEntries(c, repo, int64(user.OfficeID), scope, pgtype.Int8{}); err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+	}
+	totals := summary.ByWorkplace(entries)
+	employees := summary.EmployeesByWorkplace(entries)
+	res := make([]WorkplaceSummary, 0, len(workplaces))
+	for _, w := range workplaces {
+		if scope.workplaceID.Valid && w.ID != scope.workplaceID.Int64 {
+			continue
+		}
+		res = append(res, WorkplaceSummary{
+			WorkplaceID:   w.ID,
+			WorkplaceName: w.Name,
+			Employees:     employees[w.ID],
+			Totals:        totals[w.ID],
+		})
+	}
+
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,231 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/handler"
+	"github.com/mio256/wplus-server/pkg/infra"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/test"
+	"github.com/mio256/wplus-server/pkg/ui"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaries(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	dbConn := infra.ConnectDB(c)
+
+	office := test.CreateOffice(t, c, dbConn, nil)
+	timed := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+		v.WorkType = rdb.WorkTypeTime
+	})
+	attendance := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+		v.WorkType = rdb.WorkTypeAttendance
+	})
+	worker := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+		v.WorkplaceID = timed.ID
+	})
+	member := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+		v.WorkplaceID = attendance.ID
+	})
+
+	clock := func(h int) pgtype.Time {
+		return util.NewClock(time.Date(2000, 1, 1, h, 0, 0, 0, time.UTC))
+	}
+	day := func(d int) pgtype.Date {
+		return util.NewDate(time.Date(2024, 4, d, 0, 0, 0, 0, time.UTC))
+	}
+	// 9 hours and 5 hours, and an entry of the previous month
+	for _, e := range []struct {
+		Date       pgtype.Date
+		Start, End int
+	}{{day(1), 9, 18}, {day(2), 9, 14}, {util.NewDate(time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)), 9, 18}} {
+		test.CreateWorkEntries(t, c, dbConn, func(v *rdb.WorkEntry) {
+			v.EmployeeID = worker.ID
+			v.WorkplaceID = timed.ID
+			v.Date = e.Date
+			v.Hours = pgtype.Int2{}
+			v.StartTime = clock(e.Start)
+			v.EndTime = clock(e.End)
+		})
+	}
+	for d, present := range map[int]bool{1: true, 2: true, 3: false} {
+		test.CreateWorkEntries(t, c, dbConn, func(v *rdb.WorkEntry) {
+			v.EmployeeID = member.ID
+			v.WorkplaceID = attendance.ID
+			v.Date = day(d)
+			v.Hours = pgtype.Int2{}
+			v.Attendance = pgtype.Bool{Bool: present, Valid: true}
+		})
+	}
+
+	token := func(claims util.UserClaims) string {
+		claims.OfficeID = uint64(office.ID)
+		s, err := util.GenerateToken(claims)
+		require.NoError(t, err)
+		return s
+	}
+	adminToken := token(util.UserClaims{Role: string(rdb.UserTypeAdmin)})
+	managerToken := token(util.UserClaims{Role: string(rdb.UserTypeManager), EmployeeID: uint64(worker.ID), WorkplaceID: uint64(timed.ID)})
+	employeeToken := token(util.UserClaims{Role: string(rdb.UserTypeEmployee), EmployeeID: uint64(member.ID), WorkplaceID: uint64(attendance.ID)})
+
+	get := func(path, token string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w = get(ui.SummaryPath+"employees/?year=2024&month=4", adminToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	var employees []handler.EmployeeSummary
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &employees))
+	byID := map[int64]handler.EmployeeSummary{}
+	for _, e := range employees {
+		byID[e.EmployeeID] = e
+	}
+	require.Contains(t, byID, worker.ID)
+	assert.Equal(t, 2, byID[worker.ID].DaysWorked)
+	assert.Equal(t, 14*60, byID[worker.ID].TotalMinutes)
+	assert.Equal(t, 14*60-60, byID[worker.ID].NetMinutes)
+	assert.Equal(t, 7*60, byID[worker.ID].AverageShiftMinutes)
+	assert.Equal(t, 2, byID[member.ID].DaysWorked)
+	assert.Equal(t, 2, byID[member.ID].AttendanceCount)
+	assert.Equal(t, 0, byID[member.ID].TotalMinutes)
+
+	w = get(ui.SummaryPath+"workplaces/?from=2024-04-01&to=2024-04-30", adminToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	var workplaces []handler.WorkplaceSummary
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &workplaces))
+	require.Len(t, workplaces, 2)
+	for _, s := range workplaces {
+		assert.Equal(t, 1, s.Employees)
+		assert.Equal(t, 2, s.DaysWorked)
+	}
+
+	// managers see their workplace only
+	w = get(ui.SummaryPath+"workplaces/?year=2024&month=4", managerToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &workplaces))
+	require.Len(t, workplaces, 1)
+	assert.Equal(t, timed.ID, workplaces[0].WorkplaceID)
+	w = get(ui.SummaryPath+fmt.Sprintf("employees/?year=2024&month=4&workplace_id=%d", attendance.ID), managerToken)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	// the workplace of the token is stale after a transfer, and the current one counts
+	staleToken := token(util.UserClaims{Role: string(rdb.UserTypeManager), EmployeeID: uint64(worker.ID), WorkplaceID: uint64(attendance.ID)})
+	w = get(ui.SummaryPath+"workplaces/?year=2024&month=4", staleToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &workplaces))
+	require.Len(t, workplaces, 1)
+	assert.Equal(t, timed.ID, workplaces[0].WorkplaceID)
+	w = get(ui.SummaryPath+fmt.Sprintf("employees/?year=2024&month=4&workplace_id=%d", attendance.ID), staleToken)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// employees see themselves
+	w = get(ui.SummaryPath+"employees/?year=2024&month=4", employeeToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &employees))
+	require.Len(t, employees, 1)
+	assert.Equal(t, member.ID, employees[0].EmployeeID)
+	w = get(ui.SummaryPath+"workplaces/", employeeToken)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = get(ui.SummaryPath+"employees/?year=2024&month=13", adminToken)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestWorkplaceSummariesSplitBreak(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	dbConn := infra.ConnectDB(c)
+
+	office := test.CreateOffice(t, c, dbConn, nil)
+	first := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+		v.WorkType = rdb.WorkTypeTime
+	})
+	second := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+		v.WorkType = rdb.WorkTypeTime
+	})
+	worker := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+		v.WorkplaceID = first.ID
+	})
+
+	clock := func(h int) pgtype.Time {
+		return util.NewClock(time.Date(2000, 1, 1, h, 0, 0, 0, time.UTC))
+	}
+	// 5 hours at the first workplace and 4 at the second on the same day, so an hour of break split 33 to 27
+	for _, e := range []struct {
+		WorkplaceID int64
+		Start, End  int
+	}{{first.ID, 9, 14}, {second.ID, 14, 18}} {
+		test.CreateWorkEntries(t, c, dbConn, func(v *rdb.WorkEntry) {
+			v.EmployeeID = worker.ID
+			v.WorkplaceID = e.WorkplaceID
+			v.Date = util.NewDate(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC))
+			v.Hours = pgtype.Int2{}
+			v.StartTime = clock(e.Start)
+			v.EndTime = clock(e.End)
+		})
+	}
+
+	token := func(claims util.UserClaims) string {
+		claims.OfficeID = uint64(office.ID)
+		s, err := util.GenerateToken(claims)
+		require.NoError(t, err)
+		return s
+	}
+	get := func(path, token string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	adminToken := token(util.UserClaims{Role: string(rdb.UserTypeAdmin)})
+
+	w = get(ui.SummaryPath+"employees/?year=2024&month=4", adminToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	var employees []handler.EmployeeSummary
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &employees))
+	require.Len(t, employees, 1)
+	assert.Equal(t, 9*60-60, employees[0].NetMinutes)
+
+	w = get(ui.SummaryPath+"workplaces/?year=2024&month=4", adminToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	var workplaces []handler.WorkplaceSummary
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &workplaces))
+	net := map[int64]int{}
+	for _, s := range workplaces {
+		net[s.WorkplaceID] = s.NetMinutes
+	}
+	assert.Equal(t, map[int64]int{first.ID: 5*60 - 33, second.ID: 4*60 - 27}, net)
+
+	// the manager of one workplace sees the same share of the break
+	managerToken := token(util.UserClaims{Role: string(rdb.UserTypeManager), EmployeeID: uint64(worker.ID), WorkplaceID: uint64(first.ID)})
+	w = get(ui.SummaryPath+"workplaces/?year=2024&month=4", managerToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &workplaces))
+	require.Len(t, workplaces, 1)
+	assert.Equal(t, 5*60-33, workplaces[0].NetMinutes)
+}
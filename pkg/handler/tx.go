@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/taxio/errors"
+)
+
+// txKey is the context key of the transaction of a request.
+const txKey = "tx"
+
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// queries returns the queries of the request, which run in its transaction when Transaction has begun one.
+func queries(c *gin.Context) *rdb.Queries {
+	repo := rdb.New(c.MustGet("db").(rdb.DBTX))
+	if tx, ok := c.Get(txKey); ok {
+		return repo.WithTx(tx.(pgx.Tx))
+	}
+	return repo
+}
+
+// Transaction runs each POST, PUT, PATCH and DELETE request in a transaction, which is committed when the handler
+// responds with a status below 400 and records no error, and rolled back otherwise.
+// The response is held back until the commit, so a failed commit, or an error recorded after a success was
+// written, is answered with 500 instead of the success.
+func Transaction() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+
+		db, ok := c.MustGet("db").(txBeginner)
+		if !ok {
+			c.Error(errors.New("db does not support transactions"))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		tx, err := db.Begin(c)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		defer util.DeferRollback(c, tx)
+		c.Set(txKey, tx)
+
+		w := &txWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if w.status >= http.StatusBadRequest || len(c.Errors) > 0 {
+			if err := tx.Rollback(c); err != nil {
+				c.Error(errors.Wrap(err))
+			}
+			// a success written before the error would report changes that were just rolled back
+			if len(c.Errors) > 0 && w.status < http.StatusBadRequest {
+				w.status = http.StatusInternalServerError
+				w.body.Reset()
+			}
+		} else if err := tx.Commit(c); err != nil {
+			c.Error(errors.Wrap(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		w.flush(c.Request.Context())
+	}
+}
+
+// txWriter holds the response of a handler back until its transaction ends.
+type txWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *txWriter) WriteHeader(code int) {
+	if !w.written {
+		w.status = code
+	}
+}
+
+func (w *txWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *txWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.body.Write(b)
+}
+
+func (w *txWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *txWriter) Status() int {
+	return w.status
+}
+
+func (w *txWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *txWriter) Written() bool {
+	return w.written
+}
+
+// Flush is a no-op; the response is sent after the transaction ends.
+func (w *txWriter) Flush() {}
+
+func (w *txWriter) flush(ctx context.Context) {
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() > 0 {
+		if _, err := w.ResponseWriter.Write(w.body.Bytes()); err != nil {
+			util.LogError(ctx, err)
+		}
+	} else {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
@@ -0,0 +1,153 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-faker/faker/v4"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/handler"
+	"github.com/mio256/wplus-server/pkg/infra"
+	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/test"
+	"github.com/mio256/wplus-server/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taxio/errors"
+)
+
+func TestTransactionRollback(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	dbConn := infra.ConnectDB(c)
+	repo := rdb.New(dbConn)
+
+	office := test.CreateOffice(t, c, dbConn, nil)
+	workplace := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+	})
+	_, token, _ := test.CreateUserWithToken(t, c, dbConn, func(v *rdb.User) {
+		v.OfficeID = office.ID
+		v.Role = rdb.UserTypeAdmin
+	})
+
+	// the employee is created before the user fails on an unknown role
+	b, err := json.Marshal(map[string]any{
+		"name":         faker.Username(),
+		"workplace_id": workplace.ID,
+		"role":         "owner",
+		"password":     faker.Password(),
+	})
+	require.NoError(t, err)
+	req, err := http.NewRequest("POST", ui.UserPath, bytes.NewBuffer(b))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	employees, err := repo.GetEmployees(c, workplace.ID)
+	require.NoError(t, err)
+	assert.Empty(t, employees)
+	logs, err := repo.CountAuditLogs(c, rdb.CountAuditLogsParams{
+		OfficeID: office.ID,
+		Resource: pgtype.Text{String: "employee", Valid: true},
+	})
+	require.NoError(t, err)
+	assert.Zero(t, logs)
+}
+
+// fakeTx records how a transaction ends. The other methods of pgx.Tx are not called.
+type fakeTx struct {
+	pgx.Tx
+	committed, rolledBack bool
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	if tx.committed || tx.rolledBack {
+		return pgx.ErrTxClosed
+	}
+	tx.rolledBack = true
+	return nil
+}
+
+type fakeDB struct {
+	tx *fakeTx
+}
+
+func (db *fakeDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	db.tx = &fakeTx{}
+	return db.tx, nil
+}
+
+func TestTransactionErrorAfterWrite(t *testing.T) {
+	tests := map[string]struct {
+		Handler      gin.HandlerFunc
+		WantCode     int
+		WantBody     string
+		WantCommit   bool
+		WantRollback bool
+	}{
+		"created": {
+			Handler: func(c *gin.Context) {
+				c.JSON(http.StatusCreated, gin.H{"id": 1})
+			},
+			WantCode:   http.StatusCreated,
+			WantBody:   `{"id":1}`,
+			WantCommit: true,
+		},
+		"error-after-created": {
+			Handler: func(c *gin.Context) {
+				c.JSON(http.StatusCreated, gin.H{"id": 1})
+				c.Error(errors.New("audit failed"))
+			},
+			WantCode:     http.StatusInternalServerError,
+			WantRollback: true,
+		},
+		"error-after-bad-request": {
+			Handler: func(c *gin.Context) {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input"})
+				c.Error(errors.New("bind failed"))
+			},
+			WantCode:     http.StatusBadRequest,
+			WantBody:     `{"message":"Invalid input"}`,
+			WantRollback: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			db := &fakeDB{}
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("db", db)
+			}, handler.Transaction())
+			router.POST("/", tt.Handler)
+
+			req, err := http.NewRequest("POST", "/", nil)
+			require.NoError(t, err)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.WantCode, w.Code)
+			assert.Equal(t, tt.WantBody, w.Body.String())
+			require.NotNil(t, db.tx)
+			assert.Equal(t, tt.WantCommit, db.tx.committed)
+			assert.Equal(t, tt.WantRollback, db.tx.rolledBack)
+		})
+	}
+}
@@ -10,8 +10,7 @@ import (
 )
 
 func PostUserAndEmployee(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
+	repo := queries(c)
 
 	user := c.MustGet("user").(*util.UserClaims)
 
@@ -42,13 +41,23 @@ func PostUserAndEmployee(c *gin.Context) {
 	}
 
 	employee, err := repo.CreateEmployee(c, rdb.CreateEmployeeParams{
-		Name:        input.Name,
-		WorkplaceID: input.WorkplaceID,
+		Name:           input.Name,
+		WorkplaceID:    input.WorkplaceID,
+		EmploymentType: rdb.EmploymentTypeFullTime,
 	})
 	if err != nil {
 		c.Error(errors.Wrap(err))
 		return
 	}
+	if err := writeAudit(c, repo, auditEvent{
+		Action:     AuditActionCreate,
+		Resource:   AuditResourceEmployee,
+		ResourceID: employee.ID,
+		After:      employee,
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
 
 	created, err := repo.CreateUser(c, rdb.CreateUserParams{
 		OfficeID: int64(user.OfficeID),
@@ -64,6 +73,14 @@ func PostUserAndEmployee(c *gin.Context) {
 		c.Error(errors.Wrap(err))
 		return
 	}
-
+	if err := writeAudit(c, repo, auditEvent{
+		Action:     AuditActionCreate,
+		Resource:   AuditResourceUser,
+		ResourceID: created.ID,
+		After:      created,
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
 	c.IndentedJSON(http.StatusCreated, created)
 }
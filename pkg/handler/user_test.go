@@ -11,15 +11,18 @@ import (
 	"github.com/mio256/wplus-server/pkg/infra/rdb"
 	"github.com/mio256/wplus-server/pkg/test"
 	"github.com/mio256/wplus-server/pkg/ui"
+	"github.com/mio256/wplus-server/pkg/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"sync"
 	"testing"
 )
 
 func TestPostUserAndEmployee(t *testing.T) {
-	router := ui.SetupRouter()
+	router := ui.SetupRouter(test.NewApp(t))
 
 	tests := map[string]struct {
 		Role        rdb.UserType
@@ -105,10 +108,76 @@ func TestPostUserAndEmployee(t *testing.T) {
 				assert.True(t, res.EmployeeID.Valid)
 
 				t.Cleanup(func() {
-					require.NoError(t, rdb.New(dbConn).TestDeleteUser(c, res.ID))
+					require.NoError(t, rdb.New(dbConn).TestDeleteUser(c, rdb.TestDeleteUserParams{ID: res.ID, OfficeID: res.OfficeID}))
 					require.NoError(t, rdb.New(dbConn).TestDeleteEmployee(c, res.EmployeeID.Int64))
 				})
 			}
 		})
 	}
 }
+
+func TestPostUserAndEmployeeConcurrent(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	dbConn := infra.ConnectDB(c)
+
+	office := test.CreateOffice(t, c, dbConn, nil)
+	workplace := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+	})
+	// the admin is not a user of the office, so the ids start at 1
+	token, err := util.GenerateToken(util.UserClaims{OfficeID: uint64(office.ID), Role: string(rdb.UserTypeAdmin)})
+	require.NoError(t, err)
+
+	const n = 30
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var created []rdb.User
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b, err := json.Marshal(map[string]any{
+				"name":         fmt.Sprintf("onboarding %d", i),
+				"workplace_id": workplace.ID,
+				"role":         "employee",
+				"password":     faker.Password(),
+			})
+			if !assert.NoError(t, err) {
+				return
+			}
+			req := httptest.NewRequest("POST", ui.UserPath, bytes.NewReader(b))
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if !assert.Equal(t, http.StatusCreated, w.Code, w.Body.String()) {
+				return
+			}
+			var res rdb.User
+			if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res)) {
+				mu.Lock()
+				created = append(created, res)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	t.Cleanup(func() {
+		for _, u := range created {
+			require.NoError(t, rdb.New(dbConn).TestDeleteUser(c, rdb.TestDeleteUserParams{ID: u.ID, OfficeID: u.OfficeID}))
+			require.NoError(t, rdb.New(dbConn).TestDeleteEmployee(c, u.EmployeeID.Int64))
+		}
+	})
+
+	require.Len(t, created, n)
+	ids := make([]int64, 0, n)
+	for _, u := range created {
+		ids = append(ids, u.ID)
+	}
+	slices.Sort(ids)
+	for i, id := range ids {
+		assert.Equal(t, int64(i+1), id)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/conflict"
 	"github.com/mio256/wplus-server/pkg/infra/rdb"
 	"github.com/mio256/wplus-server/pkg/util"
 	"github.com/taxio/errors"
@@ -23,9 +25,149 @@ type PostWorkEntryParams struct {
 	Comment     string `json:"comment"`
 }
 
+// WorkEntryResponse is a work entry rendered in the time zone of its office.
+type WorkEntryResponse struct {
+	ID            int64       `json:"id"`
+	EmployeeID    int64       `json:"employee_id"`
+	EmployeeName  string      `json:"employee_name"`
+	WorkplaceID   int64       `json:"workplace_id"`
+	WorkplaceName string      `json:"workplace_name"`
+	Date          pgtype.Date `json:"date"`
+	Hours         pgtype.Int2 `json:"hours"`
+	StartTime     string      `json:"start_time"`
+	EndTime       string      `json:"end_time"`
+	Attendance    pgtype.Bool `json:"attendance"`
+	Comment       pgtype.Text `json:"comment"`
+	CreatedAt     string      `json:"created_at"`
+	UpdatedAt     string      `json:"updated_at"`
+}
+
+func NewWorkEntryResponse(e rdb.WorkEntry, employeeName, workplaceName string, loc *time.Location) WorkEntryResponse {
+	return WorkEntryResponse{
+		ID:            e.ID,
+		EmployeeID:    e.EmployeeID,
+		EmployeeName:  employeeName,
+		WorkplaceID:   e.WorkplaceID,
+		WorkplaceName: workplaceName,
+		Date:          e.Date,
+		Hours:         e.Hours,
+		StartTime:     util.FormatClock(e.StartTime),
+		EndTime:       util.FormatClock(e.EndTime),
+		Attendance:    e.Attendance,
+		Comment:       e.Comment,
+		CreatedAt:     util.FormatTimestamp(e.CreatedAt, loc),
+		UpdatedAt:     util.FormatTimestamp(e.UpdatedAt, loc),
+	}
+}
+
+func newWorkEntryResponses(rows []rdb.ListWorkEntriesRow, loc *time.Location) []WorkEntryResponse {
+	res := make([]WorkEntryResponse, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, NewWorkEntryResponse(rdb.WorkEntry{
+			ID:          r.ID,
+			EmployeeID:  r.EmployeeID,
+			WorkplaceID: r.WorkplaceID,
+			Date:        r.Date,
+			Hours:       r.Hours,
+			StartTime:   r.StartTime,
+			EndTime:     r.EndTime,
+			Attendance:  r.Attendance,
+			Comment:     r.Comment,
+			DeletedAt:   r.DeletedAt,
+			CreatedAt:   r.CreatedAt,
+			UpdatedAt:   r.UpdatedAt,
+		}, r.EmployeeName, r.WorkplaceName, loc))
+	}
+	return res
+}
+
+var workEntrySorts = map[string]bool{
+	"date":      true,
+	"-date":     true,
+	"employee":  true,
+	"-employee": true,
+}
+
+// parseWorkEntriesQuery reads the paging, filter and sort query parameters of the work entry lists.
+// Dates given in from and to are interpreted in loc.
+func parseWorkEntriesQuery(c *gin.Context, officeID int64, loc *time.Location) (rdb.ListWorkEntriesParams, error) {
+	p := rdb.ListWorkEntriesParams{
+		OfficeID: officeID,
+		Sort:     "-date",
+	}
+
+	var err error
+	p.PageLimit, p.PageOffset, err = parsePage(c)
+	if err != nil {
+		return p, errors.Wrap(err)
+	}
+
+	if s := c.Query("from"); s != "" {
+		if p.FromDate, err = util.ParseLocalDate(s, loc); err != nil {
+			return p, errors.Wrap(err)
+		}
+	}
+	if s := c.Query("to"); s != "" {
+		if p.ToDate, err = util.ParseLocalDate(s, loc); err != nil {
+			return p, errors.Wrap(err)
+		}
+	}
+	if p.FromDate.Valid && p.ToDate.Valid && p.FromDate.Time.After(p.ToDate.Time) {
+		return p, errors.New("from is after to")
+	}
+
+	if s := c.Query("employee_id"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return p, errors.Wrap(err)
+		}
+		p.EmployeeID = pgtype.Int8{Int64: id, Valid: true}
+	}
+	if s := c.Query("workplace_id"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return p, errors.Wrap(err)
+		}
+		p.WorkplaceID = pgtype.Int8{Int64: id, Valid: true}
+	}
+
+	if s := c.Query("sort"); s != "" {
+		if !workEntrySorts[s] {
+			return p, errors.New("invalid sort")
+		}
+		p.Sort = s
+	}
+
+	return p, nil
+}
+
+func listWorkEntries(c *gin.Context, repo *rdb.Queries, p rdb.ListWorkEntriesParams, loc *time.Location) {
+	workEntries, err := repo.ListWorkEntries(c, p)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	total, err := repo.CountWorkEntries(c, rdb.CountWorkEntriesParams{
+		OfficeID:    p.OfficeID,
+		WorkplaceID: p.WorkplaceID,
+		EmployeeID:  p.EmployeeID,
+		FromDate:    p.FromDate,
+		ToDate:      p.ToDate,
+	})
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, ListResponse[WorkEntryResponse]{
+		Items:      newWorkEntryResponses(workEntries, loc),
+		Pagination: NewPagination(p.PageLimit, p.PageOffset, total),
+	})
+}
+
 func GetWorkEntriesByOffice(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
+	repo := queries(c)
 
 	user := c.MustGet("user").(*util.UserClaims)
 
@@ -36,18 +178,25 @@ func GetWorkEntriesByOffice(c *gin.Context) {
 		return
 	}
 
-	workEntries, err := repo.GetWorkEntriesByOffice(c, int64(user.OfficeID))
+	loc, err := officeLocation(c, repo, int64(user.OfficeID))
 	if err != nil {
 		c.Error(errors.Wrap(err))
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, workEntries)
+	p, err := parseWorkEntriesQuery(c, int64(user.OfficeID), loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+
+	listWorkEntries(c, repo, p, loc)
 }
 
 func GetWorkEntriesByWorkplace(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
+	repo := queries(c)
 
 	user := c.MustGet("user").(*util.UserClaims)
 
@@ -84,18 +233,26 @@ func GetWorkEntriesByWorkplace(c *gin.Context) {
 		return
 	}
 
-	workEntries, err := repo.GetWorkEntriesByWorkplace(c, workplaceID)
+	loc, err := officeLocation(c, repo, workplace.OfficeID)
 	if err != nil {
 		c.Error(errors.Wrap(err))
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, workEntries)
+	p, err := parseWorkEntriesQuery(c, workplace.OfficeID, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+	p.WorkplaceID = pgtype.Int8{Int64: workplaceID, Valid: true}
+
+	listWorkEntries(c, repo, p, loc)
 }
 
 func GetWorkEntries(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
+	repo := queries(c)
 
 	user := c.MustGet("user").(*util.UserClaims)
 
@@ -156,18 +313,26 @@ func GetWorkEntries(c *gin.Context) {
 		}
 	}
 
-	workEntries, err := repo.GetWorkEntriesByEmployee(c, employeeID)
+	loc, err := officeLocation(c, repo, int64(user.OfficeID))
 	if err != nil {
 		c.Error(errors.Wrap(err))
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, workEntries)
+	p, err := parseWorkEntriesQuery(c, int64(user.OfficeID), loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+	p.EmployeeID = pgtype.Int8{Int64: employeeID, Valid: true}
+
+	listWorkEntries(c, repo, p, loc)
 }
 
 func PostWorkEntry(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
+	repo := queries(c)
 
 	user := c.MustGet("user").(*util.UserClaims)
 
@@ -182,13 +347,6 @@ func PostWorkEntry(c *gin.Context) {
 		c.Error(errors.Wrap(err))
 		return
 	}
-	if employee.WorkplaceID != input.WorkplaceID {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "Invalid input",
-		})
-		return
-	}
-
 	if user.Role == "admin" {
 		employeeOfficeID, err := repo.GetEmployeeOffice(c, employee.ID)
 		if err != nil {
@@ -211,7 +369,7 @@ func PostWorkEntry(c *gin.Context) {
 			c.Error(errors.Wrap(err))
 			return
 		}
-		if employee.WorkplaceID != me.WorkplaceID {
+		if input.WorkplaceID != me.WorkplaceID {
 			c.JSON(http.StatusForbidden, gin.H{
 				"message": "your workplace is different",
 			})
@@ -244,14 +402,29 @@ func PostWorkEntry(c *gin.Context) {
 		c.Error(errors.Wrap(err))
 		return
 	}
-	date, err := time.Parse("2006-01-02T15:04:05.000+09:00", input.Date)
+	loc, err := officeLocation(c, repo, wp.OfficeID)
 	if err != nil {
 		c.Error(errors.Wrap(err))
 		return
 	}
-	p.Date = pgtype.Date{
-		Time:  date,
-		Valid: true,
+	p.Date, err = util.ParseLocalDate(input.Date, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid date",
+		})
+		return
+	}
+	// entries belong to the workplace the employee was assigned to on that day
+	assignedWorkplaceID, err := employeeWorkplaceOn(c, repo, employee, p.Date)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if assignedWorkplaceID != p.WorkplaceID {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid input",
+		})
+		return
 	}
 
 	if input.Attendance && wp.WorkType == rdb.WorkTypeAttendance {
@@ -265,30 +438,18 @@ func PostWorkEntry(c *gin.Context) {
 			Valid: true,
 		}
 	} else if input.StartTime != "" && input.EndTime != "" && wp.WorkType == rdb.WorkTypeTime {
-		startTime, err := time.Parse("2006-01-02T15:04:05.000Z", input.StartTime)
+		p.StartTime, err = util.ParseLocalClock(input.StartTime, loc)
 		if err != nil {
-			c.Error(errors.Wrap(err))
-			return
-		}
-		p.StartTime = pgtype.Time{
-			Microseconds: startTime.UnixMicro(),
-			Valid:        true,
-		}
-		if err != nil {
-			c.Error(errors.Wrap(err))
-			return
-		}
-		endTime, err := time.Parse("2006-01-02T15:04:05.000Z", input.EndTime)
-		if err != nil {
-			c.Error(errors.Wrap(err))
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid start_time",
+			})
 			return
 		}
-		p.EndTime = pgtype.Time{
-			Microseconds: endTime.UnixMicro(),
-			Valid:        true,
-		}
+		p.EndTime, err = util.ParseLocalClock(input.EndTime, loc)
 		if err != nil {
-			c.Error(errors.Wrap(err))
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid end_time",
+			})
 			return
 		}
 	} else {
@@ -298,21 +459,154 @@ func PostWorkEntry(c *gin.Context) {
 		return
 	}
 
-	if p.Comment.Valid {
+	if input.Comment != "" {
 		p.Comment = pgtype.Text{String: input.Comment, Valid: true}
 	}
 
+	// serializes the entries of the employee so that two requests cannot both pass the check
+	if _, err := repo.GetEmployeeForUpdate(c, employee.ID); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	existing, err := repo.GetWorkEntriesByEmployeeAndDate(c, rdb.GetWorkEntriesByEmployeeAndDateParams{
+		EmployeeID: employee.ID,
+		Date:       p.Date,
+	})
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	entries := make([]conflict.Entry, 0, len(existing))
+	for _, e := range existing {
+		entries = append(entries, conflictEntry(e))
+	}
+	candidate := conflictEntry(rdb.WorkEntry{
+		EmployeeID: p.EmployeeID,
+		Date:       p.Date,
+		StartTime:  p.StartTime,
+		EndTime:    p.EndTime,
+		Attendance: p.Attendance,
+	})
+	if found, ok := conflict.Check(entries, candidate, wp.AllowMultipleEntries); ok {
+		c.JSON(http.StatusConflict, gin.H{
+			"message":       conflictMessages[found.Kind],
+			"work_entry_id": found.OtherID,
+		})
+		return
+	}
+
 	workEntry, err := repo.CreateWorkEntry(c, p)
 	if err != nil {
 		c.Error(errors.Wrap(err))
 		return
 	}
-	c.IndentedJSON(http.StatusOK, workEntry)
+	if err := writeAudit(c, repo, auditEvent{
+		Action:     AuditActionCreate,
+		Resource:   AuditResourceWorkEntry,
+		ResourceID: workEntry.ID,
+		After:      workEntry,
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	c.IndentedJSON(http.StatusOK, NewWorkEntryResponse(workEntry, employee.Name, wp.Name, loc))
+}
+
+var conflictMessages = map[string]string{
+	conflict.KindOverlap:             "work entry overlaps another entry",
+	conflict.KindDuplicateAttendance: "attendance is already recorded",
+	conflict.KindMultipleEntries:     "work entry already exists",
+}
+
+func conflictEntry(e rdb.WorkEntry) conflict.Entry {
+	return conflict.Entry{
+		ID:         e.ID,
+		EmployeeID: e.EmployeeID,
+		Date:       e.Date.Time,
+		Start:      util.ClockDuration(e.StartTime),
+		End:        util.ClockDuration(e.EndTime),
+		Timed:      e.StartTime.Valid && e.EndTime.Valid,
+		Attendance: e.Attendance.Valid,
+	}
+}
+
+// WorkEntryConflict is a pair of active work entries that would be rejected today.
+type WorkEntryConflict struct {
+	Kind         string      `json:"kind"`
+	EmployeeID   int64       `json:"employee_id"`
+	EmployeeName string      `json:"employee_name"`
+	Date         pgtype.Date `json:"date"`
+	WorkEntryID  int64       `json:"work_entry_id"`
+	OtherID      int64       `json:"other_id"`
+}
+
+// GetWorkEntryConflicts reports the active entries of a workplace that overlap or duplicate each other,
+// such as those recorded before PostWorkEntry checked for them.
+func GetWorkEntryConflicts(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+
+	workplace, ok := managedWorkplace(c, repo, user, c.Query("workplace_id"))
+	if !ok {
+		return
+	}
+
+	loc, err := officeLocation(c, repo, workplace.OfficeID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	from, to, ok := periodQuery(c, loc)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+
+	rows, err := repo.OutputWorkEntriesByWorkplaceAndDate(c, rdb.OutputWorkEntriesByWorkplaceAndDateParams{
+		ID:      workplace.ID,
+		MinDate: from,
+		MaxDate: to,
+	})
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	names := map[int64]string{}
+	entries := make([]conflict.Entry, 0, len(rows))
+	for _, r := range rows {
+		names[r.EmployeeID] = r.EmployeeName
+		entries = append(entries, conflictEntry(rdb.WorkEntry{
+			ID:         r.ID,
+			EmployeeID: r.EmployeeID,
+			Date:       r.Date,
+			StartTime:  r.StartTime,
+			EndTime:    r.EndTime,
+			Attendance: r.Attendance,
+		}))
+	}
+
+	found := conflict.Find(entries, workplace.AllowMultipleEntries)
+	res := make([]WorkEntryConflict, 0, len(found))
+	for _, f := range found {
+		res = append(res, WorkEntryConflict{
+			Kind:         f.Kind,
+			EmployeeID:   f.EmployeeID,
+			EmployeeName: names[f.EmployeeID],
+			Date:         util.NewDate(f.Date),
+			WorkEntryID:  f.EntryID,
+			OtherID:      f.OtherID,
+		})
+	}
+
+	c.JSON(http.StatusOK, res)
 }
 
 func DeleteWorkEntry(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
+	repo := queries(c)
 
 	user := c.MustGet("user").(*util.UserClaims)
 
@@ -373,5 +667,150 @@ func DeleteWorkEntry(c *gin.Context) {
 		c.Error(errors.Wrap(err))
 		return
 	}
+	if err := writeAudit(c, repo, auditEvent{
+		Action:     AuditActionDelete,
+		Resource:   AuditResourceWorkEntry,
+		ResourceID: workEntry.ID,
+		Before:     workEntry,
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
 	c.Status(http.StatusNoContent)
 }
+
+// DeletedWorkEntryResponse is a deleted work entry that can still be restored.
+type DeletedWorkEntryResponse struct {
+	WorkEntryResponse
+	DeletedAt string `json:"deleted_at"`
+	// DeletedWithEmployee is set when the entry was deleted together with its employee.
+	DeletedWithEmployee bool `json:"deleted_with_employee"`
+}
+
+func GetDeletedWorkEntries(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	if user.Role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin",
+		})
+		return
+	}
+
+	since, err := deletedSince(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+
+	loc, err := officeLocation(c, repo, int64(user.OfficeID))
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	rows, err := repo.GetDeletedWorkEntries(c, rdb.GetDeletedWorkEntriesParams{
+		OfficeID:     int64(user.OfficeID),
+		DeletedSince: since,
+	})
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	res := make([]DeletedWorkEntryResponse, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, DeletedWorkEntryResponse{
+			WorkEntryResponse: NewWorkEntryResponse(rdb.WorkEntry{
+				ID:          r.ID,
+				EmployeeID:  r.EmployeeID,
+				WorkplaceID: r.WorkplaceID,
+				Date:        r.Date,
+				Hours:       r.Hours,
+				StartTime:   r.StartTime,
+				EndTime:     r.EndTime,
+				Attendance:  r.Attendance,
+				Comment:     r.Comment,
+				CreatedAt:   r.CreatedAt,
+				UpdatedAt:   r.UpdatedAt,
+			}, r.EmployeeName, r.WorkplaceName, loc),
+			DeletedAt:           util.FormatTimestamp(r.DeletedAt, loc),
+			DeletedWithEmployee: r.DeletedWithEmployee,
+		})
+	}
+
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+func RestoreWorkEntry(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	if user.Role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin",
+		})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	deleted, err := repo.GetDeletedWorkEntry(c, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "deleted work entry not found",
+		})
+		return
+	} else if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	if deleted.OfficeID != int64(user.OfficeID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "your office is different",
+		})
+		return
+	}
+	if deleted.EmployeeDeletedAt.Valid {
+		c.JSON(http.StatusConflict, gin.H{
+			"message": "employee is deleted",
+		})
+		return
+	}
+	if deleted.WorkplaceDeletedAt.Valid {
+		c.JSON(http.StatusConflict, gin.H{
+			"message": "workplace is deleted",
+		})
+		return
+	}
+
+	loc, err := officeLocation(c, repo, deleted.OfficeID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	workEntry, err := repo.RestoreWorkEntry(c, id)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if err := writeAudit(c, repo, auditEvent{
+		Action:     AuditActionRestore,
+		Resource:   AuditResourceWorkEntry,
+		ResourceID: workEntry.ID,
+		After:      workEntry,
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	c.IndentedJSON(http.StatusOK, NewWorkEntryResponse(workEntry, deleted.EmployeeName, deleted.WorkplaceName, loc))
+}
@@ -2,6 +2,7 @@ package handler_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"math/rand"
@@ -11,18 +12,22 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/conflict"
 	"github.com/mio256/wplus-server/pkg/handler"
 	"github.com/mio256/wplus-server/pkg/infra"
 	"github.com/mio256/wplus-server/pkg/infra/rdb"
 	"github.com/mio256/wplus-server/pkg/test"
 	"github.com/mio256/wplus-server/pkg/ui"
+	"github.com/mio256/wplus-server/pkg/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestGetWorkEntriesByOffice(t *testing.T) {
-	router := ui.SetupRouter()
+	router := ui.SetupRouter(test.NewApp(t))
 
 	tests := map[string]struct {
 		Role    rdb.UserType
@@ -78,31 +83,124 @@ func TestGetWorkEntriesByOffice(t *testing.T) {
 				assert.Equal(t, http.StatusForbidden, w.Code)
 			} else {
 				require.Equal(t, http.StatusOK, w.Code)
-				var res []rdb.GetWorkEntriesByOfficeRow
+				var res handler.ListResponse[handler.WorkEntryResponse]
 				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
-				require.NotEmpty(t, res)
-				var q rdb.GetWorkEntriesByOfficeRow
-				q.ID = created.ID
-				q.EmployeeID = created.EmployeeID
-				q.WorkplaceID = created.WorkplaceID
-				q.Date = created.Date
-				q.Hours = created.Hours
-				q.StartTime = created.StartTime
-				q.EndTime = created.EndTime
-				q.Attendance = created.Attendance
-				q.Comment = created.Comment
-				q.EmployeeName = employee.Name
-				q.WorkplaceName = workplace.Name
-				q.UpdatedAt = created.UpdatedAt
-				q.CreatedAt = created.CreatedAt
-				require.Contains(t, res, q)
+				require.NotEmpty(t, res.Items)
+				require.Equal(t, int64(len(res.Items)), res.Pagination.Total)
+				loc, err := util.LoadLocation(office.TimeZone)
+				require.NoError(t, err)
+				q := handler.NewWorkEntryResponse(*created, employee.Name, workplace.Name, loc)
+				require.Contains(t, res.Items, q)
 			}
 		})
 	}
 }
 
+func TestGetWorkEntriesByOfficeQuery(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	dbConn := infra.ConnectDB(c)
+
+	office := test.CreateOffice(t, c, dbConn, nil)
+	workplace := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+	})
+	employees := []*rdb.Employee{
+		test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+			v.WorkplaceID = workplace.ID
+		}),
+		test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+			v.WorkplaceID = workplace.ID
+		}),
+	}
+	for _, e := range employees {
+		for day := 1; day <= 3; day++ {
+			test.CreateWorkEntries(t, c, dbConn, func(v *rdb.WorkEntry) {
+				v.EmployeeID = e.ID
+				v.WorkplaceID = workplace.ID
+				v.Date = pgtype.Date{Time: time.Date(2024, 4, day, 0, 0, 0, 0, time.UTC), Valid: true}
+			})
+		}
+	}
+	_, token, _ := test.CreateUserWithToken(t, c, dbConn, func(v *rdb.User) {
+		v.OfficeID = office.ID
+	})
+
+	tests := map[string]struct {
+		Query     string
+		WantCode  int
+		WantDates []string
+		WantTotal int64
+		WantNext  bool
+	}{
+		"all": {
+			Query:     "",
+			WantCode:  http.StatusOK,
+			WantDates: []string{"2024-04-03", "2024-04-03", "2024-04-02", "2024-04-02", "2024-04-01", "2024-04-01"},
+			WantTotal: 6,
+		},
+		"limit": {
+			Query:     "?limit=2&offset=2",
+			WantCode:  http.StatusOK,
+			WantDates: []string{"2024-04-02", "2024-04-02"},
+			WantTotal: 6,
+			WantNext:  true,
+		},
+		"range": {
+			Query:     "?from=2024-04-02&to=2024-04-02",
+			WantCode:  http.StatusOK,
+			WantDates: []string{"2024-04-02", "2024-04-02"},
+			WantTotal: 2,
+		},
+		"employee-asc": {
+			Query:     fmt.Sprintf("?employee_id=%d&sort=date", employees[0].ID),
+			WantCode:  http.StatusOK,
+			WantDates: []string{"2024-04-01", "2024-04-02", "2024-04-03"},
+			WantTotal: 3,
+		},
+		"invalid-sort": {
+			Query:    "?sort=comment",
+			WantCode: http.StatusBadRequest,
+		},
+		"invalid-limit": {
+			Query:    "?limit=0",
+			WantCode: http.StatusBadRequest,
+		},
+		"invalid-range": {
+			Query:    "?from=2024-04-03&to=2024-04-01",
+			WantCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", ui.WorkEntryPath+tt.Query, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.WantCode, w.Code)
+			if tt.WantCode != http.StatusOK {
+				return
+			}
+			var res handler.ListResponse[handler.WorkEntryResponse]
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			var dates []string
+			for _, e := range res.Items {
+				dates = append(dates, util.FormatDate(e.Date))
+			}
+			assert.Equal(t, tt.WantDates, dates)
+			assert.Equal(t, tt.WantTotal, res.Pagination.Total)
+			assert.Equal(t, tt.WantNext, res.Pagination.HasNext)
+		})
+	}
+}
+
 func TestGetWorkEntriesByWorkplace(t *testing.T) {
-	router := ui.SetupRouter()
+	router := ui.SetupRouter(test.NewApp(t))
 
 	tests := map[string]struct {
 		Role        rdb.UserType
@@ -177,19 +275,20 @@ func TestGetWorkEntriesByWorkplace(t *testing.T) {
 				assert.Equal(t, http.StatusForbidden, w.Code)
 			} else {
 				require.Equal(t, http.StatusOK, w.Code)
-				var res []rdb.GetWorkEntriesByEmployeeRow
-				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+				var body handler.ListResponse[handler.WorkEntryResponse]
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				res := body.Items
 				require.NotEmpty(t, res)
 				require.Equal(t, created.EmployeeID, res[0].EmployeeID)
 				require.Equal(t, created.WorkplaceID, res[0].WorkplaceID)
-				require.Equal(t, created.Date.Time.Format("2006-01-02T15:04:05.000Z"), res[0].Date.Time.Format("2006-01-02T15:04:05.000Z"))
+				require.Equal(t, util.FormatDate(created.Date), util.FormatDate(res[0].Date))
 				if created.Hours.Valid {
 					require.Equal(t, int(created.Hours.Int16), int(res[0].Hours.Int16))
 				} else if created.Attendance.Valid {
 					require.Equal(t, created.Attendance.Bool, res[0].Attendance.Bool)
 				} else {
-					require.Equal(t, time.UnixMicro(created.StartTime.Microseconds).Format("2006-01-02T15:04:05.000Z"), time.UnixMicro(res[0].StartTime.Microseconds).Format("2006-01-02T15:04:05.000Z"))
-					require.Equal(t, time.UnixMicro(created.EndTime.Microseconds).Format("2006-01-02T15:04:05.000Z"), time.UnixMicro(res[0].EndTime.Microseconds).Format("2006-01-02T15:04:05.000Z"))
+					require.Equal(t, util.FormatClock(created.StartTime), res[0].StartTime)
+					require.Equal(t, util.FormatClock(created.EndTime), res[0].EndTime)
 				}
 				if created.Comment.Valid {
 					require.Equal(t, created.Comment.String, res[0].Comment.String)
@@ -200,7 +299,7 @@ func TestGetWorkEntriesByWorkplace(t *testing.T) {
 }
 
 func TestGetWorkEntries(t *testing.T) {
-	router := ui.SetupRouter()
+	router := ui.SetupRouter(test.NewApp(t))
 
 	tests := map[string]struct {
 		Role        rdb.UserType
@@ -275,19 +374,20 @@ func TestGetWorkEntries(t *testing.T) {
 				assert.Equal(t, http.StatusForbidden, w.Code)
 			} else {
 				require.Equal(t, http.StatusOK, w.Code)
-				var res []rdb.GetWorkEntriesByEmployeeRow
-				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+				var body handler.ListResponse[handler.WorkEntryResponse]
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				res := body.Items
 				require.NotEmpty(t, res)
 				require.Equal(t, created.EmployeeID, res[0].EmployeeID)
 				require.Equal(t, created.WorkplaceID, res[0].WorkplaceID)
-				require.Equal(t, created.Date.Time.Format("2006-01-02T15:04:05.000Z"), res[0].Date.Time.Format("2006-01-02T15:04:05.000Z"))
+				require.Equal(t, util.FormatDate(created.Date), util.FormatDate(res[0].Date))
 				if created.Hours.Valid {
 					require.Equal(t, int(created.Hours.Int16), int(res[0].Hours.Int16))
 				} else if created.Attendance.Valid {
 					require.Equal(t, created.Attendance.Bool, res[0].Attendance.Bool)
 				} else {
-					require.Equal(t, time.UnixMicro(created.StartTime.Microseconds).Format("2006-01-02T15:04:05.000Z"), time.UnixMicro(res[0].StartTime.Microseconds).Format("2006-01-02T15:04:05.000Z"))
-					require.Equal(t, time.UnixMicro(created.EndTime.Microseconds).Format("2006-01-02T15:04:05.000Z"), time.UnixMicro(res[0].EndTime.Microseconds).Format("2006-01-02T15:04:05.000Z"))
+					require.Equal(t, util.FormatClock(created.StartTime), res[0].StartTime)
+					require.Equal(t, util.FormatClock(created.EndTime), res[0].EndTime)
 				}
 				if created.Comment.Valid {
 					require.Equal(t, created.Comment.String, res[0].Comment.String)
@@ -298,18 +398,23 @@ func TestGetWorkEntries(t *testing.T) {
 }
 
 func TestPostWorkEntry(t *testing.T) {
-	router := ui.SetupRouter()
+	router := ui.SetupRouter(test.NewApp(t))
 
 	tests := map[string]struct {
-		WorkType    rdb.WorkType
-		Hours       int
-		StartTime   string
-		EndTime     string
-		Attendance  bool
-		Role        rdb.UserType
-		OtherOffice bool
-		OtherWp     bool
-		WantErr     bool
+		WorkType      rdb.WorkType
+		Date          string
+		Hours         int
+		StartTime     string
+		EndTime       string
+		Attendance    bool
+		Role          rdb.UserType
+		OtherOffice   bool
+		OtherWp       bool
+		WantDate      string
+		WantStartTime string
+		WantEndTime   string
+		NoComment     bool
+		WantErr       bool
 	}{
 		"admin": {
 			WorkType: rdb.WorkTypeHours,
@@ -350,11 +455,47 @@ func TestPostWorkEntry(t *testing.T) {
 			WantErr:  false,
 		},
 		"time": {
-			WorkType:  rdb.WorkTypeTime,
-			StartTime: "1970-01-01T08:00:00.000Z",
-			EndTime:   "1970-01-01T17:00:00.000Z",
-			Role:      rdb.UserTypeAdmin,
-			WantErr:   false,
+			WorkType:      rdb.WorkTypeTime,
+			StartTime:     "1970-01-01T08:00:00.000+09:00",
+			EndTime:       "1970-01-01T17:00:00.000+09:00",
+			Role:          rdb.UserTypeAdmin,
+			WantStartTime: "08:00:00",
+			WantEndTime:   "17:00:00",
+			WantErr:       false,
+		},
+		"time-utc": {
+			WorkType:      rdb.WorkTypeTime,
+			StartTime:     "1969-12-31T23:00:00.000Z",
+			EndTime:       "1970-01-01T08:00:00.000Z",
+			Role:          rdb.UserTypeAdmin,
+			WantStartTime: "08:00:00",
+			WantEndTime:   "17:00:00",
+			WantErr:       false,
+		},
+		"time-clock": {
+			WorkType:      rdb.WorkTypeTime,
+			StartTime:     "08:00",
+			EndTime:       "17:00",
+			Role:          rdb.UserTypeAdmin,
+			WantStartTime: "08:00:00",
+			WantEndTime:   "17:00:00",
+			WantErr:       false,
+		},
+		"date-utc": {
+			WorkType: rdb.WorkTypeHours,
+			Date:     "2006-01-01T15:30:00.000Z",
+			Hours:    rand.Intn(23) + 1,
+			Role:     rdb.UserTypeAdmin,
+			WantDate: "2006-01-02",
+			WantErr:  false,
+		},
+		"date-plain": {
+			WorkType: rdb.WorkTypeHours,
+			Date:     "2006-01-02",
+			Hours:    rand.Intn(23) + 1,
+			Role:     rdb.UserTypeAdmin,
+			WantDate: "2006-01-02",
+			WantErr:  false,
 		},
 		"attendance": {
 			WorkType:   rdb.WorkTypeAttendance,
@@ -362,6 +503,13 @@ func TestPostWorkEntry(t *testing.T) {
 			Role:       rdb.UserTypeAdmin,
 			WantErr:    false,
 		},
+		"no-comment": {
+			WorkType:  rdb.WorkTypeHours,
+			Hours:     rand.Intn(23) + 1,
+			Role:      rdb.UserTypeAdmin,
+			NoComment: true,
+			WantErr:   false,
+		},
 	}
 
 	for name, tt := range tests {
@@ -394,16 +542,23 @@ func TestPostWorkEntry(t *testing.T) {
 				}
 			})
 
+			date := tt.Date
+			if date == "" {
+				date = "2006-01-02T00:00:00.000+09:00"
+			}
 			p := handler.PostWorkEntryParams{
 				EmployeeID:  e.ID,
 				WorkplaceID: wp.ID,
-				Date:        "2006-01-02T00:00:00.000+09:00",
+				Date:        date,
 				Hours:       tt.Hours,
 				StartTime:   tt.StartTime,
 				EndTime:     tt.EndTime,
 				Attendance:  tt.Attendance,
 				Comment:     "test",
 			}
+			if tt.NoComment {
+				p.Comment = ""
+			}
 			b, err := json.Marshal(p)
 			require.NoError(t, err)
 			body := bytes.NewBuffer(b)
@@ -414,28 +569,33 @@ func TestPostWorkEntry(t *testing.T) {
 			c.Request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 			router.ServeHTTP(w, c.Request)
 
-			utc, err := time.LoadLocation("UTC")
-			require.NoError(t, err)
-
 			if tt.WantErr {
 				assert.Equal(t, http.StatusForbidden, w.Code)
 			} else {
 				require.Equal(t, http.StatusOK, w.Code)
-				var res rdb.WorkEntry
+				var res handler.WorkEntryResponse
 				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
 				require.Equal(t, p.EmployeeID, res.EmployeeID)
 				require.Equal(t, p.WorkplaceID, res.WorkplaceID)
-				require.Equal(t, p.Date, res.Date.Time.Format("2006-01-02T15:04:05.000+09:00"))
+				if tt.WantDate != "" {
+					require.Equal(t, tt.WantDate, util.FormatDate(res.Date))
+				} else {
+					require.Equal(t, "2006-01-02", util.FormatDate(res.Date))
+				}
 				if res.Hours.Valid {
 					require.Equal(t, p.Hours, int(res.Hours.Int16))
 				} else if res.Attendance.Valid {
 					require.Equal(t, p.Attendance, res.Attendance.Bool)
 				} else {
-					require.Equal(t, p.StartTime, time.UnixMicro(res.StartTime.Microseconds).In(utc).Format("2006-01-02T15:04:05.000Z"))
-					require.Equal(t, p.EndTime, time.UnixMicro(res.EndTime.Microseconds).In(utc).Format("2006-01-02T15:04:05.000Z"))
+					require.Equal(t, tt.WantStartTime, res.StartTime)
+					require.Equal(t, tt.WantEndTime, res.EndTime)
 				}
-				if res.Comment.Valid {
-					require.Equal(t, p.Comment, res.Comment.String)
+				// the comment is stored
+				stored, err := rdb.New(dbConn).GetWorkEntry(c, res.ID)
+				require.NoError(t, err)
+				for _, comment := range []pgtype.Text{res.Comment, stored.Comment} {
+					assert.Equal(t, !tt.NoComment, comment.Valid)
+					assert.Equal(t, p.Comment, comment.String)
 				}
 
 				t.Cleanup(func() {
@@ -446,8 +606,98 @@ func TestPostWorkEntry(t *testing.T) {
 	}
 }
 
+func TestPostWorkEntryConflict(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	dbConn := infra.ConnectDB(c)
+
+	office := test.CreateOffice(t, c, dbConn, nil)
+	workplace := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+		v.WorkType = rdb.WorkTypeTime
+	})
+	employee := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+		v.WorkplaceID = workplace.ID
+	})
+	token, err := util.GenerateToken(util.UserClaims{Role: string(rdb.UserTypeAdmin), OfficeID: uint64(office.ID)})
+	require.NoError(t, err)
+
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		req, err := http.NewRequest(method, path, bytes.NewBuffer(b))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	post := func(start, end string) *httptest.ResponseRecorder {
+		w := do("POST", ui.WorkEntryPath, handler.PostWorkEntryParams{
+			EmployeeID:  employee.ID,
+			WorkplaceID: workplace.ID,
+			Date:        "2024-04-01",
+			StartTime:   start,
+			EndTime:     end,
+		})
+		if w.Code == http.StatusOK {
+			var res handler.WorkEntryResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			t.Cleanup(func() {
+				require.NoError(t, rdb.New(dbConn).TestDeleteWorkEntry(c, res.ID))
+			})
+		}
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, post("09:00", "12:00").Code)
+	// one entry per day by default
+	assert.Equal(t, http.StatusConflict, post("13:00", "17:00").Code)
+
+	w = do("PATCH", ui.WorkplacePath+fmt.Sprintf("%d/", workplace.ID), map[string]any{"allow_multiple_entries": true})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.Equal(t, http.StatusOK, post("13:00", "17:00").Code)
+	w = post("11:00", "14:00")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "work entry overlaps another entry")
+
+	// recorded before the check
+	clock := func(h int) pgtype.Time {
+		return util.NewClock(time.Date(2000, 1, 1, h, 0, 0, 0, time.UTC))
+	}
+	first := test.CreateWorkEntries(t, c, dbConn, func(v *rdb.WorkEntry) {
+		v.EmployeeID = employee.ID
+		v.WorkplaceID = workplace.ID
+		v.Date = util.NewDate(time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC))
+		v.Hours = pgtype.Int2{}
+		v.StartTime = clock(9)
+		v.EndTime = clock(17)
+	})
+	second := test.CreateWorkEntries(t, c, dbConn, func(v *rdb.WorkEntry) {
+		v.EmployeeID = employee.ID
+		v.WorkplaceID = workplace.ID
+		v.Date = first.Date
+		v.Hours = pgtype.Int2{}
+		v.StartTime = clock(16)
+		v.EndTime = clock(20)
+	})
+
+	w = do("GET", ui.WorkEntryPath+fmt.Sprintf("conflicts/?workplace_id=%d&from=2024-04-01&to=2024-04-30", workplace.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var conflicts []handler.WorkEntryConflict
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &conflicts))
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, conflict.KindOverlap, conflicts[0].Kind)
+	assert.Equal(t, second.ID, conflicts[0].WorkEntryID)
+	assert.Equal(t, first.ID, conflicts[0].OtherID)
+	assert.Equal(t, employee.Name, conflicts[0].EmployeeName)
+}
+
 func TestDeleteWorkEntry(t *testing.T) {
-	router := ui.SetupRouter()
+	router := ui.SetupRouter(test.NewApp(t))
 
 	tests := map[string]struct {
 		Role        rdb.UserType
@@ -527,3 +777,186 @@ func TestDeleteWorkEntry(t *testing.T) {
 		})
 	}
 }
+
+func TestRestoreWorkEntry(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	tests := map[string]struct {
+		Role           rdb.UserType
+		OtherOffice    bool
+		DeleteEmployee bool
+		WantCode       int
+	}{
+		"admin": {
+			Role:     rdb.UserTypeAdmin,
+			WantCode: http.StatusOK,
+		},
+		"admin-other-office": {
+			Role:        rdb.UserTypeAdmin,
+			OtherOffice: true,
+			WantCode:    http.StatusForbidden,
+		},
+		"admin-employee-deleted": {
+			Role:           rdb.UserTypeAdmin,
+			DeleteEmployee: true,
+			WantCode:       http.StatusConflict,
+		},
+		"employee": {
+			Role:     rdb.UserTypeEmployee,
+			WantCode: http.StatusForbidden,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			dbConn := infra.ConnectDB(c)
+			repo := rdb.New(dbConn)
+
+			office := test.CreateOffice(t, c, dbConn, nil)
+			workplace := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+				v.OfficeID = office.ID
+			})
+			employee := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+				v.WorkplaceID = workplace.ID
+			})
+			_, token, _ := test.CreateUserWithToken(t, c, dbConn, func(v *rdb.User) {
+				if tt.OtherOffice {
+					v.OfficeID = test.CreateOffice(t, c, dbConn, nil).ID
+				} else {
+					v.OfficeID = office.ID
+				}
+				v.Role = tt.Role
+				if tt.Role == rdb.UserTypeEmployee {
+					v.EmployeeID = pgtype.Int8{Int64: employee.ID, Valid: true}
+				}
+			})
+			entry := test.CreateWorkEntries(t, c, dbConn, func(v *rdb.WorkEntry) {
+				v.EmployeeID = employee.ID
+				v.WorkplaceID = workplace.ID
+			})
+			require.NoError(t, repo.SoftDeleteWorkEntry(c, entry.ID))
+			if tt.DeleteEmployee {
+				require.NoError(t, repo.SoftDeleteEmployee(c, employee.ID))
+			}
+
+			var err error
+			c.Request, err = http.NewRequest("POST", fmt.Sprintf("%s%d/restore/", ui.WorkEntryPath, entry.ID), nil)
+			require.NoError(t, err)
+			c.Request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			router.ServeHTTP(w, c.Request)
+
+			assert.Equal(t, tt.WantCode, w.Code)
+			if tt.WantCode != http.StatusOK {
+				assert.NotEmpty(t, test.GetDeletedAtWorkEntry(t, c, dbConn, entry.ID))
+				return
+			}
+
+			var res handler.WorkEntryResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			assert.Equal(t, entry.ID, res.ID)
+			deletedAt, err := repo.TestGetDeletedAtWorkEntry(c, entry.ID)
+			require.NoError(t, err)
+			assert.False(t, deletedAt.Valid)
+		})
+	}
+}
+
+func TestWorkEntryWorkplaceCheck(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	dbConn := infra.ConnectDB(c)
+
+	office := test.CreateOffice(t, c, dbConn, nil)
+	workplace := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+	})
+	other := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+		v.OfficeID = office.ID
+	})
+	employee := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+		v.WorkplaceID = workplace.ID
+	})
+
+	_, err := rdb.New(dbConn).TestCreateWorkEntry(c, rdb.TestCreateWorkEntryParams{
+		EmployeeID:  employee.ID,
+		WorkplaceID: other.ID,
+		Date:        util.NewDate(time.Now()),
+		Hours:       pgtype.Int2{Int16: 8, Valid: true},
+	})
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	assert.Equal(t, "23514", pgErr.Code)
+}
+
+// BenchmarkGetWorkEntriesByOffice lists the entries of one office among others with and without the indexes.
+// The data is seeded in a transaction that is rolled back, and dropping the indexes locks the tables until then.
+func BenchmarkGetWorkEntriesByOffice(b *testing.B) {
+	ctx := context.Background()
+	dbConn := infra.ConnectDB(ctx)
+	defer dbConn.Close()
+
+	tx, err := dbConn.Begin(ctx)
+	require.NoError(b, err)
+	defer tx.Rollback(ctx)
+
+	officeID := seedWorkEntries(b, ctx, tx, 20, 5, 10, 100)
+	from := time.Now().AddDate(0, 0, -30).Format(util.DateLayout)
+
+	run := func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", ui.WorkEntryPath+"?from="+from, nil)
+			c.Set("db", tx)
+			c.Set("user", &util.UserClaims{OfficeID: uint64(officeID), Role: string(rdb.UserTypeAdmin)})
+			handler.GetWorkEntriesByOffice(c)
+			if w.Code != http.StatusOK {
+				b.Fatalf("status %d: %s", w.Code, w.Body.String())
+			}
+		}
+	}
+
+	b.Run("indexed", run)
+	b.Run("unindexed", func(b *testing.B) {
+		sp, err := tx.Begin(ctx)
+		require.NoError(b, err)
+		defer sp.Rollback(ctx)
+		_, err = sp.Exec(ctx, `drop index idx_workplaces_office_id, idx_employees_workplace_id,
+			idx_work_entries_workplace_id_date, idx_work_entries_employee_id_date`)
+		require.NoError(b, err)
+		b.ResetTimer()
+		run(b)
+	})
+}
+
+// seedWorkEntries inserts offices with workplaces, employees and a daily entry of each employee for days,
+// a tenth of them deleted, and returns the first office.
+func seedWorkEntries(b *testing.B, ctx context.Context, tx pgx.Tx, offices, workplaces, employees, days int) int64 {
+	b.Helper()
+
+	rows, err := tx.Query(ctx, `insert into offices (name) select 'bench ' || i from generate_series(1, $1::int) i returning id`, offices)
+	require.NoError(b, err)
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	require.NoError(b, err)
+
+	_, err = tx.Exec(ctx, `insert into workplaces (name, office_id, work_type)
+		select 'workplace ' || i, offices.id, 'hours' from offices, generate_series(1, $2::int) i
+		where offices.id = any($1)`, ids, workplaces)
+	require.NoError(b, err)
+	_, err = tx.Exec(ctx, `insert into employees (name, workplace_id)
+		select 'employee ' || i, workplaces.id from workplaces, generate_series(1, $2::int) i
+		where workplaces.office_id = any($1)`, ids, employees)
+	require.NoError(b, err)
+	_, err = tx.Exec(ctx, `insert into work_entries (employee_id, workplace_id, date, hours, deleted_at)
+		select employees.id, employees.workplace_id, current_date - d, 8, case when d % 10 = 0 then now() end
+		from employees join workplaces on employees.workplace_id = workplaces.id, generate_series(0, $2::int - 1) d
+		where workplaces.office_id = any($1)`, ids, days)
+	require.NoError(b, err)
+	_, err = tx.Exec(ctx, "analyze offices, workplaces, employees, work_entries")
+	require.NoError(b, err)
+
+	return ids[0]
+}
@@ -5,29 +5,46 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/mio256/wplus-server/pkg/infra/rdb"
 	"github.com/mio256/wplus-server/pkg/util"
 	"github.com/taxio/errors"
 )
 
 func GetWorkplaces(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
+	repo := queries(c)
 
 	user := c.MustGet("user").(*util.UserClaims)
 
-	workplaces, err := repo.GetWorkplaces(c, int64(user.OfficeID))
+	limit, offset, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+
+	workplaces, err := repo.ListWorkplaces(c, rdb.ListWorkplacesParams{
+		OfficeID:   int64(user.OfficeID),
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	total, err := repo.CountWorkplaces(c, int64(user.OfficeID))
 	if err != nil {
 		c.Error(errors.Wrap(err))
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, workplaces)
+	c.JSON(http.StatusOK, NewListResponse(workplaces, limit, offset, total))
 }
 
 func GetWorkplace(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
+	repo := queries(c)
 
 	user := c.MustGet("user").(*util.UserClaims)
 
@@ -54,8 +71,7 @@ func GetWorkplace(c *gin.Context) {
 }
 
 func PostWorkplace(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
+	repo := queries(c)
 
 	user := c.MustGet("user").(*util.UserClaims)
 
@@ -84,16 +100,25 @@ func PostWorkplace(c *gin.Context) {
 		c.Error(errors.Wrap(err))
 		return
 	}
-
+	if err := writeAudit(c, repo, auditEvent{
+		Action:     AuditActionCreate,
+		Resource:   AuditResourceWorkplace,
+		ResourceID: workplace.ID,
+		After:      workplace,
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
 	c.IndentedJSON(http.StatusCreated, workplace)
 }
 
-// DeleteWorkplace NOTE: DeleteWorkplaceによって削除されるWorkplaceに属するEmployeeをChangeEmployeeWorkplaceを使用して移動させるようにフロントエンドで促す
-func DeleteWorkplace(c *gin.Context) {
-	dbConn := c.MustGet("db").(rdb.DBTX)
-	repo := rdb.New(dbConn)
+// PatchWorkplace updates the profile of a workplace. Fields that are not in the request body are left unchanged,
+// and fields given as null are cleared. The work type cannot be changed because it decides the shape of the entries.
+func PatchWorkplace(c *gin.Context) {
+	repo := queries(c)
 
 	user := c.MustGet("user").(*util.UserClaims)
+
 	if user.Role != "admin" {
 		c.JSON(http.StatusForbidden, gin.H{
 			"message": "you are not admin",
@@ -120,10 +145,331 @@ func DeleteWorkplace(c *gin.Context) {
 		return
 	}
 
+	input := rdb.UpdateWorkplaceProfileParams{
+		ID:                   workplace.ID,
+		Name:                 workplace.Name,
+		Address:              workplace.Address,
+		DefaultStartTime:     workplace.DefaultStartTime,
+		DefaultEndTime:       workplace.DefaultEndTime,
+		AllowMultipleEntries: workplace.AllowMultipleEntries,
+	}
+	if err := c.BindJSON(&input); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	input.ID = workplace.ID
+	if input.Name == "" || input.DefaultStartTime.Valid != input.DefaultEndTime.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid input",
+		})
+		return
+	}
+
+	updated, err := repo.UpdateWorkplaceProfile(c, input)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if err := writeAudit(c, repo, auditEvent{
+		Action:     AuditActionUpdate,
+		Resource:   AuditResourceWorkplace,
+		ResourceID: updated.ID,
+		Before:     workplace,
+		After:      updated,
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	c.IndentedJSON(http.StatusOK, updated)
+}
+
+// DeleteWorkplaceResponse reports what happened to the active employees of a deleted workplace.
+type DeleteWorkplaceResponse struct {
+	Workplace rdb.Workplace `json:"workplace"`
+	// MovedTo is the workplace the employees were moved to, if any.
+	MovedTo            *int64         `json:"moved_to"`
+	MovedEmployees     []rdb.Employee `json:"moved_employees"`
+	DeletedEmployees   []rdb.Employee `json:"deleted_employees"`
+	DeletedWorkEntries int64          `json:"deleted_work_entries"`
+}
+
+// DeleteWorkplace refuses to delete a workplace that still has active employees.
+// Pass move_to to move them to another workplace, or cascade=true to delete them with their work entries.
+func DeleteWorkplace(c *gin.Context) {
+	user := c.MustGet("user").(*util.UserClaims)
+	if user.Role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin",
+		})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	var moveTo *int64
+	if s := c.Query("move_to"); s != "" {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || v == id {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid move_to",
+			})
+			return
+		}
+		moveTo = &v
+	}
+	cascade := c.Query("cascade") == "true"
+	if moveTo != nil && cascade {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "move_to and cascade cannot be used together",
+		})
+		return
+	}
+
+	repo := queries(c)
+
+	// locking the workplace keeps new employees from being added to it until the transaction ends
+	workplace, err := repo.GetWorkplaceForUpdate(c, id)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	if workplace.OfficeID != int64(user.OfficeID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "your office is different",
+		})
+		return
+	}
+
+	res := DeleteWorkplaceResponse{
+		MovedTo:          moveTo,
+		MovedEmployees:   []rdb.Employee{},
+		DeletedEmployees: []rdb.Employee{},
+	}
+	switch {
+	case moveTo != nil:
+		target, err := repo.GetWorkplace(c, *moveTo)
+		if err != nil || target.OfficeID != workplace.OfficeID {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid move_to",
+			})
+			return
+		}
+		if ok := moveEmployeeAssignments(c, repo, workplace, target); !ok {
+			return
+		}
+		moved, err := repo.MoveEmployeesToWorkplace(c, rdb.MoveEmployeesToWorkplaceParams{
+			ToWorkplaceID:   target.ID,
+			FromWorkplaceID: workplace.ID,
+		})
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		res.MovedEmployees = append(res.MovedEmployees, moved...)
+	case cascade:
+		deleted, err := repo.SoftDeleteEmployeesByWorkplace(c, workplace.ID)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		employeeIDs := make([]int64, 0, len(deleted))
+		for _, e := range deleted {
+			employeeIDs = append(employeeIDs, e.ID)
+		}
+		res.DeletedWorkEntries, err = repo.SoftDeleteWorkEntriesByEmployees(c, employeeIDs)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		res.DeletedEmployees = append(res.DeletedEmployees, deleted...)
+	default:
+		employees, err := repo.GetEmployees(c, workplace.ID)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return
+		}
+		if len(employees) > 0 {
+			c.JSON(http.StatusConflict, gin.H{
+				"message":   "workplace has employees",
+				"employees": employees,
+			})
+			return
+		}
+	}
+
 	if err := repo.SoftDeleteWorkplace(c, id); err != nil {
 		c.Error(errors.Wrap(err))
 		return
 	}
+	res.Workplace, err = repo.GetDeletedWorkplace(c, id)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if err := writeAudit(c, repo, auditEvent{
+		Action:     AuditActionDelete,
+		Resource:   AuditResourceWorkplace,
+		ResourceID: workplace.ID,
+		Before:     workplace,
+		After:      res,
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
 
-	c.Status(http.StatusNoContent)
+	c.IndentedJSON(http.StatusOK, res)
+}
+
+// moveEmployeeAssignments transfers the employees of a locked workplace to the target from today, so that their
+// history agrees with the workplace they are moved to. It responds and returns false when it fails.
+func moveEmployeeAssignments(c *gin.Context, repo *rdb.Queries, workplace, target rdb.Workplace) bool {
+	loc, err := officeLocation(c, repo, workplace.OfficeID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return false
+	}
+	today := util.Today(loc)
+
+	employees, err := repo.GetEmployees(c, workplace.ID)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return false
+	}
+	var scheduled []rdb.Employee
+	for _, e := range employees {
+		// locking the employee serializes the move with their transfers
+		employee, err := repo.GetEmployeeForUpdate(c, e.ID)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return false
+		}
+		latest, err := latestAssignment(c, repo, employee)
+		if err != nil {
+			c.Error(errors.Wrap(err))
+			return false
+		}
+		if latest.EffectiveFrom.Valid && !latest.EffectiveFrom.Time.Before(today.Time) {
+			scheduled = append(scheduled, employee)
+			continue
+		}
+		if _, err := transferEmployee(c, repo, employee, latest, target.ID, today); err != nil {
+			c.Error(errors.Wrap(err))
+			return false
+		}
+	}
+	if len(scheduled) > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"message":   "employees have a transfer scheduled from today or later",
+			"employees": scheduled,
+		})
+		return false
+	}
+	return true
+}
+
+func GetDeletedWorkplaces(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	if user.Role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin",
+		})
+		return
+	}
+
+	since, err := deletedSince(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+
+	limit, offset, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid query",
+		})
+		return
+	}
+
+	workplaces, err := repo.GetDeletedWorkplaces(c, rdb.GetDeletedWorkplacesParams{
+		OfficeID:     int64(user.OfficeID),
+		DeletedSince: since,
+		PageLimit:    limit,
+		PageOffset:   offset,
+	})
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	total, err := repo.CountDeletedWorkplaces(c, rdb.CountDeletedWorkplacesParams{
+		OfficeID:     int64(user.OfficeID),
+		DeletedSince: since,
+	})
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewListResponse(workplaces, limit, offset, total))
+}
+
+func RestoreWorkplace(c *gin.Context) {
+	repo := queries(c)
+
+	user := c.MustGet("user").(*util.UserClaims)
+	if user.Role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "you are not admin",
+		})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	deleted, err := repo.GetDeletedWorkplace(c, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "deleted workplace not found",
+		})
+		return
+	} else if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+
+	if deleted.OfficeID != int64(user.OfficeID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "your office is different",
+		})
+		return
+	}
+
+	workplace, err := repo.RestoreWorkplace(c, id)
+	if err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	if err := writeAudit(c, repo, auditEvent{
+		Action:     AuditActionRestore,
+		Resource:   AuditResourceWorkplace,
+		ResourceID: workplace.ID,
+		After:      workplace,
+	}); err != nil {
+		c.Error(errors.Wrap(err))
+		return
+	}
+	c.IndentedJSON(http.StatusOK, workplace)
 }
@@ -7,21 +7,25 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-faker/faker/v4"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/handler"
 	"github.com/mio256/wplus-server/pkg/infra"
 	"github.com/mio256/wplus-server/pkg/infra/rdb"
 	"github.com/mio256/wplus-server/pkg/test"
 	"github.com/mio256/wplus-server/pkg/ui"
+	"github.com/mio256/wplus-server/pkg/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestGetWorkplaces(t *testing.T) {
-	router := ui.SetupRouter()
+	router := ui.SetupRouter(test.NewApp(t))
 	ctx := context.Background()
 	dbConn := infra.ConnectDB(ctx)
 
@@ -59,17 +63,83 @@ func TestGetWorkplaces(t *testing.T) {
 			router.ServeHTTP(w, c.Request)
 
 			assert.Equal(t, http.StatusOK, w.Code)
-			var res []rdb.Workplace
+			var res handler.ListResponse[rdb.Workplace]
 			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
-			assert.NotEmpty(t, res)
-			assert.Contains(t, res, *tt.Ours)
-			assert.NotContains(t, res, *tt.Others)
+			assert.NotEmpty(t, res.Items)
+			assert.Contains(t, res.Items, *tt.Ours)
+			assert.NotContains(t, res.Items, *tt.Others)
+		})
+	}
+}
+
+func TestListWorkplacesPagination(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+	ctx := context.Background()
+	dbConn := infra.ConnectDB(ctx)
+
+	first := test.CreateWorkplace(t, ctx, dbConn, nil)
+	ids := []int64{first.ID}
+	for i := 0; i < 2; i++ {
+		wp := test.CreateWorkplace(t, ctx, dbConn, func(v *rdb.Workplace) {
+			v.OfficeID = first.OfficeID
+		})
+		ids = append(ids, wp.ID)
+	}
+	_, token, _ := test.CreateUserWithToken(t, ctx, dbConn, func(v *rdb.User) {
+		v.OfficeID = first.OfficeID
+	})
+
+	tests := map[string]struct {
+		Query    string
+		WantCode int
+		WantIDs  []int64
+		WantNext bool
+	}{
+		"first-page": {
+			Query:    "?limit=2",
+			WantCode: http.StatusOK,
+			WantIDs:  ids[:2],
+			WantNext: true,
+		},
+		"second-page": {
+			Query:    "?limit=2&offset=2",
+			WantCode: http.StatusOK,
+			WantIDs:  ids[2:],
+		},
+		"invalid-limit": {
+			Query:    "?limit=many",
+			WantCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", ui.WorkplacePath+tt.Query, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.WantCode, w.Code)
+			if tt.WantCode != http.StatusOK {
+				return
+			}
+			var res handler.ListResponse[rdb.Workplace]
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			var got []int64
+			for _, wp := range res.Items {
+				got = append(got, wp.ID)
+			}
+			assert.Equal(t, tt.WantIDs, got)
+			assert.Equal(t, int64(3), res.Pagination.Total)
+			assert.Equal(t, tt.WantNext, res.Pagination.HasNext)
 		})
 	}
 }
 
 func TestGetWorkplace(t *testing.T) {
-	router := ui.SetupRouter()
+	router := ui.SetupRouter(test.NewApp(t))
 	ctx := context.Background()
 	dbConn := infra.ConnectDB(ctx)
 
@@ -132,7 +202,7 @@ func TestGetWorkplace(t *testing.T) {
 }
 
 func TestPostWorkplace(t *testing.T) {
-	router := ui.SetupRouter()
+	router := ui.SetupRouter(test.NewApp(t))
 
 	tests := map[string]struct {
 		Role        rdb.UserType
@@ -217,30 +287,133 @@ func TestPostWorkplace(t *testing.T) {
 	}
 }
 
-func TestDeleteWorkplace(t *testing.T) {
-	router := ui.SetupRouter()
+func TestPatchWorkplace(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
 
 	tests := map[string]struct {
 		Role        rdb.UserType
 		OtherOffice bool
-		WantErr     bool
+		Body        string
+		WantCode    int
 	}{
 		"admin": {
-			Role:    rdb.UserTypeAdmin,
-			WantErr: false,
+			Role:     rdb.UserTypeAdmin,
+			Body:     `{"name": "renamed", "address": "東京都千代田区", "default_start_time": "09:00", "default_end_time": "17:30"}`,
+			WantCode: http.StatusOK,
+		},
+		"admin-half-shift": {
+			Role:     rdb.UserTypeAdmin,
+			Body:     `{"default_start_time": "09:00"}`,
+			WantCode: http.StatusBadRequest,
+		},
+		"admin-invalid-time": {
+			Role:     rdb.UserTypeAdmin,
+			Body:     `{"default_start_time": "9 o'clock", "default_end_time": "17:30"}`,
+			WantCode: http.StatusBadRequest,
 		},
 		"admin-other-office": {
 			Role:        rdb.UserTypeAdmin,
 			OtherOffice: true,
-			WantErr:     true,
+			Body:        `{"name": "renamed"}`,
+			WantCode:    http.StatusForbidden,
 		},
 		"manager": {
-			Role:    rdb.UserTypeManager,
-			WantErr: true,
+			Role:     rdb.UserTypeManager,
+			Body:     `{"name": "renamed"}`,
+			WantCode: http.StatusForbidden,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			dbConn := infra.ConnectDB(c)
+
+			office := test.CreateOffice(t, c, dbConn, nil)
+			workplace := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+				v.OfficeID = office.ID
+			})
+			_, token, _ := test.CreateUserWithToken(t, c, dbConn, func(v *rdb.User) {
+				if tt.OtherOffice {
+					v.OfficeID = test.CreateOffice(t, c, dbConn, nil).ID
+				} else {
+					v.OfficeID = office.ID
+				}
+				v.Role = tt.Role
+				if tt.Role == rdb.UserTypeManager {
+					v.EmployeeID = pgtype.Int8{Int64: test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
+						v.WorkplaceID = workplace.ID
+					}).ID, Valid: true}
+				}
+			})
+
+			var err error
+			c.Request, err = http.NewRequest("PATCH", fmt.Sprintf("%s%d/", ui.WorkplacePath, workplace.ID), bytes.NewBufferString(tt.Body))
+			require.NoError(t, err)
+			c.Request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			router.ServeHTTP(w, c.Request)
+
+			require.Equal(t, tt.WantCode, w.Code)
+			if tt.WantCode == http.StatusOK {
+				var res rdb.Workplace
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+				assert.Equal(t, workplace.ID, res.ID)
+				assert.Equal(t, workplace.WorkType, res.WorkType)
+				assert.Equal(t, "renamed", res.Name)
+				assert.Equal(t, "東京都千代田区", res.Address.String)
+				assert.Equal(t, "09:00:00", util.FormatClock(res.DefaultStartTime.Time))
+				assert.Equal(t, "17:30:00", util.FormatClock(res.DefaultEndTime.Time))
+			}
+		})
+	}
+}
+
+func TestDeleteWorkplace(t *testing.T) {
+	router := ui.SetupRouter(test.NewApp(t))
+
+	tests := map[string]struct {
+		Role        rdb.UserType
+		OtherOffice bool
+		Query       string
+		MoveTo      bool
+		WantCode    int
+	}{
+		"admin-with-employees": {
+			Role:     rdb.UserTypeAdmin,
+			WantCode: http.StatusConflict,
+		},
+		"admin-move": {
+			Role:     rdb.UserTypeAdmin,
+			MoveTo:   true,
+			WantCode: http.StatusOK,
+		},
+		"admin-move-to-self": {
+			Role:     rdb.UserTypeAdmin,
+			Query:    "move_to=self",
+			WantCode: http.StatusBadRequest,
+		},
+		"admin-cascade": {
+			Role:     rdb.UserTypeAdmin,
+			Query:    "cascade=true",
+			WantCode: http.StatusOK,
+		},
+		"admin-other-office": {
+			Role:        rdb.UserTypeAdmin,
+			OtherOffice: true,
+			Query:       "cascade=true",
+			WantCode:    http.StatusForbidden,
+		},
+		"manager": {
+			Role:     rdb.UserTypeManager,
+			Query:    "cascade=true",
+			WantCode: http.StatusForbidden,
 		},
 		"employee": {
-			Role:    rdb.UserTypeEmployee,
-			WantErr: true,
+			Role:     rdb.UserTypeEmployee,
+			Query:    "cascade=true",
+			WantCode: http.StatusForbidden,
 		},
 	}
 
@@ -255,9 +428,16 @@ func TestDeleteWorkplace(t *testing.T) {
 			workplace := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
 				v.OfficeID = office.ID
 			})
+			target := test.CreateWorkplace(t, c, dbConn, func(v *rdb.Workplace) {
+				v.OfficeID = office.ID
+			})
 			employee := test.CreateEmployee(t, c, dbConn, func(v *rdb.Employee) {
 				v.WorkplaceID = workplace.ID
 			})
+			entry := test.CreateWorkEntries(t, c, dbConn, func(v *rdb.WorkEntry) {
+				v.EmployeeID = employee.ID
+				v.WorkplaceID = workplace.ID
+			})
 			_, token, _ := test.CreateUserWithToken(t, c, dbConn, func(v *rdb.User) {
 				if tt.OtherOffice {
 					v.OfficeID = test.CreateOffice(t, c, dbConn, nil).ID
@@ -270,17 +450,57 @@ func TestDeleteWorkplace(t *testing.T) {
 				}
 			})
 
+			query := strings.ReplaceAll(tt.Query, "self", fmt.Sprint(workplace.ID))
+			if tt.MoveTo {
+				query = fmt.Sprintf("move_to=%d", target.ID)
+			}
+
 			var err error
-			c.Request, err = http.NewRequest("DELETE", fmt.Sprintf("%s%d/", ui.WorkplacePath, workplace.ID), nil)
+			c.Request, err = http.NewRequest("DELETE", fmt.Sprintf("%s%d/?%s", ui.WorkplacePath, workplace.ID, query), nil)
 			require.NoError(t, err)
 			c.Request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 			router.ServeHTTP(w, c.Request)
 
-			if tt.WantErr {
-				assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.Equal(t, tt.WantCode, w.Code)
+			if tt.WantCode != http.StatusOK {
+				_, err := rdb.New(dbConn).GetWorkplace(c, workplace.ID)
+				assert.NoError(t, err)
+				_, err = rdb.New(dbConn).GetEmployee(c, employee.ID)
+				assert.NoError(t, err)
+				return
+			}
+
+			var res handler.DeleteWorkplaceResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			assert.NotEmpty(t, test.GetDeletedAtWorkplace(t, c, dbConn, workplace.ID))
+			if tt.MoveTo {
+				require.Len(t, res.MovedEmployees, 1)
+				assert.Equal(t, employee.ID, res.MovedEmployees[0].ID)
+				assert.Equal(t, target.ID, res.MovedEmployees[0].WorkplaceID)
+				assert.Empty(t, res.DeletedEmployees)
+				moved, err := rdb.New(dbConn).GetEmployee(c, employee.ID)
+				require.NoError(t, err)
+				assert.Equal(t, target.ID, moved.WorkplaceID)
+
+				// the history of the employee follows the move from today
+				loc, err := util.LoadLocation(office.TimeZone)
+				require.NoError(t, err)
+				today := time.Now().In(loc)
+				assignments, err := rdb.New(dbConn).GetEmployeeAssignments(c, employee.ID)
+				require.NoError(t, err)
+				require.Len(t, assignments, 2)
+				assert.Equal(t, workplace.ID, assignments[0].WorkplaceID)
+				assert.Equal(t, today.AddDate(0, 0, -1).Format(util.DateLayout), util.FormatDate(assignments[0].EffectiveTo))
+				assert.Equal(t, target.ID, assignments[1].WorkplaceID)
+				assert.Equal(t, today.Format(util.DateLayout), util.FormatDate(assignments[1].EffectiveFrom))
+				assert.False(t, assignments[1].EffectiveTo.Valid)
 			} else {
-				assert.Equal(t, http.StatusNoContent, w.Code)
-				assert.NotEmpty(t, test.GetDeletedAtWorkplace(t, c, dbConn, workplace.ID))
+				require.Len(t, res.DeletedEmployees, 1)
+				assert.Equal(t, employee.ID, res.DeletedEmployees[0].ID)
+				assert.Equal(t, int64(1), res.DeletedWorkEntries)
+				assert.Empty(t, res.MovedEmployees)
+				assert.NotEmpty(t, test.GetDeletedAtEmployee(t, c, dbConn, employee.ID))
+				assert.NotEmpty(t, test.GetDeletedAtWorkEntry(t, c, dbConn, entry.ID))
 			}
 		})
 	}
@@ -0,0 +1,179 @@
+// Package holiday calculates the national holidays of Japan (国民の祝日) without any external calendar.
+//
+// The rules follow the Act on National Holidays as amended up to 2021, including the special days of 2019
+// and the holidays moved for the Tokyo Olympics in 2020 and 2021. Dates before 2000 are calculated
+// with the rules of 2000, and the equinox formula is valid until 2099.
+package holiday
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	NameSubstitute = "振替休日"
+	NameCitizens   = "国民の休日"
+)
+
+// Holiday is a day off.
+type Holiday struct {
+	Date time.Time `json:"date"`
+	Name string    `json:"name"`
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// nthWeekday returns the nth weekday of the month, e.g. the second Monday.
+func nthWeekday(year int, month time.Month, n int, weekday time.Weekday) time.Time {
+	first := date(year, month, 1)
+	offset := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, offset+(n-1)*7)
+}
+
+// equinox returns the day of the vernal (base 20.8431) or autumnal (base 23.2488) equinox for 1980 to 2099.
+func equinox(year int, base float64) int {
+	y := float64(year - 1980)
+	return int(math.Floor(base + 0.242194*y - math.Floor(y/4)))
+}
+
+// national returns the holidays named by the law, before substitute and citizens' holidays are added.
+func national(year int) map[time.Time]string {
+	h := map[time.Time]string{}
+	add := func(d time.Time, name string) {
+		h[d] = name
+	}
+
+	add(date(year, time.January, 1), "元日")
+	add(nthWeekday(year, time.January, 2, time.Monday), "成人の日")
+	add(date(year, time.February, 11), "建国記念の日")
+	if year >= 2020 {
+		add(date(year, time.February, 23), "天皇誕生日")
+	} else if year <= 2018 {
+		add(date(year, time.December, 23), "天皇誕生日")
+	}
+	add(date(year, time.March, equinox(year, 20.8431)), "春分の日")
+	if year >= 2007 {
+		add(date(year, time.April, 29), "昭和の日")
+		add(date(year, time.May, 4), "みどりの日")
+	} else {
+		add(date(year, time.April, 29), "みどりの日")
+	}
+	add(date(year, time.May, 3), "憲法記念日")
+	add(date(year, time.May, 5), "こどもの日")
+
+	switch year {
+	case 2020:
+		add(date(year, time.July, 23), "海の日")
+		add(date(year, time.July, 24), "スポーツの日")
+		add(date(year, time.August, 10), "山の日")
+	case 2021:
+		add(date(year, time.July, 22), "海の日")
+		add(date(year, time.July, 23), "スポーツの日")
+		add(date(year, time.August, 8), "山の日")
+	default:
+		if year >= 2003 {
+			add(nthWeekday(year, time.July, 3, time.Monday), "海の日")
+		} else {
+			add(date(year, time.July, 20), "海の日")
+		}
+		if year >= 2016 {
+			add(date(year, time.August, 11), "山の日")
+		}
+		if year >= 2020 {
+			add(nthWeekday(year, time.October, 2, time.Monday), "スポーツの日")
+		} else {
+			add(nthWeekday(year, time.October, 2, time.Monday), "体育の日")
+		}
+	}
+
+	if year >= 2003 {
+		add(nthWeekday(year, time.September, 3, time.Monday), "敬老の日")
+	} else {
+		add(date(year, time.September, 15), "敬老の日")
+	}
+	add(date(year, time.September, equinox(year, 23.2488)), "秋分の日")
+	add(date(year, time.November, 3), "文化の日")
+	add(date(year, time.November, 23), "勤労感謝の日")
+
+	if year == 2019 {
+		add(date(year, time.May, 1), "即位の日")
+		add(date(year, time.October, 22), "即位礼正殿の儀の行われる日")
+	}
+	return h
+}
+
+// Year returns the national holidays of a year in date order.
+func Year(year int) []Holiday {
+	base := national(year)
+	h := make(map[time.Time]string, len(base))
+	named := make([]time.Time, 0, len(base))
+	for d, name := range base {
+		h[d] = name
+		named = append(named, d)
+	}
+
+	for _, d := range named {
+		// a holiday on Sunday moves to the next day that is not a holiday (only the Monday before 2007)
+		if d.Weekday() != time.Sunday {
+			continue
+		}
+		next := d.AddDate(0, 0, 1)
+		if year >= 2007 {
+			for _, ok := h[next]; ok; _, ok = h[next] {
+				next = next.AddDate(0, 0, 1)
+			}
+		} else if _, ok := h[next]; ok {
+			continue
+		}
+		if next.Year() == year {
+			h[next] = NameSubstitute
+		}
+	}
+
+	for _, d := range named {
+		// a day between two holidays is a holiday too (except Sundays before 2007)
+		between := d.AddDate(0, 0, 1)
+		if _, ok := h[between]; ok || (year < 2007 && between.Weekday() == time.Sunday) {
+			continue
+		}
+		if _, ok := base[between.AddDate(0, 0, 1)]; ok {
+			h[between] = NameCitizens
+		}
+	}
+
+	res := make([]Holiday, 0, len(h))
+	for d, name := range h {
+		res = append(res, Holiday{Date: d, Name: name})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Date.Before(res[j].Date) })
+	return res
+}
+
+// Lookup returns the name of the national holiday on the day of d, if it is one.
+func Lookup(d time.Time) (string, bool) {
+	d = date(d.Year(), d.Month(), d.Day())
+	for _, h := range Year(d.Year()) {
+		if h.Date.Equal(d) {
+			return h.Name, true
+		}
+	}
+	return "", false
+}
+
+// Between returns the national holidays from from to to, inclusive.
+func Between(from, to time.Time) []Holiday {
+	from = date(from.Year(), from.Month(), from.Day())
+	to = date(to.Year(), to.Month(), to.Day())
+	var res []Holiday
+	for y := from.Year(); y <= to.Year(); y++ {
+		for _, h := range Year(y) {
+			if !h.Date.Before(from) && !h.Date.After(to) {
+				res = append(res, h)
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,71 @@
+package holiday_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mio256/wplus-server/pkg/holiday"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYear(t *testing.T) {
+	// published by the Cabinet Office
+	tests := map[int][]string{
+		2019: {
+			"01-01", "01-14", "02-11", "03-21", "04-29", "04-30", "05-01", "05-02", "05-03", "05-04", "05-05", "05-06",
+			"07-15", "08-11", "08-12", "09-16", "09-23", "10-14", "10-22", "11-03", "11-04", "11-23",
+		},
+		2020: {
+			"01-01", "01-13", "02-11", "02-23", "02-24", "03-20", "04-29", "05-03", "05-04", "05-05", "05-06",
+			"07-23", "07-24", "08-10", "09-21", "09-22", "11-03", "11-23",
+		},
+		2024: {
+			"01-01", "01-08", "02-11", "02-12", "02-23", "03-20", "04-29", "05-03", "05-04", "05-05", "05-06",
+			"07-15", "08-11", "08-12", "09-16", "09-22", "09-23", "10-14", "11-03", "11-04", "11-23",
+		},
+		2025: {
+			"01-01", "01-13", "02-11", "02-23", "02-24", "03-20", "04-29", "05-03", "05-04", "05-05", "05-06",
+			"07-21", "08-11", "09-15", "09-23", "10-13", "11-03", "11-23", "11-24",
+		},
+		2026: {
+			"01-01", "01-12", "02-11", "02-23", "03-20", "04-29", "05-03", "05-04", "05-05", "05-06",
+			"07-20", "08-11", "09-21", "09-22", "09-23", "10-12", "11-03", "11-23",
+		},
+	}
+
+	for year, want := range tests {
+		year, want := year, want
+		t.Run(time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC).Format("2006"), func(t *testing.T) {
+			var got []string
+			for _, h := range holiday.Year(year) {
+				got = append(got, h.Date.Format("01-02"))
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	name, ok := holiday.Lookup(time.Date(2024, 9, 23, 15, 0, 0, 0, time.Local))
+	require.True(t, ok)
+	assert.Equal(t, holiday.NameSubstitute, name)
+
+	name, ok = holiday.Lookup(time.Date(2026, 9, 22, 0, 0, 0, 0, time.UTC))
+	require.True(t, ok)
+	assert.Equal(t, holiday.NameCitizens, name)
+
+	name, ok = holiday.Lookup(time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC))
+	require.True(t, ok)
+	assert.Equal(t, "春分の日", name)
+
+	_, ok = holiday.Lookup(time.Date(2024, 3, 21, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+}
+
+func TestBetween(t *testing.T) {
+	got := holiday.Between(time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC))
+	require.Len(t, got, 2)
+	assert.Equal(t, "元日", got[0].Name)
+	assert.Equal(t, "成人の日", got[1].Name)
+}
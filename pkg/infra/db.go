@@ -2,22 +2,49 @@ package infra
 
 import (
 	"context"
-	"fmt"
-	"os"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mio256/wplus-server/pkg/config"
 	"github.com/mio256/wplus-server/pkg/infra/rdb"
+	"github.com/mio256/wplus-server/pkg/tracing"
+	"github.com/taxio/errors"
 )
 
-func ConnectDB(ctx context.Context) *pgxpool.Pool {
-	var (
-		dbUser         = os.Getenv("DB_USER")              // e.g. 'my-db-user'
-		dbPwd          = os.Getenv("DB_PASS")              // e.g. 'my-db-password'
-		unixSocketPath = os.Getenv("INSTANCE_UNIX_SOCKET") // e.g. '/cloudsql/project:region:instance'
-		dbName         = os.Getenv("DB_NAME")              // e.g. 'my-database'
-	)
+// newPool creates a pool whose queries are traced.
+func newPool(ctx context.Context, pc *pgxpool.Config) (*pgxpool.Pool, error) {
+	pc.ConnConfig.Tracer = tracing.NewQueryTracer()
+	return pgxpool.NewWithConfig(ctx, pc)
+}
 
-	dbConn, err := pgxpool.New(ctx, fmt.Sprintf("user=%s password=%s database=%s host=%s", dbUser, dbPwd, dbName, unixSocketPath))
+// OpenDB creates a pool and checks that the database answers within timeout.
+func OpenDB(ctx context.Context, pc *pgxpool.Config, timeout time.Duration) (*pgxpool.Pool, error) {
+	dbConn, err := newPool(ctx, pc)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if err := PingDB(ctx, dbConn, timeout); err != nil {
+		dbConn.Close()
+		return nil, errors.Wrap(err)
+	}
+	return dbConn, nil
+}
+
+// ConnectDB creates a pool from the configuration of the environment without connecting;
+// connections are made on first use.
+func ConnectDB(ctx context.Context) *pgxpool.Pool {
+	cfg, err := config.FromEnv()
+	if err != nil {
+		panic(err)
+	}
+	if err := cfg.Database.Validate(); err != nil {
+		panic(err)
+	}
+	pc, err := cfg.Database.PoolConfig()
+	if err != nil {
+		panic(err)
+	}
+	dbConn, err := newPool(ctx, pc)
 	if err != nil {
 		panic(err)
 	}
@@ -25,11 +52,12 @@ func ConnectDB(ctx context.Context) *pgxpool.Pool {
 	return dbConn
 }
 
-func CheckConnectDB(ctx context.Context) {
-	dbConn := ConnectDB(ctx)
-	repo := rdb.New(dbConn)
-	if err := repo.Ping(ctx); err != nil {
-		panic(err)
+// PingDB checks that the database answers within timeout.
+func PingDB(ctx context.Context, db rdb.DBTX, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := rdb.New(db).Ping(ctx); err != nil {
+		return errors.Wrap(err)
 	}
-	defer dbConn.Close()
+	return nil
 }
@@ -7,33 +7,198 @@ package rdb
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countDeletedEmployees = `-- name: CountDeletedEmployees :one
+select count(*)
+from employees
+join workplaces on employees.workplace_id = workplaces.id
+where workplaces.office_id = $1 and employees.deleted_at >= $2
+`
+
+type CountDeletedEmployeesParams struct {
+	OfficeID     int64            `json:"office_id"`
+	DeletedSince pgtype.Timestamp `json:"deleted_since"`
+}
+
+func (q *Queries) CountDeletedEmployees(ctx context.Context, arg CountDeletedEmployeesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countDeletedEmployees, arg.OfficeID, arg.DeletedSince)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countEmployees = `-- name: CountEmployees :one
+select count(*)
+from employees
+join workplaces on employees.workplace_id = workplaces.id
+where workplaces.office_id = $1
+    and employees.deleted_at is null
+    and ($2::bigint is null or employees.workplace_id = $2)
+`
+
+type CountEmployeesParams struct {
+	OfficeID    int64       `json:"office_id"`
+	WorkplaceID pgtype.Int8 `json:"workplace_id"`
+}
+
+func (q *Queries) CountEmployees(ctx context.Context, arg CountEmployeesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countEmployees, arg.OfficeID, arg.WorkplaceID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createEmployee = `-- name: CreateEmployee :one
-insert into employees (name, workplace_id) values ($1, $2) returning id, name, workplace_id, deleted_at, created_at, updated_at
+insert into employees (name, workplace_id, code, name_kana, employment_type, hire_date, leave_date, hourly_wage)
+values ($1, $2, $3, $4, $5, $6, $7, $8)
+returning id, name, workplace_id, code, name_kana, employment_type, hire_date, leave_date, hourly_wage, deleted_at, created_at, updated_at
 `
 
 type CreateEmployeeParams struct {
-	Name        string `json:"name"`
-	WorkplaceID int64  `json:"workplace_id"`
+	Name           string         `json:"name"`
+	WorkplaceID    int64          `json:"workplace_id"`
+	Code           pgtype.Text    `json:"code"`
+	NameKana       pgtype.Text    `json:"name_kana"`
+	EmploymentType EmploymentType `json:"employment_type"`
+	HireDate       pgtype.Date    `json:"hire_date"`
+	LeaveDate      pgtype.Date    `json:"leave_date"`
+	HourlyWage     pgtype.Int4    `json:"hourly_wage"`
 }
 
 func (q *Queries) CreateEmployee(ctx context.Context, arg CreateEmployeeParams) (Employee, error) {
-	row := q.db.QueryRow(ctx, createEmployee, arg.Name, arg.WorkplaceID)
+	row := q.db.QueryRow(ctx, createEmployee,
+		arg.Name,
+		arg.WorkplaceID,
+		arg.Code,
+		arg.NameKana,
+		arg.EmploymentType,
+		arg.HireDate,
+		arg.LeaveDate,
+		arg.HourlyWage,
+	)
 	var i Employee
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.WorkplaceID,
+		&i.Code,
+		&i.NameKana,
+		&i.EmploymentType,
+		&i.HireDate,
+		&i.LeaveDate,
+		&i.HourlyWage,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getDeletedEmployee = `-- name: GetDeletedEmployee :one
+select employees.id, employees.name, employees.workplace_id, employees.code, employees.name_kana, employees.employment_type, employees.hire_date, employees.leave_date, employees.hourly_wage, employees.deleted_at, employees.created_at, employees.updated_at, workplaces.office_id, workplaces.deleted_at as workplace_deleted_at
+from employees
+join workplaces on employees.workplace_id = workplaces.id
+where employees.id = $1 and employees.deleted_at is not null
+`
+
+type GetDeletedEmployeeRow struct {
+	ID                 int64            `json:"id"`
+	Name               string           `json:"name"`
+	WorkplaceID        int64            `json:"workplace_id"`
+	Code               pgtype.Text      `json:"code"`
+	NameKana           pgtype.Text      `json:"name_kana"`
+	EmploymentType     EmploymentType   `json:"employment_type"`
+	HireDate           pgtype.Date      `json:"hire_date"`
+	LeaveDate          pgtype.Date      `json:"leave_date"`
+	HourlyWage         pgtype.Int4      `json:"hourly_wage"`
+	DeletedAt          pgtype.Timestamp `json:"deleted_at"`
+	CreatedAt          pgtype.Timestamp `json:"created_at"`
+	UpdatedAt          pgtype.Timestamp `json:"updated_at"`
+	OfficeID           int64            `json:"office_id"`
+	WorkplaceDeletedAt pgtype.Timestamp `json:"workplace_deleted_at"`
+}
+
+func (q *Queries) GetDeletedEmployee(ctx context.Context, id int64) (GetDeletedEmployeeRow, error) {
+	row := q.db.QueryRow(ctx, getDeletedEmployee, id)
+	var i GetDeletedEmployeeRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.WorkplaceID,
+		&i.Code,
+		&i.NameKana,
+		&i.EmploymentType,
+		&i.HireDate,
+		&i.LeaveDate,
+		&i.HourlyWage,
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OfficeID,
+		&i.WorkplaceDeletedAt,
 	)
 	return i, err
 }
 
+const getDeletedEmployees = `-- name: GetDeletedEmployees :many
+select employees.id, employees.name, employees.workplace_id, employees.code, employees.name_kana, employees.employment_type, employees.hire_date, employees.leave_date, employees.hourly_wage, employees.deleted_at, employees.created_at, employees.updated_at
+from employees
+join workplaces on employees.workplace_id = workplaces.id
+where workplaces.office_id = $1 and employees.deleted_at >= $2
+order by employees.deleted_at desc, employees.id desc
+limit $3 offset $4
+`
+
+type GetDeletedEmployeesParams struct {
+	OfficeID     int64            `json:"office_id"`
+	DeletedSince pgtype.Timestamp `json:"deleted_since"`
+	PageLimit    int32            `json:"page_limit"`
+	PageOffset   int32            `json:"page_offset"`
+}
+
+func (q *Queries) GetDeletedEmployees(ctx context.Context, arg GetDeletedEmployeesParams) ([]Employee, error) {
+	rows, err := q.db.Query(ctx, getDeletedEmployees,
+		arg.OfficeID,
+		arg.DeletedSince,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Employee
+	for rows.Next() {
+		var i Employee
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.WorkplaceID,
+			&i.Code,
+			&i.NameKana,
+			&i.EmploymentType,
+			&i.HireDate,
+			&i.LeaveDate,
+			&i.HourlyWage,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getEmployee = `-- name: GetEmployee :one
-select id, name, workplace_id, deleted_at, created_at, updated_at from employees where id = $1 and deleted_at is null
+select id, name, workplace_id, code, name_kana, employment_type, hire_date, leave_date, hourly_wage, deleted_at, created_at, updated_at from employees where id = $1 and deleted_at is null
 `
 
 func (q *Queries) GetEmployee(ctx context.Context, id int64) (Employee, error) {
@@ -43,6 +208,36 @@ func (q *Queries) GetEmployee(ctx context.Context, id int64) (Employee, error) {
 		&i.ID,
 		&i.Name,
 		&i.WorkplaceID,
+		&i.Code,
+		&i.NameKana,
+		&i.EmploymentType,
+		&i.HireDate,
+		&i.LeaveDate,
+		&i.HourlyWage,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getEmployeeForUpdate = `-- name: GetEmployeeForUpdate :one
+select id, name, workplace_id, code, name_kana, employment_type, hire_date, leave_date, hourly_wage, deleted_at, created_at, updated_at from employees where id = $1 and deleted_at is null for update
+`
+
+func (q *Queries) GetEmployeeForUpdate(ctx context.Context, id int64) (Employee, error) {
+	row := q.db.QueryRow(ctx, getEmployeeForUpdate, id)
+	var i Employee
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.WorkplaceID,
+		&i.Code,
+		&i.NameKana,
+		&i.EmploymentType,
+		&i.HireDate,
+		&i.LeaveDate,
+		&i.HourlyWage,
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -64,7 +259,9 @@ func (q *Queries) GetEmployeeOffice(ctx context.Context, id int64) (int64, error
 }
 
 const getEmployees = `-- name: GetEmployees :many
-select id, name, workplace_id, deleted_at, created_at, updated_at from employees where workplace_id = $1 and deleted_at is null
+select id, name, workplace_id, code, name_kana, employment_type, hire_date, leave_date, hourly_wage, deleted_at, created_at, updated_at from employees
+where workplace_id = $1 and deleted_at is null
+order by coalesce(name_kana, name), id
 `
 
 func (q *Queries) GetEmployees(ctx context.Context, workplaceID int64) ([]Employee, error) {
@@ -80,6 +277,12 @@ func (q *Queries) GetEmployees(ctx context.Context, workplaceID int64) ([]Employ
 			&i.ID,
 			&i.Name,
 			&i.WorkplaceID,
+			&i.Code,
+			&i.NameKana,
+			&i.EmploymentType,
+			&i.HireDate,
+			&i.LeaveDate,
+			&i.HourlyWage,
 			&i.DeletedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
@@ -95,10 +298,11 @@ func (q *Queries) GetEmployees(ctx context.Context, workplaceID int64) ([]Employ
 }
 
 const getEmployeesByOffice = `-- name: GetEmployeesByOffice :many
-select employees.id, employees.name, employees.workplace_id, employees.deleted_at, employees.created_at, employees.updated_at
+select employees.id, employees.name, employees.workplace_id, employees.code, employees.name_kana, employees.employment_type, employees.hire_date, employees.leave_date, employees.hourly_wage, employees.deleted_at, employees.created_at, employees.updated_at
 from employees
 join workplaces on employees.workplace_id = workplaces.id
 where workplaces.office_id = $1 and employees.deleted_at is null
+order by coalesce(employees.name_kana, employees.name), employees.id
 `
 
 func (q *Queries) GetEmployeesByOffice(ctx context.Context, officeID int64) ([]Employee, error) {
@@ -114,6 +318,12 @@ func (q *Queries) GetEmployeesByOffice(ctx context.Context, officeID int64) ([]E
 			&i.ID,
 			&i.Name,
 			&i.WorkplaceID,
+			&i.Code,
+			&i.NameKana,
+			&i.EmploymentType,
+			&i.HireDate,
+			&i.LeaveDate,
+			&i.HourlyWage,
 			&i.DeletedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
@@ -128,6 +338,164 @@ func (q *Queries) GetEmployeesByOffice(ctx context.Context, officeID int64) ([]E
 	return items, nil
 }
 
+const listEmployees = `-- name: ListEmployees :many
+select employees.id, employees.name, employees.workplace_id, employees.code, employees.name_kana, employees.employment_type, employees.hire_date, employees.leave_date, employees.hourly_wage, employees.deleted_at, employees.created_at, employees.updated_at
+from employees
+join workplaces on employees.workplace_id = workplaces.id
+where workplaces.office_id = $1
+    and employees.deleted_at is null
+    and ($2::bigint is null or employees.workplace_id = $2)
+order by coalesce(employees.name_kana, employees.name), employees.id
+limit $3 offset $4
+`
+
+type ListEmployeesParams struct {
+	OfficeID    int64       `json:"office_id"`
+	WorkplaceID pgtype.Int8 `json:"workplace_id"`
+	PageLimit   int32       `json:"page_limit"`
+	PageOffset  int32       `json:"page_offset"`
+}
+
+func (q *Queries) ListEmployees(ctx context.Context, arg ListEmployeesParams) ([]Employee, error) {
+	rows, err := q.db.Query(ctx, listEmployees,
+		arg.OfficeID,
+		arg.WorkplaceID,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Employee
+	for rows.Next() {
+		var i Employee
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.WorkplaceID,
+			&i.Code,
+			&i.NameKana,
+			&i.EmploymentType,
+			&i.HireDate,
+			&i.LeaveDate,
+			&i.HourlyWage,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveEmployeesToWorkplace = `-- name: MoveEmployeesToWorkplace :many
+update employees set workplace_id = $1, updated_at = now()
+where workplace_id = $2 and deleted_at is null
+returning id, name, workplace_id, code, name_kana, employment_type, hire_date, leave_date, hourly_wage, deleted_at, created_at, updated_at
+`
+
+type MoveEmployeesToWorkplaceParams struct {
+	ToWorkplaceID   int64 `json:"to_workplace_id"`
+	FromWorkplaceID int64 `json:"from_workplace_id"`
+}
+
+func (q *Queries) MoveEmployeesToWorkplace(ctx context.Context, arg MoveEmployeesToWorkplaceParams) ([]Employee, error) {
+	rows, err := q.db.Query(ctx, moveEmployeesToWorkplace, arg.ToWorkplaceID, arg.FromWorkplaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Employee
+	for rows.Next() {
+		var i Employee
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.WorkplaceID,
+			&i.Code,
+			&i.NameKana,
+			&i.EmploymentType,
+			&i.HireDate,
+			&i.LeaveDate,
+			&i.HourlyWage,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeEmployees = `-- name: PurgeEmployees :execrows
+delete from employees
+where deleted_at < $1
+    and not exists (select 1 from work_entries where work_entries.employee_id = employees.id)
+    and not exists (select 1 from users where users.employee_id = employees.id)
+`
+
+func (q *Queries) PurgeEmployees(ctx context.Context, deletedBefore pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeEmployees, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeUsersOfDeletedEmployees = `-- name: PurgeUsersOfDeletedEmployees :execrows
+delete from users
+where employee_id in (
+    select employees.id from employees
+    where employees.deleted_at < $1
+        and not exists (select 1 from work_entries where work_entries.employee_id = employees.id)
+)
+`
+
+func (q *Queries) PurgeUsersOfDeletedEmployees(ctx context.Context, deletedBefore pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeUsersOfDeletedEmployees, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreEmployee = `-- name: RestoreEmployee :one
+update employees set deleted_at = null, updated_at = now()
+where id = $1 and deleted_at is not null
+returning id, name, workplace_id, code, name_kana, employment_type, hire_date, leave_date, hourly_wage, deleted_at, created_at, updated_at
+`
+
+func (q *Queries) RestoreEmployee(ctx context.Context, id int64) (Employee, error) {
+	row := q.db.QueryRow(ctx, restoreEmployee, id)
+	var i Employee
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.WorkplaceID,
+		&i.Code,
+		&i.NameKana,
+		&i.EmploymentType,
+		&i.HireDate,
+		&i.LeaveDate,
+		&i.HourlyWage,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const softDeleteEmployee = `-- name: SoftDeleteEmployee :exec
 update employees set deleted_at = now() where id = $1
 `
@@ -137,6 +505,99 @@ func (q *Queries) SoftDeleteEmployee(ctx context.Context, id int64) error {
 	return err
 }
 
+const softDeleteEmployeesByWorkplace = `-- name: SoftDeleteEmployeesByWorkplace :many
+update employees set deleted_at = now()
+where workplace_id = $1 and deleted_at is null
+returning id, name, workplace_id, code, name_kana, employment_type, hire_date, leave_date, hourly_wage, deleted_at, created_at, updated_at
+`
+
+func (q *Queries) SoftDeleteEmployeesByWorkplace(ctx context.Context, workplaceID int64) ([]Employee, error) {
+	rows, err := q.db.Query(ctx, softDeleteEmployeesByWorkplace, workplaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Employee
+	for rows.Next() {
+		var i Employee
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.WorkplaceID,
+			&i.Code,
+			&i.NameKana,
+			&i.EmploymentType,
+			&i.HireDate,
+			&i.LeaveDate,
+			&i.HourlyWage,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateEmployeeProfile = `-- name: UpdateEmployeeProfile :one
+update employees
+set name = $2,
+    code = $3,
+    name_kana = $4,
+    employment_type = $5,
+    hire_date = $6,
+    leave_date = $7,
+    hourly_wage = $8,
+    updated_at = now()
+where id = $1 and deleted_at is null
+returning id, name, workplace_id, code, name_kana, employment_type, hire_date, leave_date, hourly_wage, deleted_at, created_at, updated_at
+`
+
+type UpdateEmployeeProfileParams struct {
+	ID             int64          `json:"id"`
+	Name           string         `json:"name"`
+	Code           pgtype.Text    `json:"code"`
+	NameKana       pgtype.Text    `json:"name_kana"`
+	EmploymentType EmploymentType `json:"employment_type"`
+	HireDate       pgtype.Date    `json:"hire_date"`
+	LeaveDate      pgtype.Date    `json:"leave_date"`
+	HourlyWage     pgtype.Int4    `json:"hourly_wage"`
+}
+
+func (q *Queries) UpdateEmployeeProfile(ctx context.Context, arg UpdateEmployeeProfileParams) (Employee, error) {
+	row := q.db.QueryRow(ctx, updateEmployeeProfile,
+		arg.ID,
+		arg.Name,
+		arg.Code,
+		arg.NameKana,
+		arg.EmploymentType,
+		arg.HireDate,
+		arg.LeaveDate,
+		arg.HourlyWage,
+	)
+	var i Employee
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.WorkplaceID,
+		&i.Code,
+		&i.NameKana,
+		&i.EmploymentType,
+		&i.HireDate,
+		&i.LeaveDate,
+		&i.HourlyWage,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updateEmployeeWorkplace = `-- name: UpdateEmployeeWorkplace :exec
 update employees set workplace_id = $2 where id = $1 and deleted_at is null
 `
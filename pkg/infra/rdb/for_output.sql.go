@@ -11,8 +11,115 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const outputApprovedLeaves = `-- name: OutputApprovedLeaves :many
+select leave_requests.employee_id, leave_requests.date, leave_types.marker, leave_types.paid
+from leave_requests
+    join leave_types on leave_requests.leave_type_id = leave_types.id
+where leave_types.office_id = $1
+    and leave_requests.status = 'approved'
+    and leave_requests.date between $2 and $3
+order by leave_requests.employee_id, leave_requests.date
+`
+
+type OutputApprovedLeavesParams struct {
+	OfficeID int64       `json:"office_id"`
+	MinDate  pgtype.Date `json:"min_date"`
+	MaxDate  pgtype.Date `json:"max_date"`
+}
+
+type OutputApprovedLeavesRow struct {
+	EmployeeID int64       `json:"employee_id"`
+	Date       pgtype.Date `json:"date"`
+	Marker     string      `json:"marker"`
+	Paid       bool        `json:"paid"`
+}
+
+func (q *Queries) OutputApprovedLeaves(ctx context.Context, arg OutputApprovedLeavesParams) ([]OutputApprovedLeavesRow, error) {
+	rows, err := q.db.Query(ctx, outputApprovedLeaves, arg.OfficeID, arg.MinDate, arg.MaxDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutputApprovedLeavesRow
+	for rows.Next() {
+		var i OutputApprovedLeavesRow
+		if err := rows.Scan(
+			&i.EmployeeID,
+			&i.Date,
+			&i.Marker,
+			&i.Paid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const outputEmployeesByWorkplaceAndDate = `-- name: OutputEmployeesByWorkplaceAndDate :many
+select employees.id, employees.name, employees.workplace_id, employees.code, employees.name_kana, employees.employment_type, employees.hire_date, employees.leave_date, employees.hourly_wage, employees.deleted_at, employees.created_at, employees.updated_at
+from employees
+where employees.deleted_at is null
+    and (
+        exists (
+            select 1 from employee_assignments
+            where employee_assignments.employee_id = employees.id
+                and employee_assignments.workplace_id = $1
+                and (employee_assignments.effective_from is null or employee_assignments.effective_from <= $2)
+                and (employee_assignments.effective_to is null or employee_assignments.effective_to >= $3)
+        )
+        or (
+            employees.workplace_id = $1
+            and not exists (select 1 from employee_assignments where employee_assignments.employee_id = employees.id)
+        )
+    )
+order by coalesce(employees.name_kana, employees.name), employees.id
+`
+
+type OutputEmployeesByWorkplaceAndDateParams struct {
+	WorkplaceID int64       `json:"workplace_id"`
+	MaxDate     pgtype.Date `json:"max_date"`
+	MinDate     pgtype.Date `json:"min_date"`
+}
+
+func (q *Queries) OutputEmployeesByWorkplaceAndDate(ctx context.Context, arg OutputEmployeesByWorkplaceAndDateParams) ([]Employee, error) {
+	rows, err := q.db.Query(ctx, outputEmployeesByWorkplaceAndDate, arg.WorkplaceID, arg.MaxDate, arg.MinDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Employee
+	for rows.Next() {
+		var i Employee
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.WorkplaceID,
+			&i.Code,
+			&i.NameKana,
+			&i.EmploymentType,
+			&i.HireDate,
+			&i.LeaveDate,
+			&i.HourlyWage,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const outputWorkEntriesByWorkplaceAndDate = `-- name: OutputWorkEntriesByWorkplaceAndDate :many
-select employees.name as employee_name, work_entries.id, work_entries.employee_id, work_entries.workplace_id, work_entries.date, work_entries.hours, work_entries.start_time, work_entries.end_time, work_entries.attendance, work_entries.comment, work_entries.deleted_at, work_entries.created_at, work_entries.updated_at
+select employees.name as employee_name, work_entries.id, work_entries.employee_id, work_entries.workplace_id, work_entries.date, work_entries.hours, work_entries.start_time, work_entries.end_time, work_entries.attendance, work_entries.comment, work_entries.deleted_with_employee, work_entries.deleted_at, work_entries.created_at, work_entries.updated_at
 from work_entries
     join employees on work_entries.employee_id = employees.id
     join workplaces on work_entries.workplace_id = workplaces.id
@@ -20,7 +127,7 @@ where workplaces.id = $1
     and work_entries.date >= $2
     and work_entries.date <= $3
     and work_entries.deleted_at is null
-order by employee_name
+order by coalesce(employees.name_kana, employees.name), employees.id, work_entries.date
 `
 
 type OutputWorkEntriesByWorkplaceAndDateParams struct {
@@ -30,19 +137,20 @@ type OutputWorkEntriesByWorkplaceAndDateParams struct {
 }
 
 type OutputWorkEntriesByWorkplaceAndDateRow struct {
-	EmployeeName string           `json:"employee_name"`
-	ID           int64            `json:"id"`
-	EmployeeID   int64            `json:"employee_id"`
-	WorkplaceID  int64            `json:"workplace_id"`
-	Date         pgtype.Date      `json:"date"`
-	Hours        pgtype.Int2      `json:"hours"`
-	StartTime    pgtype.Time      `json:"start_time"`
-	EndTime      pgtype.Time      `json:"end_time"`
-	Attendance   pgtype.Bool      `json:"attendance"`
-	Comment      pgtype.Text      `json:"comment"`
-	DeletedAt    pgtype.Timestamp `json:"deleted_at"`
-	CreatedAt    pgtype.Timestamp `json:"created_at"`
-	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+	EmployeeName        string           `json:"employee_name"`
+	ID                  int64            `json:"id"`
+	EmployeeID          int64            `json:"employee_id"`
+	WorkplaceID         int64            `json:"workplace_id"`
+	Date                pgtype.Date      `json:"date"`
+	Hours               pgtype.Int2      `json:"hours"`
+	StartTime           pgtype.Time      `json:"start_time"`
+	EndTime             pgtype.Time      `json:"end_time"`
+	Attendance          pgtype.Bool      `json:"attendance"`
+	Comment             pgtype.Text      `json:"comment"`
+	DeletedWithEmployee bool             `json:"deleted_with_employee"`
+	DeletedAt           pgtype.Timestamp `json:"deleted_at"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `json:"updated_at"`
 }
 
 func (q *Queries) OutputWorkEntriesByWorkplaceAndDate(ctx context.Context, arg OutputWorkEntriesByWorkplaceAndDateParams) ([]OutputWorkEntriesByWorkplaceAndDateRow, error) {
@@ -65,6 +173,7 @@ func (q *Queries) OutputWorkEntriesByWorkplaceAndDate(ctx context.Context, arg O
 			&i.EndTime,
 			&i.Attendance,
 			&i.Comment,
+			&i.DeletedWithEmployee,
 			&i.DeletedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
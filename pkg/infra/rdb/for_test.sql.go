@@ -21,7 +21,7 @@ func (q *Queries) Ping(ctx context.Context) error {
 }
 
 const testCreateEmployee = `-- name: TestCreateEmployee :one
-insert into employees (name, workplace_id) values ($1, $2) returning id, name, workplace_id, deleted_at, created_at, updated_at
+insert into employees (name, workplace_id) values ($1, $2) returning id, name, workplace_id, code, name_kana, employment_type, hire_date, leave_date, hourly_wage, deleted_at, created_at, updated_at
 `
 
 type TestCreateEmployeeParams struct {
@@ -36,6 +36,12 @@ func (q *Queries) TestCreateEmployee(ctx context.Context, arg TestCreateEmployee
 		&i.ID,
 		&i.Name,
 		&i.WorkplaceID,
+		&i.Code,
+		&i.NameKana,
+		&i.EmploymentType,
+		&i.HireDate,
+		&i.LeaveDate,
+		&i.HourlyWage,
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -44,7 +50,7 @@ func (q *Queries) TestCreateEmployee(ctx context.Context, arg TestCreateEmployee
 }
 
 const testCreateOffice = `-- name: TestCreateOffice :one
-insert into offices (name) values ($1) returning id, name, deleted_at, created_at, updated_at
+insert into offices (name) values ($1) returning id, name, time_zone, deleted_at, created_at, updated_at
 `
 
 func (q *Queries) TestCreateOffice(ctx context.Context, name string) (Office, error) {
@@ -53,6 +59,7 @@ func (q *Queries) TestCreateOffice(ctx context.Context, name string) (Office, er
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
+		&i.TimeZone,
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -99,7 +106,7 @@ func (q *Queries) TestCreateUser(ctx context.Context, arg TestCreateUserParams)
 const testCreateWorkEntry = `-- name: TestCreateWorkEntry :one
 insert into work_entries (employee_id, workplace_id, date, hours, start_time, end_time, attendance, comment)
 values ($1, $2, $3, $4, $5, $6, $7, $8)
-returning id, employee_id, workplace_id, date, hours, start_time, end_time, attendance, comment, deleted_at, created_at, updated_at
+returning id, employee_id, workplace_id, date, hours, start_time, end_time, attendance, comment, deleted_with_employee, deleted_at, created_at, updated_at
 `
 
 type TestCreateWorkEntryParams struct {
@@ -135,6 +142,7 @@ func (q *Queries) TestCreateWorkEntry(ctx context.Context, arg TestCreateWorkEnt
 		&i.EndTime,
 		&i.Attendance,
 		&i.Comment,
+		&i.DeletedWithEmployee,
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -145,7 +153,7 @@ func (q *Queries) TestCreateWorkEntry(ctx context.Context, arg TestCreateWorkEnt
 const testCreateWorkplace = `-- name: TestCreateWorkplace :one
 insert into workplaces (name, office_id, work_type)
 values ($1, $2, $3)
-returning id, name, office_id, work_type, deleted_at, created_at, updated_at
+returning id, name, office_id, work_type, address, default_start_time, default_end_time, allow_multiple_entries, deleted_at, created_at, updated_at
 `
 
 type TestCreateWorkplaceParams struct {
@@ -162,6 +170,10 @@ func (q *Queries) TestCreateWorkplace(ctx context.Context, arg TestCreateWorkpla
 		&i.Name,
 		&i.OfficeID,
 		&i.WorkType,
+		&i.Address,
+		&i.DefaultStartTime,
+		&i.DefaultEndTime,
+		&i.AllowMultipleEntries,
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -178,6 +190,15 @@ func (q *Queries) TestDeleteEmployee(ctx context.Context, id int64) error {
 	return err
 }
 
+const testDeleteLeaveTypes = `-- name: TestDeleteLeaveTypes :exec
+delete from leave_types where office_id = $1
+`
+
+func (q *Queries) TestDeleteLeaveTypes(ctx context.Context, officeID int64) error {
+	_, err := q.db.Exec(ctx, testDeleteLeaveTypes, officeID)
+	return err
+}
+
 const testDeleteOffice = `-- name: TestDeleteOffice :exec
 delete from offices where id = $1
 `
@@ -187,12 +208,44 @@ func (q *Queries) TestDeleteOffice(ctx context.Context, id int64) error {
 	return err
 }
 
+const testDeleteOfficeClosures = `-- name: TestDeleteOfficeClosures :exec
+delete from office_closures where office_id = $1
+`
+
+func (q *Queries) TestDeleteOfficeClosures(ctx context.Context, officeID int64) error {
+	_, err := q.db.Exec(ctx, testDeleteOfficeClosures, officeID)
+	return err
+}
+
+const testDeleteOvertimeRules = `-- name: TestDeleteOvertimeRules :exec
+delete from overtime_rules where office_id = $1
+`
+
+func (q *Queries) TestDeleteOvertimeRules(ctx context.Context, officeID int64) error {
+	_, err := q.db.Exec(ctx, testDeleteOvertimeRules, officeID)
+	return err
+}
+
+const testDeleteShiftTemplates = `-- name: TestDeleteShiftTemplates :exec
+delete from shift_templates where workplace_id = $1
+`
+
+func (q *Queries) TestDeleteShiftTemplates(ctx context.Context, workplaceID int64) error {
+	_, err := q.db.Exec(ctx, testDeleteShiftTemplates, workplaceID)
+	return err
+}
+
 const testDeleteUser = `-- name: TestDeleteUser :exec
-delete from users where id = $1
+delete from users where id = $1 and office_id = $2
 `
 
-func (q *Queries) TestDeleteUser(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, testDeleteUser, id)
+type TestDeleteUserParams struct {
+	ID       int64 `json:"id"`
+	OfficeID int64 `json:"office_id"`
+}
+
+func (q *Queries) TestDeleteUser(ctx context.Context, arg TestDeleteUserParams) error {
+	_, err := q.db.Exec(ctx, testDeleteUser, arg.ID, arg.OfficeID)
 	return err
 }
 
@@ -259,7 +312,7 @@ func (q *Queries) TestGetDeletedAtWorkplace(ctx context.Context, id int64) (pgty
 }
 
 const testGetEmployee = `-- name: TestGetEmployee :one
-select id, name, workplace_id, deleted_at, created_at, updated_at from employees where id = $1 and deleted_at is null
+select id, name, workplace_id, code, name_kana, employment_type, hire_date, leave_date, hourly_wage, deleted_at, created_at, updated_at from employees where id = $1 and deleted_at is null
 `
 
 func (q *Queries) TestGetEmployee(ctx context.Context, id int64) (Employee, error) {
@@ -269,6 +322,12 @@ func (q *Queries) TestGetEmployee(ctx context.Context, id int64) (Employee, erro
 		&i.ID,
 		&i.Name,
 		&i.WorkplaceID,
+		&i.Code,
+		&i.NameKana,
+		&i.EmploymentType,
+		&i.HireDate,
+		&i.LeaveDate,
+		&i.HourlyWage,
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -6,11 +6,101 @@ package rdb
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/util"
 )
 
+type EmploymentType string
+
+const (
+	EmploymentTypeFullTime  EmploymentType = "full_time"
+	EmploymentTypePartTime  EmploymentType = "part_time"
+	EmploymentTypeContract  EmploymentType = "contract"
+	EmploymentTypeTemporary EmploymentType = "temporary"
+)
+
+func (e *EmploymentType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EmploymentType(s)
+	case string:
+		*e = EmploymentType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EmploymentType: %T", src)
+	}
+	return nil
+}
+
+type NullEmploymentType struct {
+	EmploymentType EmploymentType `json:"employment_type"`
+	Valid          bool           `json:"valid"` // Valid is true if EmploymentType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEmploymentType) Scan(value interface{}) error {
+	if value == nil {
+		ns.EmploymentType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EmploymentType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEmploymentType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EmploymentType), nil
+}
+
+type LeaveStatus string
+
+const (
+	LeaveStatusPending   LeaveStatus = "pending"
+	LeaveStatusApproved  LeaveStatus = "approved"
+	LeaveStatusRejected  LeaveStatus = "rejected"
+	LeaveStatusCancelled LeaveStatus = "cancelled"
+)
+
+func (e *LeaveStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = LeaveStatus(s)
+	case string:
+		*e = LeaveStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for LeaveStatus: %T", src)
+	}
+	return nil
+}
+
+type NullLeaveStatus struct {
+	LeaveStatus LeaveStatus `json:"leave_status"`
+	Valid       bool        `json:"valid"` // Valid is true if LeaveStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullLeaveStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.LeaveStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.LeaveStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullLeaveStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.LeaveStatus), nil
+}
+
 type UserType string
 
 const (
@@ -97,23 +187,132 @@ func (ns NullWorkType) Value() (driver.Value, error) {
 	return string(ns.WorkType), nil
 }
 
+type AuditLog struct {
+	ID         int64            `json:"id"`
+	OfficeID   int64            `json:"office_id"`
+	UserID     pgtype.Int8      `json:"user_id"`
+	UserName   pgtype.Text      `json:"user_name"`
+	UserRole   pgtype.Text      `json:"user_role"`
+	Action     string           `json:"action"`
+	Resource   string           `json:"resource"`
+	ResourceID pgtype.Int8      `json:"resource_id"`
+	Before     json.RawMessage  `json:"before"`
+	After      json.RawMessage  `json:"after"`
+	RequestID  pgtype.Text      `json:"request_id"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
 type Employee struct {
+	ID             int64            `json:"id"`
+	Name           string           `json:"name"`
+	WorkplaceID    int64            `json:"workplace_id"`
+	Code           pgtype.Text      `json:"code"`
+	NameKana       pgtype.Text      `json:"name_kana"`
+	EmploymentType EmploymentType   `json:"employment_type"`
+	HireDate       pgtype.Date      `json:"hire_date"`
+	LeaveDate      pgtype.Date      `json:"leave_date"`
+	HourlyWage     pgtype.Int4      `json:"hourly_wage"`
+	DeletedAt      pgtype.Timestamp `json:"deleted_at"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+}
+
+type EmployeeAssignment struct {
+	ID            int64            `json:"id"`
+	EmployeeID    int64            `json:"employee_id"`
+	WorkplaceID   int64            `json:"workplace_id"`
+	EffectiveFrom pgtype.Date      `json:"effective_from"`
+	EffectiveTo   pgtype.Date      `json:"effective_to"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+}
+
+type LeaveGrant struct {
+	ID         int64            `json:"id"`
+	EmployeeID int64            `json:"employee_id"`
+	GrantedOn  pgtype.Date      `json:"granted_on"`
+	ExpiresOn  pgtype.Date      `json:"expires_on"`
+	Days       int16            `json:"days"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+	UpdatedAt  pgtype.Timestamp `json:"updated_at"`
+}
+
+type LeaveRequest struct {
 	ID          int64            `json:"id"`
-	Name        string           `json:"name"`
-	WorkplaceID int64            `json:"workplace_id"`
-	DeletedAt   pgtype.Timestamp `json:"deleted_at"`
+	EmployeeID  int64            `json:"employee_id"`
+	LeaveTypeID int64            `json:"leave_type_id"`
+	Date        pgtype.Date      `json:"date"`
+	Status      LeaveStatus      `json:"status"`
+	Comment     pgtype.Text      `json:"comment"`
+	DecidedBy   pgtype.Int8      `json:"decided_by"`
+	DecidedAt   pgtype.Timestamp `json:"decided_at"`
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
 	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
 }
 
+type LeaveType struct {
+	ID        int64            `json:"id"`
+	OfficeID  int64            `json:"office_id"`
+	Name      string           `json:"name"`
+	Paid      bool             `json:"paid"`
+	Marker    string           `json:"marker"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
 type Office struct {
 	ID        int64            `json:"id"`
 	Name      string           `json:"name"`
+	TimeZone  string           `json:"time_zone"`
 	DeletedAt pgtype.Timestamp `json:"deleted_at"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
 	UpdatedAt pgtype.Timestamp `json:"updated_at"`
 }
 
+type OfficeClosure struct {
+	ID        int64            `json:"id"`
+	OfficeID  int64            `json:"office_id"`
+	Date      pgtype.Date      `json:"date"`
+	Name      string           `json:"name"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+type OvertimeRule struct {
+	OfficeID           int64            `json:"office_id"`
+	DailyLimitMinutes  int32            `json:"daily_limit_minutes"`
+	WeeklyLimitMinutes int32            `json:"weekly_limit_minutes"`
+	NightStart         util.Clock       `json:"night_start"`
+	NightEnd           util.Clock       `json:"night_end"`
+	LegalHoliday       int16            `json:"legal_holiday"`
+	WeekStart          int16            `json:"week_start"`
+	CreatedAt          pgtype.Timestamp `json:"created_at"`
+	UpdatedAt          pgtype.Timestamp `json:"updated_at"`
+}
+
+type Shift struct {
+	ID              int64            `json:"id"`
+	WorkplaceID     int64            `json:"workplace_id"`
+	EmployeeID      int64            `json:"employee_id"`
+	Date            pgtype.Date      `json:"date"`
+	StartTime       util.Clock       `json:"start_time"`
+	EndTime         util.Clock       `json:"end_time"`
+	ShiftTemplateID pgtype.Int8      `json:"shift_template_id"`
+	PublishedAt     pgtype.Timestamp `json:"published_at"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	UpdatedAt       pgtype.Timestamp `json:"updated_at"`
+}
+
+type ShiftTemplate struct {
+	ID          int64            `json:"id"`
+	WorkplaceID int64            `json:"workplace_id"`
+	Name        string           `json:"name"`
+	StartTime   util.Clock       `json:"start_time"`
+	EndTime     util.Clock       `json:"end_time"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+}
+
 type User struct {
 	ID         int64            `json:"id"`
 	OfficeID   int64            `json:"office_id"`
@@ -125,27 +324,37 @@ type User struct {
 	UpdatedAt  pgtype.Timestamp `json:"updated_at"`
 }
 
+type UserIDCounter struct {
+	OfficeID int64 `json:"office_id"`
+	LastID   int64 `json:"last_id"`
+}
+
 type WorkEntry struct {
-	ID          int64            `json:"id"`
-	EmployeeID  int64            `json:"employee_id"`
-	WorkplaceID int64            `json:"workplace_id"`
-	Date        pgtype.Date      `json:"date"`
-	Hours       pgtype.Int2      `json:"hours"`
-	StartTime   pgtype.Time      `json:"start_time"`
-	EndTime     pgtype.Time      `json:"end_time"`
-	Attendance  pgtype.Bool      `json:"attendance"`
-	Comment     pgtype.Text      `json:"comment"`
-	DeletedAt   pgtype.Timestamp `json:"deleted_at"`
-	CreatedAt   pgtype.Timestamp `json:"created_at"`
-	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+	ID                  int64            `json:"id"`
+	EmployeeID          int64            `json:"employee_id"`
+	WorkplaceID         int64            `json:"workplace_id"`
+	Date                pgtype.Date      `json:"date"`
+	Hours               pgtype.Int2      `json:"hours"`
+	StartTime           pgtype.Time      `json:"start_time"`
+	EndTime             pgtype.Time      `json:"end_time"`
+	Attendance          pgtype.Bool      `json:"attendance"`
+	Comment             pgtype.Text      `json:"comment"`
+	DeletedWithEmployee bool             `json:"deleted_with_employee"`
+	DeletedAt           pgtype.Timestamp `json:"deleted_at"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `json:"updated_at"`
 }
 
 type Workplace struct {
-	ID        int64            `json:"id"`
-	Name      string           `json:"name"`
-	OfficeID  int64            `json:"office_id"`
-	WorkType  WorkType         `json:"work_type"`
-	DeletedAt pgtype.Timestamp `json:"deleted_at"`
-	CreatedAt pgtype.Timestamp `json:"created_at"`
-	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+	ID                   int64            `json:"id"`
+	Name                 string           `json:"name"`
+	OfficeID             int64            `json:"office_id"`
+	WorkType             WorkType         `json:"work_type"`
+	Address              pgtype.Text      `json:"address"`
+	DefaultStartTime     util.Clock       `json:"default_start_time"`
+	DefaultEndTime       util.Clock       `json:"default_end_time"`
+	AllowMultipleEntries bool             `json:"allow_multiple_entries"`
+	DeletedAt            pgtype.Timestamp `json:"deleted_at"`
+	CreatedAt            pgtype.Timestamp `json:"created_at"`
+	UpdatedAt            pgtype.Timestamp `json:"updated_at"`
 }
@@ -10,7 +10,7 @@ import (
 )
 
 const allOffices = `-- name: AllOffices :many
-select id, name, deleted_at, created_at, updated_at from offices where deleted_at is null
+select id, name, time_zone, deleted_at, created_at, updated_at from offices where deleted_at is null
 `
 
 func (q *Queries) AllOffices(ctx context.Context) ([]Office, error) {
@@ -25,6 +25,7 @@ func (q *Queries) AllOffices(ctx context.Context) ([]Office, error) {
 		if err := rows.Scan(
 			&i.ID,
 			&i.Name,
+			&i.TimeZone,
 			&i.DeletedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
@@ -40,7 +41,7 @@ func (q *Queries) AllOffices(ctx context.Context) ([]Office, error) {
 }
 
 const createOffice = `-- name: CreateOffice :one
-insert into offices (name) values ($1) returning id, name, deleted_at, created_at, updated_at
+insert into offices (name) values ($1) returning id, name, time_zone, deleted_at, created_at, updated_at
 `
 
 func (q *Queries) CreateOffice(ctx context.Context, name string) (Office, error) {
@@ -49,6 +50,7 @@ func (q *Queries) CreateOffice(ctx context.Context, name string) (Office, error)
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
+		&i.TimeZone,
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -57,7 +59,7 @@ func (q *Queries) CreateOffice(ctx context.Context, name string) (Office, error)
 }
 
 const getOffice = `-- name: GetOffice :one
-select id, name, deleted_at, created_at, updated_at from offices where id = $1 and deleted_at is null
+select id, name, time_zone, deleted_at, created_at, updated_at from offices where id = $1 and deleted_at is null
 `
 
 func (q *Queries) GetOffice(ctx context.Context, id int64) (Office, error) {
@@ -66,6 +68,7 @@ func (q *Queries) GetOffice(ctx context.Context, id int64) (Office, error) {
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
+		&i.TimeZone,
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -81,3 +84,26 @@ func (q *Queries) SoftDeleteOffice(ctx context.Context, id int64) error {
 	_, err := q.db.Exec(ctx, softDeleteOffice, id)
 	return err
 }
+
+const updateOfficeTimeZone = `-- name: UpdateOfficeTimeZone :one
+update offices set time_zone = $2, updated_at = now() where id = $1 and deleted_at is null returning id, name, time_zone, deleted_at, created_at, updated_at
+`
+
+type UpdateOfficeTimeZoneParams struct {
+	ID       int64  `json:"id"`
+	TimeZone string `json:"time_zone"`
+}
+
+func (q *Queries) UpdateOfficeTimeZone(ctx context.Context, arg UpdateOfficeTimeZoneParams) (Office, error) {
+	row := q.db.QueryRow(ctx, updateOfficeTimeZone, arg.ID, arg.TimeZone)
+	var i Office
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.TimeZone,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -12,21 +12,16 @@ import (
 )
 
 const createUser = `-- name: CreateUser :one
-WITH MaxId AS (
-    SELECT COALESCE(MAX(id), 0) AS max_id
-    FROM users
-    WHERE office_id = $1
-),
-NewId AS (
-    SELECT max_id + 1 AS new_id
-    FROM MaxId
+with next_id as (
+    insert into user_id_counters (office_id, last_id)
+    values ($1, (select coalesce(max(id), 0) + 1 from users where office_id = $1))
+    on conflict (office_id) do update
+    set last_id = greatest(user_id_counters.last_id, (select coalesce(max(id), 0) from users where office_id = $1)) + 1
+    returning last_id
 )
-INSERT INTO users (id, office_id, name, password, role, employee_id)
-VALUES (
-    (SELECT new_id FROM NewId),
-    $1, $2, $3, $4, $5
-)
-RETURNING id, office_id, name, password, role, employee_id, created_at, updated_at
+insert into users (id, office_id, name, password, role, employee_id)
+values ((select last_id from next_id), $1, $2, $3, $4, $5)
+returning id, office_id, name, password, role, employee_id, created_at, updated_at
 `
 
 type CreateUserParams struct {
@@ -11,10 +11,43 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countWorkEntries = `-- name: CountWorkEntries :one
+select count(*)
+from work_entries
+join workplaces on work_entries.workplace_id = workplaces.id
+where workplaces.office_id = $1
+    and work_entries.deleted_at is null
+    and ($2::bigint is null or work_entries.workplace_id = $2)
+    and ($3::bigint is null or work_entries.employee_id = $3)
+    and ($4::date is null or work_entries.date >= $4)
+    and ($5::date is null or work_entries.date <= $5)
+`
+
+type CountWorkEntriesParams struct {
+	OfficeID    int64       `json:"office_id"`
+	WorkplaceID pgtype.Int8 `json:"workplace_id"`
+	EmployeeID  pgtype.Int8 `json:"employee_id"`
+	FromDate    pgtype.Date `json:"from_date"`
+	ToDate      pgtype.Date `json:"to_date"`
+}
+
+func (q *Queries) CountWorkEntries(ctx context.Context, arg CountWorkEntriesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countWorkEntries,
+		arg.OfficeID,
+		arg.WorkplaceID,
+		arg.EmployeeID,
+		arg.FromDate,
+		arg.ToDate,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createWorkEntry = `-- name: CreateWorkEntry :one
 insert into work_entries (employee_id, workplace_id, date, hours, start_time, end_time, attendance, comment)
 values ($1, $2, $3, $4, $5, $6, $7, $8)
-returning id, employee_id, workplace_id, date, hours, start_time, end_time, attendance, comment, deleted_at, created_at, updated_at
+returning id, employee_id, workplace_id, date, hours, start_time, end_time, attendance, comment, deleted_with_employee, deleted_at, created_at, updated_at
 `
 
 type CreateWorkEntryParams struct {
@@ -50,6 +83,7 @@ func (q *Queries) CreateWorkEntry(ctx context.Context, arg CreateWorkEntryParams
 		&i.EndTime,
 		&i.Attendance,
 		&i.Comment,
+		&i.DeletedWithEmployee,
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -57,8 +91,132 @@ func (q *Queries) CreateWorkEntry(ctx context.Context, arg CreateWorkEntryParams
 	return i, err
 }
 
+const getDeletedWorkEntries = `-- name: GetDeletedWorkEntries :many
+select workplaces.name as workplace_name, employees.name as employee_name, work_entries.id, work_entries.employee_id, work_entries.workplace_id, work_entries.date, work_entries.hours, work_entries.start_time, work_entries.end_time, work_entries.attendance, work_entries.comment, work_entries.deleted_with_employee, work_entries.deleted_at, work_entries.created_at, work_entries.updated_at
+from work_entries
+join employees on work_entries.employee_id = employees.id
+join workplaces on work_entries.workplace_id = workplaces.id
+where workplaces.office_id = $1 and work_entries.deleted_at >= $2
+order by work_entries.deleted_at desc, work_entries.id desc
+`
+
+type GetDeletedWorkEntriesParams struct {
+	OfficeID     int64            `json:"office_id"`
+	DeletedSince pgtype.Timestamp `json:"deleted_since"`
+}
+
+type GetDeletedWorkEntriesRow struct {
+	WorkplaceName       string           `json:"workplace_name"`
+	EmployeeName        string           `json:"employee_name"`
+	ID                  int64            `json:"id"`
+	EmployeeID          int64            `json:"employee_id"`
+	WorkplaceID         int64            `json:"workplace_id"`
+	Date                pgtype.Date      `json:"date"`
+	Hours               pgtype.Int2      `json:"hours"`
+	StartTime           pgtype.Time      `json:"start_time"`
+	EndTime             pgtype.Time      `json:"end_time"`
+	Attendance          pgtype.Bool      `json:"attendance"`
+	Comment             pgtype.Text      `json:"comment"`
+	DeletedWithEmployee bool             `json:"deleted_with_employee"`
+	DeletedAt           pgtype.Timestamp `json:"deleted_at"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `json:"updated_at"`
+}
+
+func (q *Queries) GetDeletedWorkEntries(ctx context.Context, arg GetDeletedWorkEntriesParams) ([]GetDeletedWorkEntriesRow, error) {
+	rows, err := q.db.Query(ctx, getDeletedWorkEntries, arg.OfficeID, arg.DeletedSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDeletedWorkEntriesRow
+	for rows.Next() {
+		var i GetDeletedWorkEntriesRow
+		if err := rows.Scan(
+			&i.WorkplaceName,
+			&i.EmployeeName,
+			&i.ID,
+			&i.EmployeeID,
+			&i.WorkplaceID,
+			&i.Date,
+			&i.Hours,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Attendance,
+			&i.Comment,
+			&i.DeletedWithEmployee,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletedWorkEntry = `-- name: GetDeletedWorkEntry :one
+select workplaces.name as workplace_name, employees.name as employee_name, work_entries.id, work_entries.employee_id, work_entries.workplace_id, work_entries.date, work_entries.hours, work_entries.start_time, work_entries.end_time, work_entries.attendance, work_entries.comment, work_entries.deleted_with_employee, work_entries.deleted_at, work_entries.created_at, work_entries.updated_at,
+    workplaces.office_id, employees.deleted_at as employee_deleted_at, workplaces.deleted_at as workplace_deleted_at
+from work_entries
+join employees on work_entries.employee_id = employees.id
+join workplaces on work_entries.workplace_id = workplaces.id
+where work_entries.id = $1 and work_entries.deleted_at is not null
+`
+
+type GetDeletedWorkEntryRow struct {
+	WorkplaceName       string           `json:"workplace_name"`
+	EmployeeName        string           `json:"employee_name"`
+	ID                  int64            `json:"id"`
+	EmployeeID          int64            `json:"employee_id"`
+	WorkplaceID         int64            `json:"workplace_id"`
+	Date                pgtype.Date      `json:"date"`
+	Hours               pgtype.Int2      `json:"hours"`
+	StartTime           pgtype.Time      `json:"start_time"`
+	EndTime             pgtype.Time      `json:"end_time"`
+	Attendance          pgtype.Bool      `json:"attendance"`
+	Comment             pgtype.Text      `json:"comment"`
+	DeletedWithEmployee bool             `json:"deleted_with_employee"`
+	DeletedAt           pgtype.Timestamp `json:"deleted_at"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `json:"updated_at"`
+	OfficeID            int64            `json:"office_id"`
+	EmployeeDeletedAt   pgtype.Timestamp `json:"employee_deleted_at"`
+	WorkplaceDeletedAt  pgtype.Timestamp `json:"workplace_deleted_at"`
+}
+
+func (q *Queries) GetDeletedWorkEntry(ctx context.Context, id int64) (GetDeletedWorkEntryRow, error) {
+	row := q.db.QueryRow(ctx, getDeletedWorkEntry, id)
+	var i GetDeletedWorkEntryRow
+	err := row.Scan(
+		&i.WorkplaceName,
+		&i.EmployeeName,
+		&i.ID,
+		&i.EmployeeID,
+		&i.WorkplaceID,
+		&i.Date,
+		&i.Hours,
+		&i.StartTime,
+		&i.EndTime,
+		&i.Attendance,
+		&i.Comment,
+		&i.DeletedWithEmployee,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OfficeID,
+		&i.EmployeeDeletedAt,
+		&i.WorkplaceDeletedAt,
+	)
+	return i, err
+}
+
 const getWorkEntriesByEmployee = `-- name: GetWorkEntriesByEmployee :many
-select workplaces.name as workplace_name, employees.name as employee_name, work_entries.id, work_entries.employee_id, work_entries.workplace_id, work_entries.date, work_entries.hours, work_entries.start_time, work_entries.end_time, work_entries.attendance, work_entries.comment, work_entries.deleted_at, work_entries.created_at, work_entries.updated_at
+select workplaces.name as workplace_name, employees.name as employee_name, work_entries.id, work_entries.employee_id, work_entries.workplace_id, work_entries.date, work_entries.hours, work_entries.start_time, work_entries.end_time, work_entries.attendance, work_entries.comment, work_entries.deleted_with_employee, work_entries.deleted_at, work_entries.created_at, work_entries.updated_at
 from work_entries
 join employees on work_entries.employee_id = employees.id
 join workplaces on work_entries.workplace_id = workplaces.id
@@ -66,20 +224,21 @@ where employees.id = $1 and work_entries.deleted_at is null
 `
 
 type GetWorkEntriesByEmployeeRow struct {
-	WorkplaceName string           `json:"workplace_name"`
-	EmployeeName  string           `json:"employee_name"`
-	ID            int64            `json:"id"`
-	EmployeeID    int64            `json:"employee_id"`
-	WorkplaceID   int64            `json:"workplace_id"`
-	Date          pgtype.Date      `json:"date"`
-	Hours         pgtype.Int2      `json:"hours"`
-	StartTime     pgtype.Time      `json:"start_time"`
-	EndTime       pgtype.Time      `json:"end_time"`
-	Attendance    pgtype.Bool      `json:"attendance"`
-	Comment       pgtype.Text      `json:"comment"`
-	DeletedAt     pgtype.Timestamp `json:"deleted_at"`
-	CreatedAt     pgtype.Timestamp `json:"created_at"`
-	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+	WorkplaceName       string           `json:"workplace_name"`
+	EmployeeName        string           `json:"employee_name"`
+	ID                  int64            `json:"id"`
+	EmployeeID          int64            `json:"employee_id"`
+	WorkplaceID         int64            `json:"workplace_id"`
+	Date                pgtype.Date      `json:"date"`
+	Hours               pgtype.Int2      `json:"hours"`
+	StartTime           pgtype.Time      `json:"start_time"`
+	EndTime             pgtype.Time      `json:"end_time"`
+	Attendance          pgtype.Bool      `json:"attendance"`
+	Comment             pgtype.Text      `json:"comment"`
+	DeletedWithEmployee bool             `json:"deleted_with_employee"`
+	DeletedAt           pgtype.Timestamp `json:"deleted_at"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `json:"updated_at"`
 }
 
 func (q *Queries) GetWorkEntriesByEmployee(ctx context.Context, id int64) ([]GetWorkEntriesByEmployeeRow, error) {
@@ -103,6 +262,7 @@ func (q *Queries) GetWorkEntriesByEmployee(ctx context.Context, id int64) ([]Get
 			&i.EndTime,
 			&i.Attendance,
 			&i.Comment,
+			&i.DeletedWithEmployee,
 			&i.DeletedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
@@ -117,44 +277,27 @@ func (q *Queries) GetWorkEntriesByEmployee(ctx context.Context, id int64) ([]Get
 	return items, nil
 }
 
-const getWorkEntriesByOffice = `-- name: GetWorkEntriesByOffice :many
-select workplaces.name as workplace_name, employees.name as employee_name, work_entries.id, work_entries.employee_id, work_entries.workplace_id, work_entries.date, work_entries.hours, work_entries.start_time, work_entries.end_time, work_entries.attendance, work_entries.comment, work_entries.deleted_at, work_entries.created_at, work_entries.updated_at
-from work_entries
-join employees on work_entries.employee_id = employees.id
-join workplaces on work_entries.workplace_id = workplaces.id
-join offices on workplaces.office_id = offices.id
-where offices.id = $1 and work_entries.deleted_at is null
+const getWorkEntriesByEmployeeAndDate = `-- name: GetWorkEntriesByEmployeeAndDate :many
+select id, employee_id, workplace_id, date, hours, start_time, end_time, attendance, comment, deleted_with_employee, deleted_at, created_at, updated_at from work_entries
+where employee_id = $1 and date = $2 and deleted_at is null
+order by id
 `
 
-type GetWorkEntriesByOfficeRow struct {
-	WorkplaceName string           `json:"workplace_name"`
-	EmployeeName  string           `json:"employee_name"`
-	ID            int64            `json:"id"`
-	EmployeeID    int64            `json:"employee_id"`
-	WorkplaceID   int64            `json:"workplace_id"`
-	Date          pgtype.Date      `json:"date"`
-	Hours         pgtype.Int2      `json:"hours"`
-	StartTime     pgtype.Time      `json:"start_time"`
-	EndTime       pgtype.Time      `json:"end_time"`
-	Attendance    pgtype.Bool      `json:"attendance"`
-	Comment       pgtype.Text      `json:"comment"`
-	DeletedAt     pgtype.Timestamp `json:"deleted_at"`
-	CreatedAt     pgtype.Timestamp `json:"created_at"`
-	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+type GetWorkEntriesByEmployeeAndDateParams struct {
+	EmployeeID int64       `json:"employee_id"`
+	Date       pgtype.Date `json:"date"`
 }
 
-func (q *Queries) GetWorkEntriesByOffice(ctx context.Context, id int64) ([]GetWorkEntriesByOfficeRow, error) {
-	rows, err := q.db.Query(ctx, getWorkEntriesByOffice, id)
+func (q *Queries) GetWorkEntriesByEmployeeAndDate(ctx context.Context, arg GetWorkEntriesByEmployeeAndDateParams) ([]WorkEntry, error) {
+	rows, err := q.db.Query(ctx, getWorkEntriesByEmployeeAndDate, arg.EmployeeID, arg.Date)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetWorkEntriesByOfficeRow
+	var items []WorkEntry
 	for rows.Next() {
-		var i GetWorkEntriesByOfficeRow
+		var i WorkEntry
 		if err := rows.Scan(
-			&i.WorkplaceName,
-			&i.EmployeeName,
 			&i.ID,
 			&i.EmployeeID,
 			&i.WorkplaceID,
@@ -164,6 +307,7 @@ func (q *Queries) GetWorkEntriesByOffice(ctx context.Context, id int64) ([]GetWo
 			&i.EndTime,
 			&i.Attendance,
 			&i.Comment,
+			&i.DeletedWithEmployee,
 			&i.DeletedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
@@ -178,40 +322,228 @@ func (q *Queries) GetWorkEntriesByOffice(ctx context.Context, id int64) ([]GetWo
 	return items, nil
 }
 
-const getWorkEntriesByWorkplace = `-- name: GetWorkEntriesByWorkplace :many
-select workplaces.name as workplace_name, employees.name as employee_name, work_entries.id, work_entries.employee_id, work_entries.workplace_id, work_entries.date, work_entries.hours, work_entries.start_time, work_entries.end_time, work_entries.attendance, work_entries.comment, work_entries.deleted_at, work_entries.created_at, work_entries.updated_at
+const getWorkEntriesForOvertime = `-- name: GetWorkEntriesForOvertime :many
+select work_entries.employee_id, employees.name as employee_name, work_entries.workplace_id,
+    work_entries.date, work_entries.hours, work_entries.start_time, work_entries.end_time
+from work_entries
+join employees on work_entries.employee_id = employees.id
+join workplaces on work_entries.workplace_id = workplaces.id
+where workplaces.office_id = $1
+    and work_entries.deleted_at is null
+    and work_entries.date >= $2
+    and work_entries.date <= $3
+    and ($4::bigint is null or work_entries.employee_id = $4)
+order by coalesce(employees.name_kana, employees.name), work_entries.employee_id, work_entries.date, work_entries.start_time
+`
+
+type GetWorkEntriesForOvertimeParams struct {
+	OfficeID   int64       `json:"office_id"`
+	FromDate   pgtype.Date `json:"from_date"`
+	ToDate     pgtype.Date `json:"to_date"`
+	EmployeeID pgtype.Int8 `json:"employee_id"`
+}
+
+type GetWorkEntriesForOvertimeRow struct {
+	EmployeeID   int64       `json:"employee_id"`
+	EmployeeName string      `json:"employee_name"`
+	WorkplaceID  int64       `json:"workplace_id"`
+	Date         pgtype.Date `json:"date"`
+	Hours        pgtype.Int2 `json:"hours"`
+	StartTime    pgtype.Time `json:"start_time"`
+	EndTime      pgtype.Time `json:"end_time"`
+}
+
+func (q *Queries) GetWorkEntriesForOvertime(ctx context.Context, arg GetWorkEntriesForOvertimeParams) ([]GetWorkEntriesForOvertimeRow, error) {
+	rows, err := q.db.Query(ctx, getWorkEntriesForOvertime,
+		arg.OfficeID,
+		arg.FromDate,
+		arg.ToDate,
+		arg.EmployeeID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWorkEntriesForOvertimeRow
+	for rows.Next() {
+		var i GetWorkEntriesForOvertimeRow
+		if err := rows.Scan(
+			&i.EmployeeID,
+			&i.EmployeeName,
+			&i.WorkplaceID,
+			&i.Date,
+			&i.Hours,
+			&i.StartTime,
+			&i.EndTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWorkEntriesForSummary = `-- name: GetWorkEntriesForSummary :many
+select work_entries.employee_id, work_entries.workplace_id, work_entries.date,
+    work_entries.hours, work_entries.start_time, work_entries.end_time, work_entries.attendance
+from work_entries
+join workplaces on work_entries.workplace_id = workplaces.id
+where workplaces.office_id = $1
+    and work_entries.deleted_at is null
+    and work_entries.date >= $2
+    and work_entries.date <= $3
+    and ($4::bigint is null or work_entries.workplace_id = $4)
+    and ($5::bigint is null or work_entries.employee_id = $5)
+`
+
+type GetWorkEntriesForSummaryParams struct {
+	OfficeID    int64       `json:"office_id"`
+	FromDate    pgtype.Date `json:"from_date"`
+	ToDate      pgtype.Date `json:"to_date"`
+	WorkplaceID pgtype.Int8 `json:"workplace_id"`
+	EmployeeID  pgtype.Int8 `json:"employee_id"`
+}
+
+type GetWorkEntriesForSummaryRow struct {
+	EmployeeID  int64       `json:"employee_id"`
+	WorkplaceID int64       `json:"workplace_id"`
+	Date        pgtype.Date `json:"date"`
+	Hours       pgtype.Int2 `json:"hours"`
+	StartTime   pgtype.Time `json:"start_time"`
+	EndTime     pgtype.Time `json:"end_time"`
+	Attendance  pgtype.Bool `json:"attendance"`
+}
+
+func (q *Queries) GetWorkEntriesForSummary(ctx context.Context, arg GetWorkEntriesForSummaryParams) ([]GetWorkEntriesForSummaryRow, error) {
+	rows, err := q.db.Query(ctx, getWorkEntriesForSummary,
+		arg.OfficeID,
+		arg.FromDate,
+		arg.ToDate,
+		arg.WorkplaceID,
+		arg.EmployeeID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWorkEntriesForSummaryRow
+	for rows.Next() {
+		var i GetWorkEntriesForSummaryRow
+		if err := rows.Scan(
+			&i.EmployeeID,
+			&i.WorkplaceID,
+			&i.Date,
+			&i.Hours,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Attendance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWorkEntry = `-- name: GetWorkEntry :one
+select id, employee_id, workplace_id, date, hours, start_time, end_time, attendance, comment, deleted_with_employee, deleted_at, created_at, updated_at from work_entries where id = $1 and deleted_at is null
+`
+
+func (q *Queries) GetWorkEntry(ctx context.Context, id int64) (WorkEntry, error) {
+	row := q.db.QueryRow(ctx, getWorkEntry, id)
+	var i WorkEntry
+	err := row.Scan(
+		&i.ID,
+		&i.EmployeeID,
+		&i.WorkplaceID,
+		&i.Date,
+		&i.Hours,
+		&i.StartTime,
+		&i.EndTime,
+		&i.Attendance,
+		&i.Comment,
+		&i.DeletedWithEmployee,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listWorkEntries = `-- name: ListWorkEntries :many
+select workplaces.name as workplace_name, employees.name as employee_name, work_entries.id, work_entries.employee_id, work_entries.workplace_id, work_entries.date, work_entries.hours, work_entries.start_time, work_entries.end_time, work_entries.attendance, work_entries.comment, work_entries.deleted_with_employee, work_entries.deleted_at, work_entries.created_at, work_entries.updated_at
 from work_entries
 join employees on work_entries.employee_id = employees.id
 join workplaces on work_entries.workplace_id = workplaces.id
-where workplaces.id = $1 and work_entries.deleted_at is null
+where workplaces.office_id = $1
+    and work_entries.deleted_at is null
+    and ($2::bigint is null or work_entries.workplace_id = $2)
+    and ($3::bigint is null or work_entries.employee_id = $3)
+    and ($4::date is null or work_entries.date >= $4)
+    and ($5::date is null or work_entries.date <= $5)
+order by
+    case when $6::text = 'date' then work_entries.date end asc,
+    case when $6::text = '-date' then work_entries.date end desc,
+    case when $6::text = 'employee' then coalesce(employees.name_kana, employees.name) end asc,
+    case when $6::text = '-employee' then coalesce(employees.name_kana, employees.name) end desc,
+    work_entries.date desc,
+    work_entries.id desc
+limit $7 offset $8
 `
 
-type GetWorkEntriesByWorkplaceRow struct {
-	WorkplaceName string           `json:"workplace_name"`
-	EmployeeName  string           `json:"employee_name"`
-	ID            int64            `json:"id"`
-	EmployeeID    int64            `json:"employee_id"`
-	WorkplaceID   int64            `json:"workplace_id"`
-	Date          pgtype.Date      `json:"date"`
-	Hours         pgtype.Int2      `json:"hours"`
-	StartTime     pgtype.Time      `json:"start_time"`
-	EndTime       pgtype.Time      `json:"end_time"`
-	Attendance    pgtype.Bool      `json:"attendance"`
-	Comment       pgtype.Text      `json:"comment"`
-	DeletedAt     pgtype.Timestamp `json:"deleted_at"`
-	CreatedAt     pgtype.Timestamp `json:"created_at"`
-	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+type ListWorkEntriesParams struct {
+	OfficeID    int64       `json:"office_id"`
+	WorkplaceID pgtype.Int8 `json:"workplace_id"`
+	EmployeeID  pgtype.Int8 `json:"employee_id"`
+	FromDate    pgtype.Date `json:"from_date"`
+	ToDate      pgtype.Date `json:"to_date"`
+	Sort        string      `json:"sort"`
+	PageLimit   int32       `json:"page_limit"`
+	PageOffset  int32       `json:"page_offset"`
 }
 
-func (q *Queries) GetWorkEntriesByWorkplace(ctx context.Context, id int64) ([]GetWorkEntriesByWorkplaceRow, error) {
-	rows, err := q.db.Query(ctx, getWorkEntriesByWorkplace, id)
+type ListWorkEntriesRow struct {
+	WorkplaceName       string           `json:"workplace_name"`
+	EmployeeName        string           `json:"employee_name"`
+	ID                  int64            `json:"id"`
+	EmployeeID          int64            `json:"employee_id"`
+	WorkplaceID         int64            `json:"workplace_id"`
+	Date                pgtype.Date      `json:"date"`
+	Hours               pgtype.Int2      `json:"hours"`
+	StartTime           pgtype.Time      `json:"start_time"`
+	EndTime             pgtype.Time      `json:"end_time"`
+	Attendance          pgtype.Bool      `json:"attendance"`
+	Comment             pgtype.Text      `json:"comment"`
+	DeletedWithEmployee bool             `json:"deleted_with_employee"`
+	DeletedAt           pgtype.Timestamp `json:"deleted_at"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `json:"updated_at"`
+}
+
+func (q *Queries) ListWorkEntries(ctx context.Context, arg ListWorkEntriesParams) ([]ListWorkEntriesRow, error) {
+	rows, err := q.db.Query(ctx, listWorkEntries,
+		arg.OfficeID,
+		arg.WorkplaceID,
+		arg.EmployeeID,
+		arg.FromDate,
+		arg.ToDate,
+		arg.Sort,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetWorkEntriesByWorkplaceRow
+	var items []ListWorkEntriesRow
 	for rows.Next() {
-		var i GetWorkEntriesByWorkplaceRow
+		var i ListWorkEntriesRow
 		if err := rows.Scan(
 			&i.WorkplaceName,
 			&i.EmployeeName,
@@ -224,6 +556,7 @@ func (q *Queries) GetWorkEntriesByWorkplace(ctx context.Context, id int64) ([]Ge
 			&i.EndTime,
 			&i.Attendance,
 			&i.Comment,
+			&i.DeletedWithEmployee,
 			&i.DeletedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
@@ -238,12 +571,39 @@ func (q *Queries) GetWorkEntriesByWorkplace(ctx context.Context, id int64) ([]Ge
 	return items, nil
 }
 
-const getWorkEntry = `-- name: GetWorkEntry :one
-select id, employee_id, workplace_id, date, hours, start_time, end_time, attendance, comment, deleted_at, created_at, updated_at from work_entries where id = $1 and deleted_at is null
+const purgeWorkEntries = `-- name: PurgeWorkEntries :execrows
+delete from work_entries where deleted_at < $1
 `
 
-func (q *Queries) GetWorkEntry(ctx context.Context, id int64) (WorkEntry, error) {
-	row := q.db.QueryRow(ctx, getWorkEntry, id)
+func (q *Queries) PurgeWorkEntries(ctx context.Context, deletedBefore pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeWorkEntries, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreWorkEntriesByEmployee = `-- name: RestoreWorkEntriesByEmployee :execrows
+update work_entries set deleted_at = null, deleted_with_employee = false, updated_at = now()
+where employee_id = $1 and deleted_with_employee
+`
+
+func (q *Queries) RestoreWorkEntriesByEmployee(ctx context.Context, employeeID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreWorkEntriesByEmployee, employeeID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreWorkEntry = `-- name: RestoreWorkEntry :one
+update work_entries set deleted_at = null, deleted_with_employee = false, updated_at = now()
+where id = $1 and deleted_at is not null
+returning id, employee_id, workplace_id, date, hours, start_time, end_time, attendance, comment, deleted_with_employee, deleted_at, created_at, updated_at
+`
+
+func (q *Queries) RestoreWorkEntry(ctx context.Context, id int64) (WorkEntry, error) {
+	row := q.db.QueryRow(ctx, restoreWorkEntry, id)
 	var i WorkEntry
 	err := row.Scan(
 		&i.ID,
@@ -255,6 +615,7 @@ func (q *Queries) GetWorkEntry(ctx context.Context, id int64) (WorkEntry, error)
 		&i.EndTime,
 		&i.Attendance,
 		&i.Comment,
+		&i.DeletedWithEmployee,
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -263,7 +624,7 @@ func (q *Queries) GetWorkEntry(ctx context.Context, id int64) (WorkEntry, error)
 }
 
 const softDeleteWorkEntriesByEmployee = `-- name: SoftDeleteWorkEntriesByEmployee :exec
-update work_entries set deleted_at = now() where employee_id = $1 and deleted_at is null
+update work_entries set deleted_at = now(), deleted_with_employee = true where employee_id = $1 and deleted_at is null
 `
 
 func (q *Queries) SoftDeleteWorkEntriesByEmployee(ctx context.Context, employeeID int64) error {
@@ -271,8 +632,21 @@ func (q *Queries) SoftDeleteWorkEntriesByEmployee(ctx context.Context, employeeI
 	return err
 }
 
+const softDeleteWorkEntriesByEmployees = `-- name: SoftDeleteWorkEntriesByEmployees :execrows
+update work_entries set deleted_at = now(), deleted_with_employee = true
+where employee_id = any($1::bigint[]) and deleted_at is null
+`
+
+func (q *Queries) SoftDeleteWorkEntriesByEmployees(ctx context.Context, employeeIds []int64) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteWorkEntriesByEmployees, employeeIds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const softDeleteWorkEntry = `-- name: SoftDeleteWorkEntry :exec
-update work_entries set deleted_at = now() where id = $1
+update work_entries set deleted_at = now(), deleted_with_employee = false where id = $1
 `
 
 func (q *Queries) SoftDeleteWorkEntry(ctx context.Context, id int64) error {
@@ -7,28 +7,96 @@ package rdb
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/util"
 )
 
+const countDeletedWorkplaces = `-- name: CountDeletedWorkplaces :one
+select count(*) from workplaces
+where office_id = $1 and deleted_at >= $2
+`
+
+type CountDeletedWorkplacesParams struct {
+	OfficeID     int64            `json:"office_id"`
+	DeletedSince pgtype.Timestamp `json:"deleted_since"`
+}
+
+func (q *Queries) CountDeletedWorkplaces(ctx context.Context, arg CountDeletedWorkplacesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countDeletedWorkplaces, arg.OfficeID, arg.DeletedSince)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countWorkplaces = `-- name: CountWorkplaces :one
+select count(*) from workplaces where office_id = $1 and deleted_at is null
+`
+
+func (q *Queries) CountWorkplaces(ctx context.Context, officeID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countWorkplaces, officeID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createWorkplace = `-- name: CreateWorkplace :one
-insert into workplaces (name, office_id, work_type)
-values ($1, $2, $3)
-returning id, name, office_id, work_type, deleted_at, created_at, updated_at
+insert into workplaces (name, office_id, work_type, address, default_start_time, default_end_time)
+values ($1, $2, $3, $4, $5, $6)
+returning id, name, office_id, work_type, address, default_start_time, default_end_time, allow_multiple_entries, deleted_at, created_at, updated_at
 `
 
 type CreateWorkplaceParams struct {
-	Name     string   `json:"name"`
-	OfficeID int64    `json:"office_id"`
-	WorkType WorkType `json:"work_type"`
+	Name             string      `json:"name"`
+	OfficeID         int64       `json:"office_id"`
+	WorkType         WorkType    `json:"work_type"`
+	Address          pgtype.Text `json:"address"`
+	DefaultStartTime util.Clock  `json:"default_start_time"`
+	DefaultEndTime   util.Clock  `json:"default_end_time"`
 }
 
 func (q *Queries) CreateWorkplace(ctx context.Context, arg CreateWorkplaceParams) (Workplace, error) {
-	row := q.db.QueryRow(ctx, createWorkplace, arg.Name, arg.OfficeID, arg.WorkType)
+	row := q.db.QueryRow(ctx, createWorkplace,
+		arg.Name,
+		arg.OfficeID,
+		arg.WorkType,
+		arg.Address,
+		arg.DefaultStartTime,
+		arg.DefaultEndTime,
+	)
+	var i Workplace
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OfficeID,
+		&i.WorkType,
+		&i.Address,
+		&i.DefaultStartTime,
+		&i.DefaultEndTime,
+		&i.AllowMultipleEntries,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getDeletedWorkplace = `-- name: GetDeletedWorkplace :one
+select id, name, office_id, work_type, address, default_start_time, default_end_time, allow_multiple_entries, deleted_at, created_at, updated_at from workplaces where id = $1 and deleted_at is not null
+`
+
+func (q *Queries) GetDeletedWorkplace(ctx context.Context, id int64) (Workplace, error) {
+	row := q.db.QueryRow(ctx, getDeletedWorkplace, id)
 	var i Workplace
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.OfficeID,
 		&i.WorkType,
+		&i.Address,
+		&i.DefaultStartTime,
+		&i.DefaultEndTime,
+		&i.AllowMultipleEntries,
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -36,8 +104,59 @@ func (q *Queries) CreateWorkplace(ctx context.Context, arg CreateWorkplaceParams
 	return i, err
 }
 
+const getDeletedWorkplaces = `-- name: GetDeletedWorkplaces :many
+select id, name, office_id, work_type, address, default_start_time, default_end_time, allow_multiple_entries, deleted_at, created_at, updated_at from workplaces
+where office_id = $1 and deleted_at >= $2
+order by deleted_at desc, id desc
+limit $3 offset $4
+`
+
+type GetDeletedWorkplacesParams struct {
+	OfficeID     int64            `json:"office_id"`
+	DeletedSince pgtype.Timestamp `json:"deleted_since"`
+	PageLimit    int32            `json:"page_limit"`
+	PageOffset   int32            `json:"page_offset"`
+}
+
+func (q *Queries) GetDeletedWorkplaces(ctx context.Context, arg GetDeletedWorkplacesParams) ([]Workplace, error) {
+	rows, err := q.db.Query(ctx, getDeletedWorkplaces,
+		arg.OfficeID,
+		arg.DeletedSince,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Workplace
+	for rows.Next() {
+		var i Workplace
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.OfficeID,
+			&i.WorkType,
+			&i.Address,
+			&i.DefaultStartTime,
+			&i.DefaultEndTime,
+			&i.AllowMultipleEntries,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getWorkplace = `-- name: GetWorkplace :one
-select id, name, office_id, work_type, deleted_at, created_at, updated_at from workplaces where id = $1 and deleted_at is null
+select id, name, office_id, work_type, address, default_start_time, default_end_time, allow_multiple_entries, deleted_at, created_at, updated_at from workplaces where id = $1 and deleted_at is null
 `
 
 func (q *Queries) GetWorkplace(ctx context.Context, id int64) (Workplace, error) {
@@ -48,6 +167,33 @@ func (q *Queries) GetWorkplace(ctx context.Context, id int64) (Workplace, error)
 		&i.Name,
 		&i.OfficeID,
 		&i.WorkType,
+		&i.Address,
+		&i.DefaultStartTime,
+		&i.DefaultEndTime,
+		&i.AllowMultipleEntries,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getWorkplaceForUpdate = `-- name: GetWorkplaceForUpdate :one
+select id, name, office_id, work_type, address, default_start_time, default_end_time, allow_multiple_entries, deleted_at, created_at, updated_at from workplaces where id = $1 and deleted_at is null for update
+`
+
+func (q *Queries) GetWorkplaceForUpdate(ctx context.Context, id int64) (Workplace, error) {
+	row := q.db.QueryRow(ctx, getWorkplaceForUpdate, id)
+	var i Workplace
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OfficeID,
+		&i.WorkType,
+		&i.Address,
+		&i.DefaultStartTime,
+		&i.DefaultEndTime,
+		&i.AllowMultipleEntries,
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -56,7 +202,7 @@ func (q *Queries) GetWorkplace(ctx context.Context, id int64) (Workplace, error)
 }
 
 const getWorkplaces = `-- name: GetWorkplaces :many
-select id, name, office_id, work_type, deleted_at, created_at, updated_at from workplaces where office_id = $1 and deleted_at is null
+select id, name, office_id, work_type, address, default_start_time, default_end_time, allow_multiple_entries, deleted_at, created_at, updated_at from workplaces where office_id = $1 and deleted_at is null
 `
 
 func (q *Queries) GetWorkplaces(ctx context.Context, officeID int64) ([]Workplace, error) {
@@ -73,6 +219,55 @@ func (q *Queries) GetWorkplaces(ctx context.Context, officeID int64) ([]Workplac
 			&i.Name,
 			&i.OfficeID,
 			&i.WorkType,
+			&i.Address,
+			&i.DefaultStartTime,
+			&i.DefaultEndTime,
+			&i.AllowMultipleEntries,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWorkplaces = `-- name: ListWorkplaces :many
+select id, name, office_id, work_type, address, default_start_time, default_end_time, allow_multiple_entries, deleted_at, created_at, updated_at from workplaces
+where office_id = $1 and deleted_at is null
+order by id
+limit $2 offset $3
+`
+
+type ListWorkplacesParams struct {
+	OfficeID   int64 `json:"office_id"`
+	PageLimit  int32 `json:"page_limit"`
+	PageOffset int32 `json:"page_offset"`
+}
+
+func (q *Queries) ListWorkplaces(ctx context.Context, arg ListWorkplacesParams) ([]Workplace, error) {
+	rows, err := q.db.Query(ctx, listWorkplaces, arg.OfficeID, arg.PageLimit, arg.PageOffset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Workplace
+	for rows.Next() {
+		var i Workplace
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.OfficeID,
+			&i.WorkType,
+			&i.Address,
+			&i.DefaultStartTime,
+			&i.DefaultEndTime,
+			&i.AllowMultipleEntries,
 			&i.DeletedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
@@ -87,6 +282,47 @@ func (q *Queries) GetWorkplaces(ctx context.Context, officeID int64) ([]Workplac
 	return items, nil
 }
 
+const purgeWorkplaces = `-- name: PurgeWorkplaces :execrows
+delete from workplaces
+where deleted_at < $1
+    and not exists (select 1 from employees where employees.workplace_id = workplaces.id)
+    and not exists (select 1 from employee_assignments where employee_assignments.workplace_id = workplaces.id)
+    and not exists (select 1 from work_entries where work_entries.workplace_id = workplaces.id)
+`
+
+func (q *Queries) PurgeWorkplaces(ctx context.Context, deletedBefore pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeWorkplaces, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreWorkplace = `-- name: RestoreWorkplace :one
+update workplaces set deleted_at = null, updated_at = now()
+where id = $1 and deleted_at is not null
+returning id, name, office_id, work_type, address, default_start_time, default_end_time, allow_multiple_entries, deleted_at, created_at, updated_at
+`
+
+func (q *Queries) RestoreWorkplace(ctx context.Context, id int64) (Workplace, error) {
+	row := q.db.QueryRow(ctx, restoreWorkplace, id)
+	var i Workplace
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OfficeID,
+		&i.WorkType,
+		&i.Address,
+		&i.DefaultStartTime,
+		&i.DefaultEndTime,
+		&i.AllowMultipleEntries,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const softDeleteWorkplace = `-- name: SoftDeleteWorkplace :exec
 update workplaces set deleted_at = now() where id = $1
 `
@@ -95,3 +331,50 @@ func (q *Queries) SoftDeleteWorkplace(ctx context.Context, id int64) error {
 	_, err := q.db.Exec(ctx, softDeleteWorkplace, id)
 	return err
 }
+
+const updateWorkplaceProfile = `-- name: UpdateWorkplaceProfile :one
+update workplaces
+set name = $2,
+    address = $3,
+    default_start_time = $4,
+    default_end_time = $5,
+    allow_multiple_entries = $6,
+    updated_at = now()
+where id = $1 and deleted_at is null
+returning id, name, office_id, work_type, address, default_start_time, default_end_time, allow_multiple_entries, deleted_at, created_at, updated_at
+`
+
+type UpdateWorkplaceProfileParams struct {
+	ID                   int64       `json:"id"`
+	Name                 string      `json:"name"`
+	Address              pgtype.Text `json:"address"`
+	DefaultStartTime     util.Clock  `json:"default_start_time"`
+	DefaultEndTime       util.Clock  `json:"default_end_time"`
+	AllowMultipleEntries bool        `json:"allow_multiple_entries"`
+}
+
+func (q *Queries) UpdateWorkplaceProfile(ctx context.Context, arg UpdateWorkplaceProfileParams) (Workplace, error) {
+	row := q.db.QueryRow(ctx, updateWorkplaceProfile,
+		arg.ID,
+		arg.Name,
+		arg.Address,
+		arg.DefaultStartTime,
+		arg.DefaultEndTime,
+		arg.AllowMultipleEntries,
+	)
+	var i Workplace
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OfficeID,
+		&i.WorkType,
+		&i.Address,
+		&i.DefaultStartTime,
+		&i.DefaultEndTime,
+		&i.AllowMultipleEntries,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
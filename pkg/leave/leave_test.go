@@ -0,0 +1,106 @@
+package leave_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mio256/wplus-server/pkg/leave"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestSchedule(t *testing.T) {
+	grants := leave.Schedule(date("2015-04-01"), date("2024-03-31"))
+
+	var days []int
+	for _, g := range grants {
+		days = append(days, g.Days)
+	}
+	assert.Equal(t, []int{10, 11, 12, 14, 16, 18, 20, 20, 20}, days)
+	assert.Equal(t, date("2015-10-01"), grants[0].GrantedOn)
+	assert.Equal(t, date("2017-09-30"), grants[0].ExpiresOn)
+	assert.Equal(t, date("2023-10-01"), grants[8].GrantedOn)
+
+	assert.Empty(t, leave.Schedule(date("2024-01-01"), date("2024-06-30")))
+
+	// the end of a shorter month
+	grants = leave.Schedule(date("2023-08-31"), date("2024-03-01"))
+	require.Len(t, grants, 1)
+	assert.Equal(t, date("2024-02-29"), grants[0].GrantedOn)
+}
+
+func TestCalculate(t *testing.T) {
+	grants := []leave.Grant{
+		{GrantedOn: date("2022-10-01"), ExpiresOn: date("2024-09-30"), Days: 10},
+		{GrantedOn: date("2023-10-01"), ExpiresOn: date("2025-09-30"), Days: 11},
+	}
+
+	tests := map[string]struct {
+		Taken         []string
+		On            string
+		WantAvailable int
+		WantUsed      []int
+		WantOverdrawn int
+	}{
+		"none": {
+			On:            "2024-04-01",
+			WantAvailable: 21,
+			WantUsed:      []int{0, 0},
+		},
+		"oldest-first": {
+			Taken:         []string{"2023-11-01", "2024-01-10", "2024-01-11"},
+			On:            "2024-04-01",
+			WantAvailable: 18,
+			WantUsed:      []int{3, 0},
+		},
+		"after-the-oldest-runs-out": {
+			Taken: []string{
+				"2023-01-10", "2023-01-11", "2023-01-12", "2023-01-13", "2023-01-16",
+				"2023-01-17", "2023-01-18", "2023-01-19", "2023-01-20", "2023-01-23",
+				"2024-01-10",
+			},
+			On:            "2024-04-01",
+			WantAvailable: 10,
+			WantUsed:      []int{10, 1},
+		},
+		"expired": {
+			Taken:         []string{"2023-11-01"},
+			On:            "2024-10-01",
+			WantAvailable: 11,
+			WantUsed:      []int{1, 0},
+		},
+		"before-the-first-grant": {
+			Taken:         []string{"2022-09-01"},
+			On:            "2022-10-01",
+			WantAvailable: 10,
+			WantUsed:      []int{0, 0},
+			WantOverdrawn: 1,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var taken []time.Time
+			for _, d := range tt.Taken {
+				taken = append(taken, date(d))
+			}
+			res := leave.Calculate(grants, taken, date(tt.On))
+			assert.Equal(t, tt.WantAvailable, res.Available)
+			assert.Equal(t, tt.WantOverdrawn, res.Overdrawn)
+			var used []int
+			for _, u := range res.Grants {
+				used = append(used, u.Used)
+			}
+			assert.Equal(t, tt.WantUsed, used)
+		})
+	}
+}
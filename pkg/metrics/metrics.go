@@ -0,0 +1,132 @@
+// Package metrics collects the Prometheus metrics of the server.
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "wplus"
+
+// The kinds of export.
+const (
+	ExportWorkplaceMonth = "workplace_month"
+	ExportAuditLogsCSV   = "audit_logs_csv"
+)
+
+// unmatchedRoute labels the requests that match no route, so that random paths do not add series.
+const unmatchedRoute = "unmatched"
+
+// Metrics are the collectors of one application, in a registry of their own.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	logins          *prometheus.CounterVec
+	exportDuration  *prometheus.HistogramVec
+	exportSize      *prometheus.HistogramVec
+}
+
+// New registers the metrics of the server, the pool, the Go runtime and the process.
+func New(pool *pgxpool.Pool) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Requests by route, method and status.",
+		}, []string{"route", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time to answer requests by route, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "logins_total",
+			Help:      "Login attempts by result, success or failure.",
+		}, []string{"result"}),
+		exportDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "export_duration_seconds",
+			Help:      "Time to build exports by kind.",
+			Buckets:   []float64{.1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"kind"}),
+		exportSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "export_size_bytes",
+			Help:      "Size of exports by kind.",
+			Buckets:   prometheus.ExponentialBuckets(1<<10, 4, 8), // 1KiB to 16MiB
+		}, []string{"kind"}),
+	}
+	m.registry.MustRegister(
+		m.requests,
+		m.requestDuration,
+		m.logins,
+		m.exportDuration,
+		m.exportSize,
+		newPoolCollector(pool),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Middleware counts the requests and their durations by route and status.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		m.requests.WithLabelValues(route, c.Request.Method, status).Inc()
+		m.requestDuration.WithLabelValues(route, c.Request.Method, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveLogin counts a login attempt.
+func (m *Metrics) ObserveLogin(ok bool) {
+	result := "failure"
+	if ok {
+		result = "success"
+	}
+	m.logins.WithLabelValues(result).Inc()
+}
+
+// ObserveExport records the duration and the size of a finished export.
+func (m *Metrics) ObserveExport(kind string, d time.Duration, size int) {
+	m.exportDuration.WithLabelValues(kind).Observe(d.Seconds())
+	m.exportSize.WithLabelValues(kind).Observe(float64(size))
+}
+
+// Handler serves the metrics in the exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Protect lets only requests with the bearer token reach h.
+func Protect(h http.Handler, token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
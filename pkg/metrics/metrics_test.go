@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	// the pool connects lazily, so nothing listens here
+	pool, err := pgxpool.New(context.Background(), "postgres://u:p@127.0.0.1:1/db")
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	m := New(pool)
+
+	r := gin.New()
+	r.Use(m.Middleware())
+	r.GET("/items/:id/", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r.GET("/metrics", Protect(m.Handler(), "scrape-token"))
+	for _, path := range []string{"/items/1/", "/items/2/", "/random"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	m.ObserveLogin(true)
+	m.ObserveLogin(false)
+	m.ObserveLogin(false)
+	m.ObserveExport(ExportWorkplaceMonth, 2*time.Second, 4096)
+
+	scrape := func(token string) (int, string) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		r.ServeHTTP(w, req)
+		b, err := io.ReadAll(w.Body)
+		require.NoError(t, err)
+		return w.Code, string(b)
+	}
+
+	code, _ := scrape("")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = scrape("wrong")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, body := scrape("scrape-token")
+	require.Equal(t, http.StatusOK, code)
+	for _, want := range []string{
+		`wplus_http_requests_total{method="GET",route="/items/:id/",status="204"} 2`,
+		`wplus_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`wplus_http_request_duration_seconds_count{method="GET",route="/items/:id/",status="204"} 2`,
+		`wplus_logins_total{result="failure"} 2`,
+		`wplus_logins_total{result="success"} 1`,
+		`wplus_export_duration_seconds_sum{kind="workplace_month"} 2`,
+		`wplus_export_size_bytes_bucket{kind="workplace_month",le="4096"} 1`,
+		`wplus_db_pool_max_conns `,
+		`wplus_db_pool_acquired_conns 0`,
+		`go_goroutines `,
+	} {
+		assert.Contains(t, body, want)
+	}
+}
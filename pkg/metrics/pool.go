@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector reads the statistics of the pool on every scrape.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns       *prometheus.Desc
+	idleConns           *prometheus.Desc
+	constructingConns   *prometheus.Desc
+	totalConns          *prometheus.Desc
+	maxConns            *prometheus.Desc
+	acquires            *prometheus.Desc
+	acquireDuration     *prometheus.Desc
+	emptyAcquires       *prometheus.Desc
+	canceledAcquires    *prometheus.Desc
+	newConns            *prometheus.Desc
+	maxLifetimeDestroys *prometheus.Desc
+	maxIdleTimeDestroys *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+	return &poolCollector{
+		pool:                pool,
+		acquiredConns:       desc("acquired_conns", "Connections in use."),
+		idleConns:           desc("idle_conns", "Idle connections."),
+		constructingConns:   desc("constructing_conns", "Connections being opened."),
+		totalConns:          desc("total_conns", "Open connections."),
+		maxConns:            desc("max_conns", "Maximum connections of the pool."),
+		acquires:            desc("acquires_total", "Connections acquired."),
+		acquireDuration:     desc("acquire_duration_seconds_total", "Time spent acquiring connections."),
+		emptyAcquires:       desc("empty_acquires_total", "Acquires that waited because the pool was empty."),
+		canceledAcquires:    desc("canceled_acquires_total", "Acquires canceled by their context."),
+		newConns:            desc("new_conns_total", "Connections opened."),
+		maxLifetimeDestroys: desc("max_lifetime_destroys_total", "Connections closed for their lifetime."),
+		maxIdleTimeDestroys: desc("max_idle_time_destroys_total", "Connections closed for their idle time."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+	gauge := func(d *prometheus.Desc, v int32) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, float64(v))
+	}
+	counter := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v)
+	}
+	gauge(c.acquiredConns, s.AcquiredConns())
+	gauge(c.idleConns, s.IdleConns())
+	gauge(c.constructingConns, s.ConstructingConns())
+	gauge(c.totalConns, s.TotalConns())
+	gauge(c.maxConns, s.MaxConns())
+	counter(c.acquires, float64(s.AcquireCount()))
+	counter(c.acquireDuration, s.AcquireDuration().Seconds())
+	counter(c.emptyAcquires, float64(s.EmptyAcquireCount()))
+	counter(c.canceledAcquires, float64(s.CanceledAcquireCount()))
+	counter(c.newConns, float64(s.NewConnsCount()))
+	counter(c.maxLifetimeDestroys, float64(s.MaxLifetimeDestroyCount()))
+	counter(c.maxIdleTimeDestroys, float64(s.MaxIdleDestroyCount()))
+}
@@ -0,0 +1,293 @@
+// Package migrate applies the versioned migrations of the schema and records them in schema_migrations.
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/taxio/errors"
+)
+
+// lockKey is the key of the advisory lock that keeps instances from migrating at the same time.
+const lockKey int64 = 0x77706c7573 // "wplus"
+
+// BaselineVersion is the migration of the schema that databases had before migrations were versioned.
+const BaselineVersion int64 = 1
+
+// baselineTable exists in every database that has the baseline schema.
+const baselineTable = "offices"
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load reads the migrations in dir of fsys, sorted by version. Every version needs an up and a down file.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		m := fileName.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, errors.New("invalid migration file name", errors.WithAttrs(errors.Attr("file", e.Name())))
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil || version < 1 {
+			return nil, errors.New("invalid migration version", errors.WithAttrs(errors.Attr("file", e.Name())))
+		}
+		b, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, errors.New("migration has two names", errors.WithAttrs(errors.Attr("version", version)))
+		}
+		if m[3] == "up" {
+			mig.Up = string(b)
+		} else {
+			mig.Down = string(b)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, errors.New("migration needs up and down", errors.WithAttrs(errors.Attr("version", m.Version)))
+		}
+		migrations = append(migrations, *m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return migrations, nil
+}
+
+type Migrator struct {
+	db         *pgxpool.Pool
+	migrations []Migration
+	// Logf reports each step.
+	Logf func(format string, args ...any)
+}
+
+func New(db *pgxpool.Pool, migrations []Migration) *Migrator {
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+		Logf:       func(string, ...any) {},
+	}
+}
+
+// Status is a migration and when it was applied. Applied versions that the binary does not know have no Up and Down.
+type Status struct {
+	Migration
+	AppliedAt pgtype.Timestamp
+}
+
+// withLock runs f on one connection that holds the advisory lock, after creating schema_migrations.
+func (m *Migrator) withLock(ctx context.Context, f func(conn *pgx.Conn) error) error {
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "select pg_advisory_lock($1)", lockKey); err != nil {
+		return errors.Wrap(err)
+	}
+	defer func() {
+		// the lock is released with the session anyway, so a failure here only delays the next instance
+		if _, err := conn.Exec(context.Background(), "select pg_advisory_unlock($1)", lockKey); err != nil {
+			m.Logf("failed to release the migration lock: %v", err)
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, `create table if not exists schema_migrations (
+	version bigint primary key,
+	name varchar(255) not null,
+	applied_at timestamp not null default current_timestamp
+)`); err != nil {
+		return errors.Wrap(err)
+	}
+	return f(conn.Conn())
+}
+
+// querier is a connection or a pool.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func applied(ctx context.Context, conn querier) (map[int64]Status, error) {
+	rows, err := conn.Query(ctx, "select version, name, applied_at from schema_migrations")
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	defer rows.Close()
+	res := map[int64]Status{}
+	for rows.Next() {
+		var s Status
+		if err := rows.Scan(&s.Version, &s.Name, &s.AppliedAt); err != nil {
+			return nil, errors.Wrap(err)
+		}
+		res[s.Version] = s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return res, nil
+}
+
+// baseline records the baseline as applied to a database that has its schema but no recorded versions,
+// as databases that were set up before migrations were versioned do.
+func (m *Migrator) baseline(ctx context.Context, conn *pgx.Conn, done map[int64]Status) error {
+	if len(done) > 0 {
+		return nil
+	}
+	var exists bool
+	if err := conn.QueryRow(ctx, "select to_regclass($1) is not null", baselineTable).Scan(&exists); err != nil {
+		return errors.Wrap(err)
+	}
+	if !exists {
+		return nil
+	}
+	i := slices.IndexFunc(m.migrations, func(mig Migration) bool { return mig.Version == BaselineVersion })
+	if i < 0 {
+		return errors.New("baseline migration is missing")
+	}
+	var s Status
+	if err := conn.QueryRow(ctx, "insert into schema_migrations (version, name) values ($1, $2) returning applied_at",
+		BaselineVersion, m.migrations[i].Name).Scan(&s.AppliedAt); err != nil {
+		return errors.Wrap(err)
+	}
+	s.Migration = m.migrations[i]
+	done[BaselineVersion] = s
+	m.Logf("baselined the existing schema at version %d", BaselineVersion)
+	return nil
+}
+
+// run runs sql and records the version in one transaction.
+func run(ctx context.Context, conn *pgx.Conn, sql, record string, args ...any) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	defer util.DeferRollback(ctx, tx)
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return errors.Wrap(err)
+	}
+	if _, err := tx.Exec(ctx, record, args...); err != nil {
+		return errors.Wrap(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrap(err)
+	}
+	return nil
+}
+
+// Up applies the pending migrations in order and returns them. Each one is applied in its own transaction,
+// so a failure keeps the ones before it.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var res []Migration
+	err := m.withLock(ctx, func(conn *pgx.Conn) error {
+		done, err := applied(ctx, conn)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		if err := m.baseline(ctx, conn, done); err != nil {
+			return errors.Wrap(err)
+		}
+		for _, mig := range m.migrations {
+			if _, ok := done[mig.Version]; ok {
+				continue
+			}
+			start := time.Now()
+			if err := run(ctx, conn, mig.Up, "insert into schema_migrations (version, name) values ($1, $2)", mig.Version, mig.Name); err != nil {
+				return errors.Wrap(err, errors.WithAttrs(errors.Attr("version", mig.Version), errors.Attr("name", mig.Name)))
+			}
+			m.Logf("applied %d_%s in %s", mig.Version, mig.Name, time.Since(start).Round(time.Millisecond))
+			res = append(res, mig)
+		}
+		return nil
+	})
+	return res, err
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns them.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var res []Migration
+	err := m.withLock(ctx, func(conn *pgx.Conn) error {
+		done, err := applied(ctx, conn)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(res) < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := done[mig.Version]; !ok {
+				continue
+			}
+			if err := run(ctx, conn, mig.Down, "delete from schema_migrations where version = $1", mig.Version); err != nil {
+				return errors.Wrap(err, errors.WithAttrs(errors.Attr("version", mig.Version), errors.Attr("name", mig.Name)))
+			}
+			m.Logf("reverted %d_%s", mig.Version, mig.Name)
+			res = append(res, mig)
+		}
+		return nil
+	})
+	return res, err
+}
+
+// Status lists the known migrations and the applied versions that the binary does not know, by version.
+// It reads schema_migrations without the lock, so it answers while another instance migrates.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var exists bool
+	if err := m.db.QueryRow(ctx, "select to_regclass('schema_migrations') is not null").Scan(&exists); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	done := map[int64]Status{}
+	if exists {
+		var err error
+		if done, err = applied(ctx, m.db); err != nil {
+			return nil, errors.Wrap(err)
+		}
+	}
+
+	res := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Migration: mig}
+		if d, ok := done[mig.Version]; ok {
+			s.AppliedAt = d.AppliedAt
+			delete(done, mig.Version)
+		}
+		res = append(res, s)
+	}
+	for _, d := range done {
+		res = append(res, d)
+	}
+	slices.SortFunc(res, func(a, b Status) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return res, nil
+}
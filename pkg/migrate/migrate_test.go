@@ -0,0 +1,235 @@
+package migrate_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mio256/wplus-server/db"
+	"github.com/mio256/wplus-server/pkg/config"
+	"github.com/mio256/wplus-server/pkg/migrate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	migrations, err := migrate.Load(db.Migrations, "migrations")
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	for i, m := range migrations {
+		assert.Equal(t, int64(i+1), m.Version, "versions are contiguous")
+	}
+	assert.Equal(t, migrate.BaselineVersion, migrations[0].Version)
+
+	tests := map[string]struct {
+		Files   fstest.MapFS
+		WantErr bool
+	}{
+		"sorted": {
+			Files: fstest.MapFS{
+				"m/0002_b.up.sql":   {Data: []byte("b")},
+				"m/0002_b.down.sql": {Data: []byte("-b")},
+				"m/0001_a.up.sql":   {Data: []byte("a")},
+				"m/0001_a.down.sql": {Data: []byte("-a")},
+			},
+		},
+		"no-down": {
+			Files:   fstest.MapFS{"m/0001_a.up.sql": {Data: []byte("a")}},
+			WantErr: true,
+		},
+		"two-names": {
+			Files: fstest.MapFS{
+				"m/0001_a.up.sql":   {Data: []byte("a")},
+				"m/0001_b.down.sql": {Data: []byte("-b")},
+			},
+			WantErr: true,
+		},
+		"invalid-name": {
+			Files:   fstest.MapFS{"m/a.sql": {Data: []byte("a")}},
+			WantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			migrations, err := migrate.Load(tt.Files, "m")
+			if tt.WantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, migrations, 2)
+			assert.Equal(t, "a", migrations[0].Up)
+			assert.Equal(t, "-b", migrations[1].Down)
+		})
+	}
+}
+
+// schemaPool connects to a new schema of the test database, which is dropped when the test ends.
+func schemaPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	ctx := context.Background()
+
+	cfg, err := config.FromEnv()
+	require.NoError(t, err)
+	pc, err := cfg.Database.PoolConfig()
+	require.NoError(t, err)
+	admin, err := pgxpool.NewWithConfig(ctx, pc)
+	require.NoError(t, err)
+	t.Cleanup(admin.Close)
+
+	schema := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+	_, err = admin.Exec(ctx, "create schema "+schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := admin.Exec(ctx, "drop schema "+schema+" cascade")
+		require.NoError(t, err)
+	})
+
+	pc, err = cfg.Database.PoolConfig()
+	require.NoError(t, err)
+	pc.ConnConfig.RuntimeParams["search_path"] = schema
+	pool, err := pgxpool.NewWithConfig(ctx, pc)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	migrations, err := migrate.Load(db.Migrations, "migrations")
+	require.NoError(t, err)
+
+	t.Run("up-down", func(t *testing.T) {
+		m := migrate.New(schemaPool(t), migrations)
+
+		applied, err := m.Up(ctx)
+		require.NoError(t, err)
+		assert.Len(t, applied, len(migrations))
+		applied, err = m.Up(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+
+		statuses, err := m.Status(ctx)
+		require.NoError(t, err)
+		require.Len(t, statuses, len(migrations))
+		for _, s := range statuses {
+			assert.True(t, s.AppliedAt.Valid)
+		}
+
+		reverted, err := m.Down(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, reverted, 1)
+		assert.Equal(t, migrations[len(migrations)-1].Version, reverted[0].Version)
+		statuses, err = m.Status(ctx)
+		require.NoError(t, err)
+		assert.False(t, statuses[len(statuses)-1].AppliedAt.Valid)
+
+		// every migration reverts cleanly
+		reverted, err = m.Down(ctx, len(migrations))
+		require.NoError(t, err)
+		assert.Len(t, reverted, len(migrations)-1)
+		applied, err = m.Up(ctx)
+		require.NoError(t, err)
+		assert.Len(t, applied, len(migrations))
+	})
+
+	t.Run("baseline", func(t *testing.T) {
+		pool := schemaPool(t)
+		// a database set up with psqldef from core.sql before migrations were versioned, with its data
+		schema, err := os.ReadFile("testdata/core_before_migrations.sql")
+		require.NoError(t, err)
+		_, err = pool.Exec(ctx, string(schema))
+		require.NoError(t, err)
+		var employeeID int64
+		require.NoError(t, pool.QueryRow(ctx, `
+with office as (insert into offices (name) values ('office') returning id),
+    workplace as (insert into workplaces (name, office_id, work_type) select 'workplace', id, 'hours' from office returning id)
+insert into employees (name, workplace_id) select 'employee', id from workplace returning id`).Scan(&employeeID))
+
+		m := migrate.New(pool, migrations)
+		applied, err := m.Up(ctx)
+		require.NoError(t, err)
+		assert.Len(t, applied, len(migrations)-1)
+		statuses, err := m.Status(ctx)
+		require.NoError(t, err)
+		assert.True(t, statuses[0].AppliedAt.Valid)
+
+		// the rows get the defaults of the columns added since
+		var employmentType string
+		require.NoError(t, pool.QueryRow(ctx, "select employment_type from employees where id = $1", employeeID).Scan(&employmentType))
+		assert.Equal(t, "full_time", employmentType)
+		var timeZone string
+		require.NoError(t, pool.QueryRow(ctx, "select time_zone from offices").Scan(&timeZone))
+		assert.Equal(t, "Asia/Tokyo", timeZone)
+	})
+
+	t.Run("duplicate-users", func(t *testing.T) {
+		pool := schemaPool(t)
+		_, err := pool.Exec(ctx, migrations[0].Up)
+		require.NoError(t, err)
+		// two users of one employee, which the unique index of 0003 forbids
+		var employeeID int64
+		require.NoError(t, pool.QueryRow(ctx, `
+with office as (insert into offices (name) values ('office') returning id),
+    workplace as (insert into workplaces (name, office_id, work_type) select 'workplace', id, 'hours' from office returning id)
+insert into employees (name, workplace_id) select 'employee', id from workplace returning id`).Scan(&employeeID))
+		_, err = pool.Exec(ctx, `
+insert into users (id, office_id, name, password, role, employee_id)
+select n, office_id, 'user', 'password', 'employee', $1
+from generate_series(1, 2) as n, (select id as office_id from offices) as o`, employeeID)
+		require.NoError(t, err)
+
+		_, err = migrate.New(pool, migrations).Up(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), fmt.Sprintf("employees %d have more than one user", employeeID))
+		statuses, err := migrate.New(pool, migrations).Status(ctx)
+		require.NoError(t, err)
+		assert.True(t, statuses[1].AppliedAt.Valid)
+		assert.False(t, statuses[2].AppliedAt.Valid)
+	})
+
+	t.Run("status-while-migrating", func(t *testing.T) {
+		pool := schemaPool(t)
+		// another instance holds the lock of the migrations
+		conn, err := pool.Acquire(ctx)
+		require.NoError(t, err)
+		defer conn.Release()
+		_, err = conn.Exec(ctx, "select pg_advisory_lock($1)", int64(0x77706c7573))
+		require.NoError(t, err)
+		defer conn.Exec(ctx, "select pg_advisory_unlock($1)", int64(0x77706c7573))
+
+		statuses, err := migrate.New(pool, migrations).Status(ctx)
+		require.NoError(t, err)
+		require.Len(t, statuses, len(migrations))
+		for _, s := range statuses {
+			assert.False(t, s.AppliedAt.Valid)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		pool := schemaPool(t)
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		total := 0
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				applied, err := migrate.New(pool, migrations).Up(ctx)
+				assert.NoError(t, err)
+				mu.Lock()
+				total += len(applied)
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, len(migrations), total)
+	})
+}
@@ -0,0 +1,206 @@
+// Package overtime categorizes worked time under the Japanese Labor Standards Act.
+package overtime
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const day = 24 * time.Hour
+
+// Rules are the thresholds of an office.
+type Rules struct {
+	// DailyLimit is the statutory working time of a day (8 hours).
+	DailyLimit time.Duration
+	// WeeklyLimit is the statutory working time of a week (40 hours).
+	WeeklyLimit time.Duration
+	// NightStart and NightEnd are the late-night hours as time since midnight (22:00 to 05:00).
+	NightStart time.Duration
+	NightEnd   time.Duration
+	// LegalHoliday is the weekly day off required by law (法定休日).
+	LegalHoliday time.Weekday
+	// WeekStart is the first day of the week the weekly limit applies to.
+	WeekStart time.Weekday
+}
+
+// DefaultRules returns the statutory rules with Sunday as the legal holiday.
+func DefaultRules() Rules {
+	return Rules{
+		DailyLimit:   8 * time.Hour,
+		WeeklyLimit:  40 * time.Hour,
+		NightStart:   22 * time.Hour,
+		NightEnd:     5 * time.Hour,
+		LegalHoliday: time.Sunday,
+		WeekStart:    time.Sunday,
+	}
+}
+
+// WeekStartOf returns the first day of the week that date belongs to.
+// Entries from that day on are needed to apply the weekly limit to date.
+func (r Rules) WeekStartOf(date time.Time) time.Time {
+	return date.AddDate(0, 0, -int((date.Weekday()-r.WeekStart+7)%7))
+}
+
+// Entry is the worked time of a work entry.
+type Entry struct {
+	// Date is the business day the work started on, at midnight UTC.
+	Date time.Time
+	// Start and End are set for entries with clock times. End before Start means the work ran past midnight.
+	Start, End time.Duration
+	Timed      bool
+	// Worked is set for entries with hours only.
+	Worked time.Duration
+}
+
+// NewEntry returns the worked time of a work entry. Attendance-only entries have no worked time.
+func NewEntry(date pgtype.Date, hours pgtype.Int2, start, end pgtype.Time) Entry {
+	e := Entry{Date: date.Time}
+	if start.Valid && end.Valid {
+		e.Timed = true
+		e.Start = time.Duration(start.Microseconds) * time.Microsecond
+		e.End = time.Duration(end.Microseconds) * time.Microsecond
+	} else if hours.Valid {
+		e.Worked = time.Duration(hours.Int16) * time.Hour
+	}
+	return e
+}
+
+func (e Entry) worked() time.Duration {
+	if !e.Timed {
+		return e.Worked
+	}
+	end := e.End
+	if end <= e.Start {
+		end += day
+	}
+	return end - e.Start
+}
+
+// lateNight returns the part of the entry that falls in the late-night hours.
+func (e Entry) lateNight(r Rules) time.Duration {
+	if !e.Timed {
+		return 0
+	}
+	start, end := e.Start, e.End
+	if end <= start {
+		end += day
+	}
+	nightEnd := r.NightEnd
+	if nightEnd <= r.NightStart {
+		nightEnd += day
+	}
+	var total time.Duration
+	// the work spans at most two days, so the nights starting the day before, on the day and the day after cover it
+	for k := -1; k <= 1; k++ {
+		offset := time.Duration(k) * day
+		total += overlap(start, end, r.NightStart+offset, nightEnd+offset)
+	}
+	return total
+}
+
+func overlap(s1, e1, s2, e2 time.Duration) time.Duration {
+	s, e := max(s1, s2), min(e1, e2)
+	if e <= s {
+		return 0
+	}
+	return e - s
+}
+
+// Minutes are worked minutes by category. LateNight overlaps with the other categories,
+// and Worked is the sum of Regular, DailyOvertime, WeeklyOvertime and LegalHoliday.
+type Minutes struct {
+	Worked         int `json:"worked"`
+	Regular        int `json:"regular"`
+	DailyOvertime  int `json:"daily_overtime"`
+	WeeklyOvertime int `json:"weekly_overtime"`
+	LateNight      int `json:"late_night"`
+	LegalHoliday   int `json:"legal_holiday"`
+}
+
+// Overtime returns the minutes beyond the daily and weekly limits.
+func (m Minutes) Overtime() int {
+	return m.DailyOvertime + m.WeeklyOvertime
+}
+
+func (m *Minutes) add(o Minutes) {
+	m.Worked += o.Worked
+	m.Regular += o.Regular
+	m.DailyOvertime += o.DailyOvertime
+	m.WeeklyOvertime += o.WeeklyOvertime
+	m.LateNight += o.LateNight
+	m.LegalHoliday += o.LegalHoliday
+}
+
+// Day is the categorized time of a business day.
+type Day struct {
+	Date time.Time
+	Minutes
+}
+
+// Result is the categorized time of an employee for a period.
+type Result struct {
+	Days  []Day
+	Total Minutes
+}
+
+// Calculate categorizes the entries of an employee for the days from and to, inclusive.
+// Entries before from are only used to apply the weekly limit, so pass them from r.WeekStartOf(from).
+func Calculate(r Rules, entries []Entry, from, to time.Time) Result {
+	type daily struct {
+		worked, night time.Duration
+	}
+	days := map[time.Time]*daily{}
+	var dates []time.Time
+	for _, e := range entries {
+		d, ok := days[e.Date]
+		if !ok {
+			d = &daily{}
+			days[e.Date] = d
+			dates = append(dates, e.Date)
+		}
+		d.worked += e.worked()
+		d.night += e.lateNight(r)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	var res Result
+	var week time.Time
+	var weekRegular time.Duration
+	for _, date := range dates {
+		d := days[date]
+		if ws := r.WeekStartOf(date); !ws.Equal(week) {
+			week, weekRegular = ws, 0
+		}
+
+		var m Minutes
+		m.Worked = minutes(d.worked)
+		m.LateNight = minutes(d.night)
+		if date.Weekday() == r.LegalHoliday {
+			// work on the legal holiday is counted apart from the daily and weekly limits
+			m.LegalHoliday = m.Worked
+		} else {
+			regular := min(d.worked, r.DailyLimit)
+			m.DailyOvertime = minutes(d.worked - regular)
+			if weekRegular+regular > r.WeeklyLimit {
+				weekly := weekRegular + regular - max(r.WeeklyLimit, weekRegular)
+				m.WeeklyOvertime = minutes(weekly)
+				regular -= weekly
+			}
+			weekRegular += regular
+			m.Regular = m.Worked - m.DailyOvertime - m.WeeklyOvertime
+		}
+
+		if date.Before(from) || date.After(to) {
+			continue
+		}
+		res.Days = append(res.Days, Day{Date: date, Minutes: m})
+		res.Total.add(m)
+	}
+	return res
+}
+
+func minutes(d time.Duration) int {
+	return int(d / time.Minute)
+}
@@ -0,0 +1,138 @@
+package overtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mio256/wplus-server/pkg/overtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func timed(d string, start, end time.Duration) overtime.Entry {
+	return overtime.Entry{Date: date(d), Start: start, End: end, Timed: true}
+}
+
+func hours(d string, h int) overtime.Entry {
+	return overtime.Entry{Date: date(d), Worked: time.Duration(h) * time.Hour}
+}
+
+func TestCalculate(t *testing.T) {
+	h := time.Hour
+	tests := map[string]struct {
+		Entries  []overtime.Entry
+		From, To string
+		Want     overtime.Minutes
+	}{
+		"regular": {
+			// 2024-04-01 is a Monday
+			Entries: []overtime.Entry{timed("2024-04-01", 9*h, 17*h)},
+			From:    "2024-04-01",
+			To:      "2024-04-30",
+			Want:    overtime.Minutes{Worked: 480, Regular: 480},
+		},
+		"daily": {
+			Entries: []overtime.Entry{timed("2024-04-01", 9*h, 19*h+30*time.Minute)},
+			From:    "2024-04-01",
+			To:      "2024-04-30",
+			Want:    overtime.Minutes{Worked: 630, Regular: 480, DailyOvertime: 150},
+		},
+		"daily-split-entries": {
+			Entries: []overtime.Entry{timed("2024-04-01", 9*h, 13*h), timed("2024-04-01", 14*h, 20*h)},
+			From:    "2024-04-01",
+			To:      "2024-04-30",
+			Want:    overtime.Minutes{Worked: 600, Regular: 480, DailyOvertime: 120},
+		},
+		"weekly": {
+			// Monday to Saturday, 7 hours each
+			Entries: []overtime.Entry{
+				hours("2024-04-01", 7), hours("2024-04-02", 7), hours("2024-04-03", 7),
+				hours("2024-04-04", 7), hours("2024-04-05", 7), hours("2024-04-06", 7),
+			},
+			From: "2024-04-01",
+			To:   "2024-04-30",
+			Want: overtime.Minutes{Worked: 2520, Regular: 2400, WeeklyOvertime: 120},
+		},
+		"weekly-excludes-daily": {
+			// 10 hours a day for five days: the daily overtime does not count towards the weekly limit
+			Entries: []overtime.Entry{
+				hours("2024-04-01", 10), hours("2024-04-02", 10), hours("2024-04-03", 10),
+				hours("2024-04-04", 10), hours("2024-04-05", 10), hours("2024-04-06", 2),
+			},
+			From: "2024-04-01",
+			To:   "2024-04-30",
+			Want: overtime.Minutes{Worked: 3120, Regular: 2400, DailyOvertime: 600, WeeklyOvertime: 120},
+		},
+		"weekly-before-period": {
+			// the week starts on Sunday 2024-03-31, before the period
+			Entries: []overtime.Entry{
+				hours("2024-03-25", 8), // previous week
+				hours("2024-04-01", 8), hours("2024-04-02", 8), hours("2024-04-03", 8),
+				hours("2024-04-04", 8), hours("2024-04-05", 8), hours("2024-04-06", 8),
+			},
+			From: "2024-04-06",
+			To:   "2024-04-30",
+			Want: overtime.Minutes{Worked: 480, WeeklyOvertime: 480},
+		},
+		"late-night": {
+			Entries: []overtime.Entry{timed("2024-04-01", 20*h, 23*h)},
+			From:    "2024-04-01",
+			To:      "2024-04-30",
+			Want:    overtime.Minutes{Worked: 180, Regular: 180, LateNight: 60},
+		},
+		"late-night-past-midnight": {
+			Entries: []overtime.Entry{timed("2024-04-01", 21*h, 6*h)},
+			From:    "2024-04-01",
+			To:      "2024-04-30",
+			Want:    overtime.Minutes{Worked: 540, Regular: 480, DailyOvertime: 60, LateNight: 420},
+		},
+		"late-night-early-morning": {
+			Entries: []overtime.Entry{timed("2024-04-01", 4*h, 9*h)},
+			From:    "2024-04-01",
+			To:      "2024-04-30",
+			Want:    overtime.Minutes{Worked: 300, Regular: 300, LateNight: 60},
+		},
+		"legal-holiday": {
+			// 2024-04-07 is a Sunday
+			Entries: []overtime.Entry{timed("2024-04-07", 9*h, 19*h)},
+			From:    "2024-04-01",
+			To:      "2024-04-30",
+			Want:    overtime.Minutes{Worked: 600, LegalHoliday: 600},
+		},
+		"out-of-period": {
+			Entries: []overtime.Entry{hours("2024-05-01", 8)},
+			From:    "2024-04-01",
+			To:      "2024-04-30",
+			Want:    overtime.Minutes{},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			res := overtime.Calculate(overtime.DefaultRules(), tt.Entries, date(tt.From), date(tt.To))
+			assert.Equal(t, tt.Want, res.Total)
+			assert.Equal(t, tt.Want.Worked, tt.Want.Regular+tt.Want.DailyOvertime+tt.Want.WeeklyOvertime+tt.Want.LegalHoliday)
+		})
+	}
+}
+
+func TestCalculateRules(t *testing.T) {
+	r := overtime.DefaultRules()
+	r.DailyLimit = 7 * time.Hour
+	r.LegalHoliday = time.Saturday
+	r.WeekStart = time.Monday
+
+	entries := []overtime.Entry{hours("2024-04-01", 8), hours("2024-04-06", 4)}
+	res := overtime.Calculate(r, entries, date("2024-04-01"), date("2024-04-07"))
+	assert.Equal(t, overtime.Minutes{Worked: 720, Regular: 420, DailyOvertime: 60, LegalHoliday: 240}, res.Total)
+	assert.Len(t, res.Days, 2)
+	assert.Equal(t, date("2024-04-01"), r.WeekStartOf(date("2024-04-07")))
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// busyRetryAfter is the wait suggested to the requests turned away by a full Concurrency. Exports take seconds.
+const busyRetryAfter = 5 * time.Second
+
+// Concurrency caps the requests that run at once, in total and for every key.
+type Concurrency struct {
+	total, perKey int
+
+	mu      sync.Mutex
+	running int
+	byKey   map[string]int
+}
+
+func NewConcurrency(total, perKey int) *Concurrency {
+	return &Concurrency{total: total, perKey: perKey, byKey: map[string]int{}}
+}
+
+// Acquire takes a slot for key without waiting. The slot is given back by calling release.
+func (cc *Concurrency) Acquire(key string) (release func(), ok bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.running >= cc.total || cc.byKey[key] >= cc.perKey {
+		return nil, false
+	}
+	cc.running++
+	cc.byKey[key]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cc.mu.Lock()
+			defer cc.mu.Unlock()
+			cc.running--
+			if cc.byKey[key]--; cc.byKey[key] == 0 {
+				delete(cc.byKey, key)
+			}
+		})
+	}, true
+}
+
+// Middleware rejects the requests that find no slot, and holds the slot of the others until they are answered.
+func (cc *Concurrency) Middleware(key Key) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		release, ok := cc.Acquire(key(c))
+		if !ok {
+			tooManyRequests(c, busyRetryAfter)
+			return
+		}
+		defer release()
+		c.Next()
+	}
+}
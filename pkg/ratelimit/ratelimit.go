@@ -0,0 +1,108 @@
+// Package ratelimit turns away the clients that send too much with 429 Too Many Requests and a Retry-After.
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mio256/wplus-server/pkg/config"
+	"golang.org/x/time/rate"
+)
+
+// Key picks the client of a request whose rate is limited, e.g. its address.
+type Key func(c *gin.Context) string
+
+// Limiter keeps a token bucket for every client, which holds Count requests and refills over Period.
+type Limiter struct {
+	rate config.Rate
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+type bucket struct {
+	limiter *rate.Limiter
+	seen    time.Time
+}
+
+// New returns a limiter of r, or nil, which lets everything through, when r is no limit.
+func New(r config.Rate) *Limiter {
+	if r.Count == 0 {
+		return nil
+	}
+	return &Limiter{rate: r, buckets: map[string]*bucket{}}
+}
+
+// Allow takes a request of key from its bucket at now. When the bucket is empty, it returns how long until it is not.
+func (l *Limiter) Allow(key string, now time.Time) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(rate.Every(l.rate.Period/time.Duration(l.rate.Count)), l.rate.Count)}
+		l.buckets[key] = b
+	}
+	b.seen = now
+
+	r := b.limiter.ReserveN(now, 1)
+	if d := r.DelayFrom(now); d > 0 {
+		r.CancelAt(now)
+		return false, d
+	}
+	return true, 0
+}
+
+// sweep drops the buckets that have refilled, at most once a period, so that the clients that left do not pile up.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < l.rate.Period {
+		return
+	}
+	l.swept = now
+	for key, b := range l.buckets {
+		if now.Sub(b.seen) >= l.rate.Period {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Middleware rejects the requests of the clients that go over the rate.
+func (l *Limiter) Middleware(key Key) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ok, wait := l.Allow(key(c), time.Now()); !ok {
+			tooManyRequests(c, wait)
+		}
+	}
+}
+
+// IP keys requests by the address of the client.
+func IP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// tooManyRequests aborts with the number of seconds to wait, rounded up.
+func tooManyRequests(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	c.AbortWithStatus(http.StatusTooManyRequests)
+}
+
+// MaxBody rejects the requests that announce a body over n bytes with 413, and cuts the others at n bytes,
+// which fails their binding.
+func MaxBody(n int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > n {
+			c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, n)
+	}
+}
@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mio256/wplus-server/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	l := New(config.Rate{Count: 3, Period: time.Minute})
+	now := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("a", now)
+		assert.True(t, ok)
+	}
+	ok, wait := l.Allow("a", now)
+	assert.False(t, ok)
+	assert.Equal(t, 20*time.Second, wait)
+	// the rejected request took nothing
+	ok, wait = l.Allow("a", now.Add(5*time.Second))
+	assert.False(t, ok)
+	assert.Equal(t, 15*time.Second, wait)
+
+	ok, _ = l.Allow("b", now)
+	assert.True(t, ok, "other clients have buckets of their own")
+	ok, _ = l.Allow("a", now.Add(20*time.Second))
+	assert.True(t, ok)
+
+	l.Allow("c", now.Add(2*time.Minute))
+	assert.Len(t, l.buckets, 1, "the refilled buckets are dropped")
+
+	none := New(config.Rate{})
+	assert.Nil(t, none)
+	ok, _ = none.Allow("a", now)
+	assert.True(t, ok)
+}
+
+func TestConcurrency(t *testing.T) {
+	cc := NewConcurrency(2, 1)
+
+	releaseA, ok := cc.Acquire("a")
+	require.True(t, ok)
+	_, ok = cc.Acquire("a")
+	assert.False(t, ok, "a key has one slot")
+	releaseB, ok := cc.Acquire("b")
+	require.True(t, ok)
+	_, ok = cc.Acquire("c")
+	assert.False(t, ok, "all the slots are taken")
+
+	releaseA()
+	releaseA()
+	_, ok = cc.Acquire("c")
+	assert.True(t, ok)
+	_, ok = cc.Acquire("a")
+	assert.False(t, ok, "a slot is given back once")
+	releaseB()
+	assert.Equal(t, map[string]int{"c": 1}, cc.byKey)
+}
+
+func TestMiddleware(t *testing.T) {
+	l := New(config.Rate{Count: 1, Period: time.Minute})
+	cc := NewConcurrency(1, 1)
+	held := make(chan struct{})
+	done := make(chan struct{})
+
+	r := gin.New()
+	r.Use(MaxBody(8))
+	r.POST("/limited/", l.Middleware(IP), func(c *gin.Context) {
+		if _, err := io.ReadAll(c.Request.Body); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	r.GET("/slow/", cc.Middleware(func(*gin.Context) string { return "office" }), func(c *gin.Context) {
+		close(held)
+		<-done
+		c.Status(http.StatusNoContent)
+	})
+
+	post := func(body io.Reader) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/limited/", body))
+		return w
+	}
+	assert.Equal(t, http.StatusNoContent, post(strings.NewReader("{}")).Code)
+	w := post(strings.NewReader("{}"))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post(strings.NewReader(`{"a":"long"}`)).Code)
+	// a body of unknown length is cut while it is read
+	req := httptest.NewRequest("POST", "/limited/", io.MultiReader(strings.NewReader(`{"a":"long"}`)))
+	req.ContentLength = -1
+	req.RemoteAddr = "192.0.2.2:1234"
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	go func() {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow/", nil))
+	}()
+	<-held
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/slow/", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Retry-After"))
+	close(done)
+}
@@ -0,0 +1,129 @@
+// Package server serves the router on several addresses and shuts down gracefully.
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/mio256/wplus-server/pkg/config"
+	"github.com/taxio/errors"
+)
+
+// Listen binds every address, or none when one of them fails.
+func Listen(addrs []string) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(addrs))
+	for _, addr := range addrs {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, errors.Wrap(err, errors.WithAttrs(errors.Attr("addr", addr)))
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
+// Serve serves h on the listeners until ctx is done, and then stops accepting connections and waits
+// for the requests in flight up to the shutdown timeout. It returns early when one of the listeners fails,
+// after shutting the others down.
+func Serve(ctx context.Context, cfg config.Server, h http.Handler, listeners []net.Listener) error {
+	servers := make([]*http.Server, 0, len(listeners))
+	errc := make(chan error, len(listeners))
+	for _, l := range listeners {
+		s := &http.Server{
+			Handler:           h,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		}
+		servers = append(servers, s)
+		slog.Info("listening", slog.String("addr", l.Addr().String()))
+		go func(l net.Listener) {
+			if err := s.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+				errc <- errors.Wrap(err, errors.WithAttrs(errors.Attr("addr", l.Addr().String())))
+			}
+		}(l)
+	}
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+		slog.Info("shutting down")
+	case serveErr = <-errc:
+	}
+
+	// ctx is done, so the deadline starts from a fresh context
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	// all at once, so that no server accepts connections while another one drains
+	errs := make([]error, len(servers)+1)
+	errs[0] = serveErr
+	var wg sync.WaitGroup
+	for i, s := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.Shutdown(shutdownCtx); err != nil {
+				errs[i+1] = errors.Wrap(err)
+				// cut the connections that did not finish in time
+				s.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Run listens on the addresses of cfg and serves h until ctx is done.
+func Run(ctx context.Context, cfg config.Server, h http.Handler) error {
+	listeners, err := Listen(cfg.ListenAddrs())
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	return Serve(ctx, cfg, h, listeners)
+}
+
+// RunWithInternal serves h like Run, and internal, such as the metrics, on internalAddr when it is set.
+// Every address is bound before either serves, and both stop when ctx is done or either of them fails.
+func RunWithInternal(ctx context.Context, cfg config.Server, h http.Handler, internalAddr string, internal http.Handler) error {
+	if internalAddr == "" {
+		return Run(ctx, cfg, h)
+	}
+	listeners, err := Listen(cfg.ListenAddrs())
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	internalListeners, err := Listen([]string{internalAddr})
+	if err != nil {
+		for _, l := range listeners {
+			l.Close()
+		}
+		return errors.Wrap(err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i, serve := range []func() error{
+		func() error { return Serve(ctx, cfg, h, listeners) },
+		func() error { return Serve(ctx, cfg, internal, internalListeners) },
+	} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// one stopping stops the other
+			defer cancel()
+			errs[i] = serve()
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
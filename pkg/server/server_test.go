@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mio256/wplus-server/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe(t *testing.T) {
+	cfg := config.Default().Server
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+		io.WriteString(w, "ok")
+	})
+
+	listeners, err := Listen([]string{"127.0.0.1:0", "127.0.0.1:0"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- Serve(ctx, cfg, h, listeners)
+	}()
+
+	// every address serves
+	for _, l := range listeners {
+		res, err := http.Get("http://" + l.Addr().String() + "/")
+		require.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	}
+
+	// a request in flight is finished after the shutdown begins
+	slow := make(chan *http.Response)
+	go func() {
+		res, err := http.Get("http://" + listeners[0].Addr().String() + "/slow")
+		assert.NoError(t, err)
+		slow <- res
+	}()
+	<-started
+	cancel()
+
+	select {
+	case <-done:
+		t.Fatal("returned before the request in flight finished")
+	case <-time.After(100 * time.Millisecond):
+	}
+	// no new connections are accepted
+	_, err = http.Get("http://" + listeners[1].Addr().String() + "/")
+	assert.Error(t, err)
+
+	close(release)
+	res := <-slow
+	require.NotNil(t, res)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, "ok", string(body))
+	assert.NoError(t, <-done)
+}
+
+func TestServeShutdownTimeout(t *testing.T) {
+	cfg := config.Default().Server
+	cfg.ShutdownTimeout = 50 * time.Millisecond
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	listeners, err := Listen([]string{"127.0.0.1:0"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- Serve(ctx, cfg, h, listeners)
+	}()
+	go http.Get("http://" + listeners[0].Addr().String() + "/")
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("did not give up on the request in flight")
+	}
+}
+
+func TestListen(t *testing.T) {
+	listeners, err := Listen([]string{"127.0.0.1:0"})
+	require.NoError(t, err)
+	defer listeners[0].Close()
+
+	// the second address is taken, so the first one is released
+	_, err = Listen([]string{"127.0.0.1:0", listeners[0].Addr().String()})
+	assert.Error(t, err)
+}
+
+func TestRunWithInternal(t *testing.T) {
+	cfg := config.Default().Server
+	cfg.Addrs = []string{"127.0.0.1:0"}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	taken, err := Listen([]string{"127.0.0.1:0"})
+	require.NoError(t, err)
+	defer taken[0].Close()
+
+	// the internal address is taken, so nothing serves
+	err = RunWithInternal(context.Background(), cfg, h, taken[0].Addr().String(), h)
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- RunWithInternal(ctx, cfg, h, "127.0.0.1:0", h)
+	}()
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("did not stop")
+	}
+}
@@ -0,0 +1,162 @@
+// Package shift compares planned shifts with the work that was recorded.
+package shift
+
+import (
+	"sort"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+const (
+	// KindMissingPunch is a shift without any work recorded on its day.
+	KindMissingPunch = "missing_punch"
+	// KindLateArrival is work that started after its shift.
+	KindLateArrival = "late_arrival"
+	// KindEarlyDeparture is work that ended before its shift.
+	KindEarlyDeparture = "early_departure"
+	// KindUnscheduledWork is work without a shift.
+	KindUnscheduledWork = "unscheduled_work"
+)
+
+// Planned is a shift of an employee.
+type Planned struct {
+	ID         int64
+	EmployeeID int64
+	// Date is the day the shift starts on, at midnight UTC.
+	Date time.Time
+	// Start and End are the time since midnight. End before Start means the shift runs past midnight.
+	Start, End time.Duration
+}
+
+// Actual is a work entry of an employee.
+type Actual struct {
+	ID         int64
+	EmployeeID int64
+	Date       time.Time
+	// Start and End are set for entries with clock times. Entries with hours or attendance only
+	// match any shift on their day and are not checked for late arrivals or early departures.
+	Start, End time.Duration
+	Timed      bool
+}
+
+// Finding is a difference between the plan and the record.
+type Finding struct {
+	Kind       string
+	EmployeeID int64
+	Date       time.Time
+	// ShiftID is zero for unscheduled work.
+	ShiftID int64
+	// EntryID is zero for missing punches.
+	EntryID int64
+	// Minutes is how late or early the work was.
+	Minutes int
+}
+
+func span(start, end time.Duration) (time.Duration, time.Duration) {
+	if end <= start {
+		end += day
+	}
+	return start, end
+}
+
+func overlap(s1, e1, s2, e2 time.Duration) time.Duration {
+	s, e := max(s1, s2), min(e1, e2)
+	if e <= s {
+		return 0
+	}
+	return e - s
+}
+
+type key struct {
+	employeeID int64
+	date       time.Time
+}
+
+// Compare matches the shifts of each employee and day to the entries that overlap them the most.
+// Lateness and early departure within grace are tolerated. Shifts on or after until are not reported
+// as missing, because their work may not have been recorded yet.
+func Compare(planned []Planned, actual []Actual, grace time.Duration, until time.Time) []Finding {
+	shifts := map[key][]Planned{}
+	entries := map[key][]Actual{}
+	var keys []key
+	for _, p := range planned {
+		k := key{p.EmployeeID, p.Date}
+		if _, ok := shifts[k]; !ok {
+			if _, ok := entries[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		shifts[k] = append(shifts[k], p)
+	}
+	for _, a := range actual {
+		k := key{a.EmployeeID, a.Date}
+		if _, ok := entries[k]; !ok {
+			if _, ok := shifts[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		entries[k] = append(entries[k], a)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].date.Equal(keys[j].date) {
+			return keys[i].date.Before(keys[j].date)
+		}
+		return keys[i].employeeID < keys[j].employeeID
+	})
+
+	var res []Finding
+	for _, k := range keys {
+		res = append(res, compareDay(k, shifts[k], entries[k], grace, until)...)
+	}
+	return res
+}
+
+func compareDay(k key, shifts []Planned, entries []Actual, grace time.Duration, until time.Time) []Finding {
+	var res []Finding
+	used := make([]bool, len(entries))
+	for _, p := range shifts {
+		ps, pe := span(p.Start, p.End)
+		best, bestOverlap := -1, time.Duration(-1)
+		for i, a := range entries {
+			if used[i] {
+				continue
+			}
+			o := time.Duration(0)
+			if a.Timed {
+				as, ae := span(a.Start, a.End)
+				o = overlap(ps, pe, as, ae)
+				if o == 0 {
+					continue
+				}
+			}
+			if o > bestOverlap {
+				best, bestOverlap = i, o
+			}
+		}
+		if best < 0 {
+			if k.date.Before(until) {
+				res = append(res, Finding{Kind: KindMissingPunch, EmployeeID: k.employeeID, Date: k.date, ShiftID: p.ID})
+			}
+			continue
+		}
+		used[best] = true
+		a := entries[best]
+		if !a.Timed {
+			continue
+		}
+		as, ae := span(a.Start, a.End)
+		if late := as - ps; late > grace {
+			res = append(res, Finding{Kind: KindLateArrival, EmployeeID: k.employeeID, Date: k.date, ShiftID: p.ID, EntryID: a.ID, Minutes: int(late / time.Minute)})
+		}
+		if early := pe - ae; early > grace {
+			res = append(res, Finding{Kind: KindEarlyDeparture, EmployeeID: k.employeeID, Date: k.date, ShiftID: p.ID, EntryID: a.ID, Minutes: int(early / time.Minute)})
+		}
+	}
+	for i, a := range entries {
+		if !used[i] {
+			res = append(res, Finding{Kind: KindUnscheduledWork, EmployeeID: k.employeeID, Date: k.date, EntryID: a.ID})
+		}
+	}
+	return res
+}
@@ -0,0 +1,90 @@
+package shift_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mio256/wplus-server/pkg/shift"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestCompare(t *testing.T) {
+	h := time.Hour
+	until := date("2024-04-10")
+
+	tests := map[string]struct {
+		Planned []shift.Planned
+		Actual  []shift.Actual
+		Want    []shift.Finding
+	}{
+		"on-time": {
+			Planned: []shift.Planned{{ID: 1, EmployeeID: 1, Date: date("2024-04-01"), Start: 9 * h, End: 17 * h}},
+			Actual:  []shift.Actual{{ID: 10, EmployeeID: 1, Date: date("2024-04-01"), Start: 9*h + 3*time.Minute, End: 17 * h, Timed: true}},
+		},
+		"late-and-early": {
+			Planned: []shift.Planned{{ID: 1, EmployeeID: 1, Date: date("2024-04-01"), Start: 9 * h, End: 17 * h}},
+			Actual:  []shift.Actual{{ID: 10, EmployeeID: 1, Date: date("2024-04-01"), Start: 9*h + 30*time.Minute, End: 16 * h, Timed: true}},
+			Want: []shift.Finding{
+				{Kind: shift.KindLateArrival, EmployeeID: 1, Date: date("2024-04-01"), ShiftID: 1, EntryID: 10, Minutes: 30},
+				{Kind: shift.KindEarlyDeparture, EmployeeID: 1, Date: date("2024-04-01"), ShiftID: 1, EntryID: 10, Minutes: 60},
+			},
+		},
+		"missing": {
+			Planned: []shift.Planned{
+				{ID: 1, EmployeeID: 1, Date: date("2024-04-01"), Start: 9 * h, End: 17 * h},
+				// not over yet
+				{ID: 2, EmployeeID: 1, Date: date("2024-04-10"), Start: 9 * h, End: 17 * h},
+			},
+			Want: []shift.Finding{{Kind: shift.KindMissingPunch, EmployeeID: 1, Date: date("2024-04-01"), ShiftID: 1}},
+		},
+		"unscheduled": {
+			Planned: []shift.Planned{{ID: 1, EmployeeID: 1, Date: date("2024-04-01"), Start: 9 * h, End: 12 * h}},
+			Actual: []shift.Actual{
+				{ID: 10, EmployeeID: 1, Date: date("2024-04-01"), Start: 9 * h, End: 12 * h, Timed: true},
+				{ID: 11, EmployeeID: 1, Date: date("2024-04-01"), Start: 18 * h, End: 20 * h, Timed: true},
+				{ID: 12, EmployeeID: 2, Date: date("2024-04-01"), Start: 9 * h, End: 12 * h, Timed: true},
+			},
+			Want: []shift.Finding{
+				{Kind: shift.KindUnscheduledWork, EmployeeID: 1, Date: date("2024-04-01"), EntryID: 11},
+				{Kind: shift.KindUnscheduledWork, EmployeeID: 2, Date: date("2024-04-01"), EntryID: 12},
+			},
+		},
+		"split-shifts": {
+			Planned: []shift.Planned{
+				{ID: 1, EmployeeID: 1, Date: date("2024-04-01"), Start: 9 * h, End: 12 * h},
+				{ID: 2, EmployeeID: 1, Date: date("2024-04-01"), Start: 13 * h, End: 17 * h},
+			},
+			Actual: []shift.Actual{
+				{ID: 11, EmployeeID: 1, Date: date("2024-04-01"), Start: 13 * h, End: 17 * h, Timed: true},
+				{ID: 10, EmployeeID: 1, Date: date("2024-04-01"), Start: 9 * h, End: 12 * h, Timed: true},
+			},
+		},
+		"night": {
+			Planned: []shift.Planned{{ID: 1, EmployeeID: 1, Date: date("2024-04-01"), Start: 22 * h, End: 6 * h}},
+			Actual:  []shift.Actual{{ID: 10, EmployeeID: 1, Date: date("2024-04-01"), Start: 22 * h, End: 5 * h, Timed: true}},
+			Want: []shift.Finding{
+				{Kind: shift.KindEarlyDeparture, EmployeeID: 1, Date: date("2024-04-01"), ShiftID: 1, EntryID: 10, Minutes: 60},
+			},
+		},
+		"hours-only": {
+			Planned: []shift.Planned{{ID: 1, EmployeeID: 1, Date: date("2024-04-01"), Start: 9 * h, End: 17 * h}},
+			Actual:  []shift.Actual{{ID: 10, EmployeeID: 1, Date: date("2024-04-01")}},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			res := shift.Compare(tt.Planned, tt.Actual, 5*time.Minute, until)
+			assert.Equal(t, tt.Want, res)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+// Package summary adds up the work entries of a period per employee and per workplace.
+package summary
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const day = 24 * time.Hour
+
+// Entry is a work entry.
+type Entry struct {
+	EmployeeID  int64
+	WorkplaceID int64
+	// Date is the day of the entry, at midnight UTC.
+	Date time.Time
+	// Worked is the clock span of the entry, or its hours when it has no clock times.
+	Worked time.Duration
+	// Present is set for attendance marks. Absence marks are not work.
+	Present bool
+}
+
+// NewEntry returns the worked time of a work entry. An end before the start means the work ran past midnight.
+func NewEntry(employeeID, workplaceID int64, date pgtype.Date, hours pgtype.Int2, start, end pgtype.Time, attendance pgtype.Bool) Entry {
+	e := Entry{
+		EmployeeID:  employeeID,
+		WorkplaceID: workplaceID,
+		Date:        date.Time,
+		Present:     attendance.Valid && attendance.Bool,
+	}
+	if start.Valid && end.Valid {
+		s := time.Duration(start.Microseconds) * time.Microsecond
+		t := time.Duration(end.Microseconds) * time.Microsecond
+		if t <= s {
+			t += day
+		}
+		e.Worked = t - s
+	} else if hours.Valid {
+		e.Worked = time.Duration(hours.Int16) * time.Hour
+	}
+	return e
+}
+
+// Break returns the minimum break for the time worked in a day under Article 34 of the Labor Standards Act:
+// 45 minutes over 6 hours and an hour over 8 hours.
+func Break(worked time.Duration) time.Duration {
+	switch {
+	case worked > 8*time.Hour:
+		return time.Hour
+	case worked > 6*time.Hour:
+		return 45 * time.Minute
+	}
+	return 0
+}
+
+// Totals are the figures of a period.
+type Totals struct {
+	// DaysWorked counts the days with worked time or an attendance mark, per employee.
+	DaysWorked int `json:"days_worked"`
+	// Shifts counts the entries with worked time.
+	Shifts int `json:"shifts"`
+	// TotalMinutes is the time worked and NetMinutes the same without the minimum break of each day.
+	TotalMinutes int `json:"total_minutes"`
+	NetMinutes   int `json:"net_minutes"`
+	// AttendanceCount counts the attendance marks, without absences.
+	AttendanceCount int `json:"attendance_count"`
+	// AverageShiftMinutes is TotalMinutes divided by Shifts, rounded down.
+	AverageShiftMinutes int `json:"average_shift_minutes"`
+}
+
+type key struct {
+	employeeID int64
+	date       time.Time
+}
+
+// Days returns the time worked by each employee and day. Days with only attendance marks are present with zero.
+func Days(entries []Entry) map[int64]map[time.Time]time.Duration {
+	res := map[int64]map[time.Time]time.Duration{}
+	for _, e := range entries {
+		if e.Worked == 0 && !e.Present {
+			continue
+		}
+		if res[e.EmployeeID] == nil {
+			res[e.EmployeeID] = map[time.Time]time.Duration{}
+		}
+		res[e.EmployeeID][e.Date] += e.Worked
+	}
+	return res
+}
+
+// Calculate adds up entries.
+func Calculate(entries []Entry) Totals {
+	return calculate(entries, func(_ key, worked time.Duration) time.Duration { return Break(worked) })
+}
+
+// calculate adds up entries and takes brk off the time worked by each employee and day.
+func calculate(entries []Entry, brk func(k key, worked time.Duration) time.Duration) Totals {
+	var t Totals
+	worked := map[key]time.Duration{}
+	var total time.Duration
+	for _, e := range entries {
+		if e.Present {
+			t.AttendanceCount++
+		}
+		if e.Worked > 0 {
+			t.Shifts++
+			total += e.Worked
+		}
+		if e.Worked > 0 || e.Present {
+			worked[key{e.EmployeeID, e.Date}] += e.Worked
+		}
+	}
+	net := total
+	for k, w := range worked {
+		net -= brk(k, w)
+	}
+	t.DaysWorked = len(worked)
+	t.TotalMinutes = int(total / time.Minute)
+	t.NetMinutes = int(net / time.Minute)
+	if t.Shifts > 0 {
+		t.AverageShiftMinutes = t.TotalMinutes / t.Shifts
+	}
+	return t
+}
+
+// ByEmployee adds up the entries of each employee.
+func ByEmployee(entries []Entry) map[int64]Totals {
+	return by(entries, func(e Entry) int64 { return e.EmployeeID })
+}
+
+// ByWorkplace adds up the entries of each workplace. The days worked of a workplace are the days worked of its employees.
+// The minimum break of an employee's day is split between the workplaces of the day in proportion to the time worked
+// at each, so that the net minutes of the workplaces add up to those of the employees.
+func ByWorkplace(entries []Entry) map[int64]Totals {
+	breaks := splitBreaks(entries)
+	groups := map[int64][]Entry{}
+	for _, e := range entries {
+		groups[e.WorkplaceID] = append(groups[e.WorkplaceID], e)
+	}
+	res := make(map[int64]Totals, len(groups))
+	for id, es := range groups {
+		res[id] = calculate(es, func(k key, _ time.Duration) time.Duration {
+			return breaks[share{k, id}]
+		})
+	}
+	return res
+}
+
+// share is the part of the day of an employee spent at a workplace.
+type share struct {
+	key
+	workplaceID int64
+}
+
+// splitBreaks returns the part of the minimum break of each employee and day that falls to each workplace. The parts
+// are whole minutes that add up to the break, and the rounding falls to the workplaces with the higher IDs.
+func splitBreaks(entries []Entry) map[share]time.Duration {
+	worked := map[key]map[int64]time.Duration{}
+	for _, e := range entries {
+		if e.Worked == 0 && !e.Present {
+			continue
+		}
+		k := key{e.EmployeeID, e.Date}
+		if worked[k] == nil {
+			worked[k] = map[int64]time.Duration{}
+		}
+		worked[k][e.WorkplaceID] += e.Worked
+	}
+	res := map[share]time.Duration{}
+	for k, byWorkplace := range worked {
+		var total time.Duration
+		ids := make([]int64, 0, len(byWorkplace))
+		for id, w := range byWorkplace {
+			total += w
+			ids = append(ids, id)
+		}
+		minutes := int64(Break(total) / time.Minute)
+		if minutes == 0 {
+			continue
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		// the parts are the differences of the rounded down running shares, so that they add up to the whole
+		var sum time.Duration
+		var given int64
+		for _, id := range ids {
+			sum += byWorkplace[id]
+			upTo := minutes * int64(sum) / int64(total)
+			res[share{k, id}] = time.Duration(upTo-given) * time.Minute
+			given = upTo
+		}
+	}
+	return res
+}
+
+// EmployeesByWorkplace counts the employees with worked days at each workplace.
+func EmployeesByWorkplace(entries []Entry) map[int64]int {
+	seen := map[[2]int64]bool{}
+	res := map[int64]int{}
+	for _, e := range entries {
+		k := [2]int64{e.WorkplaceID, e.EmployeeID}
+		if (e.Worked == 0 && !e.Present) || seen[k] {
+			continue
+		}
+		seen[k] = true
+		res[e.WorkplaceID]++
+	}
+	return res
+}
+
+func by(entries []Entry, id func(Entry) int64) map[int64]Totals {
+	groups := map[int64][]Entry{}
+	for _, e := range entries {
+		groups[id(e)] = append(groups[id(e)], e)
+	}
+	res := make(map[int64]Totals, len(groups))
+	for k, es := range groups {
+		res[k] = Calculate(es)
+	}
+	return res
+}
@@ -0,0 +1,133 @@
+package summary_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mio256/wplus-server/pkg/summary"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestNewEntry(t *testing.T) {
+	clock := func(h, m int) pgtype.Time {
+		return pgtype.Time{Microseconds: int64(time.Duration(h)*time.Hour+time.Duration(m)*time.Minute) / 1000, Valid: true}
+	}
+	d := pgtype.Date{Time: date("2024-04-01"), Valid: true}
+
+	tests := map[string]struct {
+		Hours      pgtype.Int2
+		Start, End pgtype.Time
+		Attendance pgtype.Bool
+		Want       summary.Entry
+	}{
+		"time": {
+			Start: clock(9, 0),
+			End:   clock(17, 30),
+			Want:  summary.Entry{Date: d.Time, Worked: 8*time.Hour + 30*time.Minute},
+		},
+		"past-midnight": {
+			Start: clock(22, 0),
+			End:   clock(5, 0),
+			Want:  summary.Entry{Date: d.Time, Worked: 7 * time.Hour},
+		},
+		"hours": {
+			Hours: pgtype.Int2{Int16: 6, Valid: true},
+			Want:  summary.Entry{Date: d.Time, Worked: 6 * time.Hour},
+		},
+		"present": {
+			Attendance: pgtype.Bool{Bool: true, Valid: true},
+			Want:       summary.Entry{Date: d.Time, Present: true},
+		},
+		"absent": {
+			Attendance: pgtype.Bool{Bool: false, Valid: true},
+			Want:       summary.Entry{Date: d.Time},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.Want, summary.NewEntry(0, 0, d, tt.Hours, tt.Start, tt.End, tt.Attendance))
+		})
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	h := time.Hour
+	entries := []summary.Entry{
+		// two shifts on the first day add up to 9 hours, so an hour of break
+		{EmployeeID: 1, WorkplaceID: 1, Date: date("2024-04-01"), Worked: 4 * h},
+		{EmployeeID: 1, WorkplaceID: 1, Date: date("2024-04-01"), Worked: 5 * h},
+		{EmployeeID: 1, WorkplaceID: 1, Date: date("2024-04-02"), Worked: 7 * h},
+		{EmployeeID: 2, WorkplaceID: 2, Date: date("2024-04-01"), Present: true},
+		{EmployeeID: 2, WorkplaceID: 2, Date: date("2024-04-02")},
+		{EmployeeID: 2, WorkplaceID: 1, Date: date("2024-04-03"), Worked: 5 * h},
+	}
+
+	assert.Equal(t, summary.Totals{
+		DaysWorked:          4,
+		Shifts:              4,
+		TotalMinutes:        21 * 60,
+		NetMinutes:          21*60 - 60 - 45,
+		AttendanceCount:     1,
+		AverageShiftMinutes: 21 * 60 / 4,
+	}, summary.Calculate(entries))
+
+	byEmployee := summary.ByEmployee(entries)
+	assert.Equal(t, 2, byEmployee[1].DaysWorked)
+	assert.Equal(t, 16*60-60-45, byEmployee[1].NetMinutes)
+	assert.Equal(t, 2, byEmployee[2].DaysWorked)
+	assert.Equal(t, 300, byEmployee[2].AverageShiftMinutes)
+
+	byWorkplace := summary.ByWorkplace(entries)
+	assert.Equal(t, 3, byWorkplace[1].DaysWorked)
+	assert.Equal(t, 21*60, byWorkplace[1].TotalMinutes)
+	assert.Equal(t, summary.Totals{DaysWorked: 1, AttendanceCount: 1}, byWorkplace[2])
+
+	assert.Equal(t, map[int64]int{1: 2, 2: 1}, summary.EmployeesByWorkplace(entries))
+
+	assert.Equal(t, map[int64]map[time.Time]time.Duration{
+		1: {date("2024-04-01"): 9 * h, date("2024-04-02"): 7 * h},
+		2: {date("2024-04-01"): 0, date("2024-04-03"): 5 * h},
+	}, summary.Days(entries))
+}
+
+func TestByWorkplace(t *testing.T) {
+	h := time.Hour
+	entries := []summary.Entry{
+		// 9 hours over two workplaces on one day: the hour of break is split 5 to 4
+		{EmployeeID: 1, WorkplaceID: 1, Date: date("2024-04-01"), Worked: 5 * h},
+		{EmployeeID: 1, WorkplaceID: 2, Date: date("2024-04-01"), Worked: 4 * h},
+		// 7 hours at each workplace on its own day
+		{EmployeeID: 1, WorkplaceID: 1, Date: date("2024-04-02"), Worked: 7 * h},
+		{EmployeeID: 1, WorkplaceID: 2, Date: date("2024-04-03"), Worked: 7 * h},
+		// no break at either workplace for 6 hours
+		{EmployeeID: 2, WorkplaceID: 1, Date: date("2024-04-01"), Worked: 3 * h},
+		{EmployeeID: 2, WorkplaceID: 2, Date: date("2024-04-01"), Worked: 3 * h},
+	}
+
+	byWorkplace := summary.ByWorkplace(entries)
+	assert.Equal(t, 15*60-33-45, byWorkplace[1].NetMinutes)
+	assert.Equal(t, 14*60-27-45, byWorkplace[2].NetMinutes)
+	assert.Equal(t, 3, byWorkplace[1].DaysWorked)
+	assert.Equal(t, 3, byWorkplace[2].DaysWorked)
+
+	byEmployee := summary.ByEmployee(entries)
+	var employees, workplaces int
+	for _, v := range byEmployee {
+		employees += v.NetMinutes
+	}
+	for _, v := range byWorkplace {
+		workplaces += v.NetMinutes
+	}
+	assert.Equal(t, employees, workplaces)
+	assert.Equal(t, summary.Calculate(entries).NetMinutes, workplaces)
+}
@@ -0,0 +1,24 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mio256/wplus-server/pkg/app"
+	"github.com/mio256/wplus-server/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+// NewApp connects the application to the test database and closes it when the test ends.
+func NewApp(t *testing.T) *app.App {
+	t.Helper()
+
+	cfg, err := config.FromEnv()
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate())
+	a, err := app.New(context.Background(), cfg)
+	require.NoError(t, err)
+	t.Cleanup(a.Close)
+
+	return a
+}
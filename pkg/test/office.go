@@ -26,6 +26,9 @@ func CreateOffice(t *testing.T, ctx context.Context, db rdb.DBTX, f func(v *rdb.
 	require.NoError(t, err)
 
 	t.Cleanup(func() {
+		require.NoError(t, rdb.New(db).TestDeleteOvertimeRules(ctx, created.ID))
+		require.NoError(t, rdb.New(db).TestDeleteOfficeClosures(ctx, created.ID))
+		require.NoError(t, rdb.New(db).TestDeleteLeaveTypes(ctx, created.ID))
 		require.NoError(t, rdb.New(db).TestDeleteOffice(ctx, created.ID))
 	})
 
@@ -41,3 +44,26 @@ func GetDeletedAtOffice(t *testing.T, ctx context.Context, db rdb.DBTX, id int64
 
 	return deletedAt.Time
 }
+
+// CreateOfficeClosure creates a closure day. It is deleted with the office.
+func CreateOfficeClosure(t *testing.T, ctx context.Context, db rdb.DBTX, f func(v *rdb.OfficeClosure)) *rdb.OfficeClosure {
+	t.Helper()
+
+	target := &rdb.OfficeClosure{
+		Name: faker.Word(),
+	}
+
+	if f != nil {
+		f(target)
+	}
+
+	created, err := rdb.New(db).CreateOfficeClosure(ctx, rdb.CreateOfficeClosureParams{
+		OfficeID: target.OfficeID,
+		Date:     target.Date,
+		Name:     target.Name,
+	})
+
+	require.NoError(t, err)
+
+	return &created
+}
@@ -45,7 +45,7 @@ func CreateUser(t *testing.T, ctx context.Context, db rdb.DBTX, f func(v *rdb.Us
 	require.NoError(t, err)
 
 	t.Cleanup(func() {
-		require.NoError(t, rdb.New(db).TestDeleteUser(ctx, created.ID))
+		require.NoError(t, rdb.New(db).TestDeleteUser(ctx, rdb.TestDeleteUserParams{ID: created.ID, OfficeID: created.OfficeID}))
 	})
 
 	return &created, target.Password
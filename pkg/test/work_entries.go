@@ -14,8 +14,10 @@ import (
 func CreateWorkEntries(t *testing.T, ctx context.Context, db rdb.DBTX, f func(v *rdb.WorkEntry)) *rdb.WorkEntry {
 	t.Helper()
 
-	employee := CreateEmployee(t, ctx, db, nil)
 	wp := CreateWorkplace(t, ctx, db, nil)
+	employee := CreateEmployee(t, ctx, db, func(v *rdb.Employee) {
+		v.WorkplaceID = wp.ID
+	})
 
 	target := &rdb.WorkEntry{
 		EmployeeID:  employee.ID,
@@ -34,6 +34,7 @@ func CreateWorkplace(t *testing.T, ctx context.Context, db rdb.DBTX, f func(v *r
 	require.NoError(t, err)
 
 	t.Cleanup(func() {
+		require.NoError(t, rdb.New(db).TestDeleteShiftTemplates(ctx, created.ID))
 		require.NoError(t, rdb.New(db).TestDeleteWorkplace(ctx, created.ID))
 	})
 
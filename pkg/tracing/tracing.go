@@ -0,0 +1,84 @@
+// Package tracing sets up OpenTelemetry and traces the requests, the queries and the exports.
+package tracing
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/mio256/wplus-server/pkg/config"
+	"github.com/taxio/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName names the server in the traces.
+const ServiceName = "wplus-server"
+
+// shutdownTimeout bounds the export of the spans that are left on shutdown.
+const shutdownTimeout = 5 * time.Second
+
+// Setup installs the tracer provider of cfg as the global one. The spans of the stdout exporter are written to w.
+// With the none exporter, the global provider stays a no-op. The returned function flushes the spans that are left.
+func Setup(ctx context.Context, cfg config.Tracing, w io.Writer) (func(), error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case config.TracingStdout:
+		e, err := stdouttrace.New(stdouttrace.WithWriter(w))
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		exporter = e
+	case config.TracingOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		e, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		exporter = e
+	default:
+		return func() {}, nil
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := tp.Shutdown(ctx); err != nil {
+			slog.Error("failed to flush the traces", slog.Any("error", err))
+		}
+	}, nil
+}
+
+// Tracer is the tracer of a package of the server, from the global provider at the time of the call.
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer("github.com/mio256/wplus-server/" + name)
+}
+
+// End records err on span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
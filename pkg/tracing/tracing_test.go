@@ -0,0 +1,124 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/mio256/wplus-server/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// record installs a provider that keeps the ended spans in memory.
+func record(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	flush, err := Setup(context.Background(), config.Tracing{Exporter: config.TracingNone}, nil)
+	require.NoError(t, err)
+	t.Cleanup(flush)
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return sr
+}
+
+func attrs(s sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	m := map[attribute.Key]attribute.Value{}
+	for _, kv := range s.Attributes() {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestMiddleware(t *testing.T) {
+	sr := record(t)
+
+	r := gin.New()
+	r.ContextWithFallback = true
+	r.Use(Middleware())
+	r.GET("/items/:id/", func(c *gin.Context) {
+		_, span := Tracer("test").Start(c, "child")
+		span.End()
+		c.Status(http.StatusNoContent)
+	})
+	r.GET("/broken/", func(c *gin.Context) {
+		_ = c.Error(errors.New("broken"))
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest("GET", "/items/1/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/broken/", nil))
+
+	spans := sr.Ended()
+	require.Len(t, spans, 3)
+
+	child, server, broken := spans[0], spans[1], spans[2]
+	assert.Equal(t, "child", child.Name())
+	assert.Equal(t, server.SpanContext().SpanID(), child.Parent().SpanID())
+
+	assert.Equal(t, "GET /items/:id/", server.Name())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.True(t, server.Parent().IsRemote())
+	a := attrs(server)
+	assert.Equal(t, "/items/:id/", a["http.route"].AsString())
+	assert.Equal(t, int64(http.StatusNoContent), a["http.response.status_code"].AsInt64())
+	assert.Equal(t, codes.Unset, server.Status().Code)
+
+	assert.Equal(t, "GET /broken/", broken.Name())
+	assert.Equal(t, codes.Error, broken.Status().Code)
+	require.Len(t, broken.Events(), 1)
+	assert.Equal(t, "exception", broken.Events()[0].Name)
+}
+
+func TestQueryTracer(t *testing.T) {
+	sr := record(t)
+	qt := NewQueryTracer()
+
+	ctx := qt.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "-- name: GetUser :one\nselect * from users where id = $1"})
+	qt.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	ctx = qt.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "select 1"})
+	qt.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: context.Canceled})
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "GetUser", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	a := attrs(spans[0])
+	assert.Equal(t, "postgresql", a["db.system"].AsString())
+	assert.Equal(t, "GetUser", a["db.operation.name"].AsString())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Equal(t, "query", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
+
+func TestSetupStdout(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	var b bytes.Buffer
+	flush, err := Setup(context.Background(), config.Tracing{Exporter: config.TracingStdout, SampleRatio: 1}, &b)
+	require.NoError(t, err)
+
+	_, span := Tracer("test").Start(context.Background(), "stdout")
+	span.End()
+	flush()
+	assert.Contains(t, b.String(), `"Name":"stdout"`)
+	assert.Contains(t, b.String(), ServiceName)
+}
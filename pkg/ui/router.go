@@ -1,12 +1,19 @@
 package ui
 
 import (
-	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/mio256/wplus-server/pkg/app"
+	"github.com/mio256/wplus-server/pkg/config"
 	"github.com/mio256/wplus-server/pkg/handler"
-	"github.com/mio256/wplus-server/pkg/infra"
+	"github.com/mio256/wplus-server/pkg/metrics"
+	"github.com/mio256/wplus-server/pkg/ratelimit"
+	"github.com/mio256/wplus-server/pkg/tracing"
 	"github.com/mio256/wplus-server/pkg/util"
 )
 
@@ -17,12 +24,26 @@ const EmployeePath = "/employees/"
 const WorkEntryPath = "/work_entries/"
 const UserPath = "/users/"
 const OutputPath = "/output/"
+const OvertimePath = "/overtime/"
+const HolidayPath = "/holidays/"
+const LeaveTypePath = "/leave_types/"
+const LeaveRequestPath = "/leave_requests/"
+const ShiftPath = "/shifts/"
+const SummaryPath = "/summaries/"
+const AuditLogPath = "/audit_logs/"
+const LivenessPath = "/livez/"
+const ReadinessPath = "/readyz/"
 
-func DBContext() gin.HandlerFunc {
-	ctx := context.Background()
-	dbConn := infra.ConnectDB(ctx)
+// PingPath and DBPingPath are the former health checks, kept for the clients that still call them.
+const PingPath = "/ping/"
+const DBPingPath = "/db-ping/"
+const MetricsPath = "/metrics"
+
+// AppContext gives the application and its pool to the handlers.
+func AppContext(a *app.App) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Set("db", dbConn)
+		c.Set("app", a)
+		c.Set("db", a.DB)
 	}
 }
 
@@ -34,61 +55,171 @@ func UserContext() gin.HandlerFunc {
 			return
 		}
 		c.Set("user", userClaims)
+		c.Request = c.Request.WithContext(util.WithLogAttrs(c.Request.Context(),
+			slog.Uint64("user_id", userClaims.UserID),
+			slog.Uint64("office_id", userClaims.OfficeID),
+		))
+	}
+
+}
+
+// userKey keys the rate of the signed in user. User IDs are numbered by office.
+func userKey(c *gin.Context) string {
+	user := c.MustGet("user").(*util.UserClaims)
+	return fmt.Sprintf("%d/%d", user.OfficeID, user.UserID)
+}
+
+// officeKey keys the exports of the office of the signed in user.
+func officeKey(c *gin.Context) string {
+	return strconv.FormatUint(c.MustGet("user").(*util.UserClaims).OfficeID, 10)
+}
+
+// exposeHeaders are the headers of responses that browser clients may read: the name of an export,
+// the wait after a 429 and the ID of the request.
+var exposeHeaders = []string{"Content-Disposition", "Retry-After", "X-Request-ID"}
+
+func corsConfig(cfg config.CORS) cors.Config {
+	c := cors.Config{
+		AllowMethods:     cfg.AllowMethods,
+		AllowHeaders:     cfg.AllowHeaders,
+		ExposeHeaders:    exposeHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
+	}
+	if cfg.AllowsAllOrigins() {
+		c.AllowAllOrigins = true
+	} else {
+		c.AllowOrigins = cfg.AllowOrigins
 	}
+	return c
+}
 
+// MetricsHandler serves the metrics on a listener of their own.
+func MetricsHandler(a *app.App) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, a.Metrics.Handler())
+	return mux
 }
 
-func SetupRouter() *gin.Engine {
-	r := gin.Default()
-
-	r.Use(cors.Default())
-	r.Use(DBContext())
-
-	// ping
-	r.GET("/ping/", func(ctx *gin.Context) {
-		ctx.JSON(200, gin.H{
-			"message": "pong",
-		})
-	})
-	// db-ping
-	r.GET("/db-ping/", func(ctx *gin.Context) {
-		infra.CheckConnectDB(ctx)
-		ctx.JSON(200, gin.H{
-			"message": "db-pong",
-		})
-	})
+func SetupRouter(a *app.App) *gin.Engine {
+	r := gin.New()
+	// the handlers pass c as the context of their queries, which carries the span of the request this way
+	r.ContextWithFallback = true
+	r.Use(tracing.Middleware(), handler.RequestID(), handler.RequestLogger(), a.Metrics.Middleware(), handler.Recovery())
+	// X-Forwarded-For is ignored unless proxies are configured, since clients can set it to any address
+	proxies := a.Config.Server.TrustedProxies
+	if len(proxies) == 0 {
+		proxies = nil
+	}
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		// the config is validated
+		panic(err)
+	}
+	r.Use(handler.SecurityHeaders(a.Config.Security))
+	r.Use(cors.New(corsConfig(a.Config.CORS)))
+	r.Use(AppContext(a))
+
+	limits := a.Config.Limits
+
+	// health
+	r.GET(LivenessPath, handler.GetLiveness)
+	r.GET(ReadinessPath, handler.GetReadiness)
+	r.GET(PingPath, handler.GetLiveness)
+	r.GET(DBPingPath, handler.GetReadiness)
+	// metrics, unless they have a listener of their own
+	if a.Config.Metrics.Token != "" {
+		r.GET(MetricsPath, metrics.Protect(a.Metrics.Handler(), a.Config.Metrics.Token))
+	}
+
+	api := r.Group("")
+	api.Use(ratelimit.New(limits.IP).Middleware(ratelimit.IP))
+	api.Use(ratelimit.MaxBody(int64(limits.MaxBodyBytes)))
 
 	// login
-	r.POST(LoginPath, handler.PostLogin)
+	api.POST(LoginPath, ratelimit.New(limits.Login).Middleware(ratelimit.IP), handler.PostLogin)
 
 	// private
-	p := r.Group("")
+	p := api.Group("")
 	p.Use(util.AuthMiddleware)
 	p.Use(UserContext())
+	p.Use(ratelimit.New(limits.User).Middleware(userKey))
+	// exports are turned away before their transaction begins, so that the rejected ones hold no connection
+	export := p.Group("")
+	export.Use(ratelimit.New(limits.Export).Middleware(userKey))
+	export.Use(ratelimit.NewConcurrency(limits.ExportConcurrency, limits.ExportConcurrencyPerOffice).Middleware(officeKey))
+	export.Use(handler.Transaction())
+	p.Use(handler.Transaction())
 	// office
 	p.GET(OfficePath, handler.GetOffice)
+	p.PUT(OfficePath, handler.ChangeOfficeTimeZone)
+	p.GET(OfficePath+"overtime_rules/", handler.GetOvertimeRules)
+	p.PUT(OfficePath+"overtime_rules/", handler.PutOvertimeRules)
+	p.GET(OfficePath+"closures/", handler.GetOfficeClosures)
+	p.POST(OfficePath+"closures/", handler.PostOfficeClosure)
+	p.DELETE(OfficePath+"closures/:id/", handler.DeleteOfficeClosure)
 	// workplace
 	p.GET(WorkplacePath, handler.GetWorkplaces)
 	p.GET(WorkplacePath+":id/", handler.GetWorkplace)
 	p.POST(WorkplacePath, handler.PostWorkplace)
+	p.PATCH(WorkplacePath+":id/", handler.PatchWorkplace)
 	p.DELETE(WorkplacePath+":id/", handler.DeleteWorkplace)
+	p.GET(WorkplacePath+"deleted/", handler.GetDeletedWorkplaces)
+	p.POST(WorkplacePath+":id/restore/", handler.RestoreWorkplace)
+	p.GET(WorkplacePath+":id/shift_templates/", handler.GetShiftTemplates)
+	p.POST(WorkplacePath+":id/shift_templates/", handler.PostShiftTemplate)
+	p.DELETE(WorkplacePath+":id/shift_templates/:template_id/", handler.DeleteShiftTemplate)
 	// employee
 	p.GET(EmployeePath, handler.GetEmployeesByOffice)
 	p.GET(EmployeePath+"workplace/:workplace_id/", handler.GetEmployees)
 	p.GET(EmployeePath+":id/", handler.GetEmployee)
 	p.POST(EmployeePath, handler.PostEmployee)
 	p.PUT(EmployeePath+":id/", handler.ChangeEmployeeWorkplace)
+	p.GET(EmployeePath+":id/assignments/", handler.GetEmployeeAssignments)
+	p.DELETE(EmployeePath+":id/assignments/:assignment_id/", handler.CancelEmployeeAssignment)
+	p.PATCH(EmployeePath+":id/", handler.PatchEmployee)
 	p.DELETE(EmployeePath+":id/", handler.DeleteEmployee)
+	p.GET(EmployeePath+"deleted/", handler.GetDeletedEmployees)
+	p.POST(EmployeePath+":id/restore/", handler.RestoreEmployee)
+	p.GET(EmployeePath+":id/leave_balance/", handler.GetLeaveBalance)
+	p.POST(EmployeePath+":id/leave_grants/", handler.PostLeaveGrant)
 	// work_entry
 	p.GET(WorkEntryPath, handler.GetWorkEntriesByOffice)
 	p.GET(WorkEntryPath+"workplace/:workplace_id/", handler.GetWorkEntriesByWorkplace)
 	p.GET(WorkEntryPath+"employee/:employee_id/", handler.GetWorkEntries)
 	p.POST(WorkEntryPath, handler.PostWorkEntry)
 	p.DELETE(WorkEntryPath+":id/", handler.DeleteWorkEntry)
+	p.GET(WorkEntryPath+"deleted/", handler.GetDeletedWorkEntries)
+	p.GET(WorkEntryPath+"conflicts/", handler.GetWorkEntryConflicts)
+	p.POST(WorkEntryPath+":id/restore/", handler.RestoreWorkEntry)
 	// user
 	p.POST(UserPath, handler.PostUserAndEmployee)
 	// output
-	p.POST(OutputPath+"workplace/:workplace_id/", handler.GetOutputByWorkplace)
+	export.POST(OutputPath+"workplace/:workplace_id/", handler.GetOutputByWorkplace)
+	// overtime
+	p.GET(OvertimePath, handler.GetOvertimeSummary)
+	// holiday
+	p.GET(HolidayPath, handler.GetHolidays)
+	p.GET(HolidayPath+":date/", handler.GetHoliday)
+	// leave
+	p.GET(LeaveTypePath, handler.GetLeaveTypes)
+	p.POST(LeaveTypePath, handler.PostLeaveType)
+	p.GET(LeaveRequestPath, handler.GetLeaveRequests)
+	p.POST(LeaveRequestPath, handler.PostLeaveRequest)
+	p.POST(LeaveRequestPath+":id/approve/", handler.ApproveLeaveRequest)
+	p.POST(LeaveRequestPath+":id/reject/", handler.RejectLeaveRequest)
+	p.POST(LeaveRequestPath+":id/cancel/", handler.CancelLeaveRequest)
+	// shift
+	p.GET(ShiftPath, handler.GetShifts)
+	p.POST(ShiftPath, handler.PostShift)
+	p.DELETE(ShiftPath+":id/", handler.DeleteShift)
+	p.POST(ShiftPath+"publish/", handler.PublishShifts)
+	p.GET(ShiftPath+"comparison/", handler.GetShiftComparison)
+	// summary
+	p.GET(SummaryPath+"employees/", handler.GetEmployeeSummaries)
+	p.GET(SummaryPath+"workplaces/", handler.GetWorkplaceSummaries)
+	// audit log
+	p.GET(AuditLogPath, handler.GetAuditLogs)
+	export.GET(AuditLogPath+"csv/", handler.GetAuditLogsCSV)
 
 	return r
 }
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/mio256/wplus-server/pkg/app"
+	"github.com/mio256/wplus-server/pkg/config"
+	"github.com/mio256/wplus-server/pkg/server"
+	"github.com/mio256/wplus-server/pkg/tracing"
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/taxio/errors"
+)
+
+// Run serves until SIGTERM, an interrupt or the end of ctx, and closes the pool after the requests in flight
+// are drained. Both the server binary and the server command start here. cfg must be validated.
+func Run(ctx context.Context, cfg *config.Config) error {
+	slog.SetDefault(util.NewLogger(os.Stdout, cfg.Log.Level))
+	flush, err := tracing.Setup(ctx, cfg.Tracing, os.Stdout)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	defer flush()
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	a, err := app.New(ctx, cfg)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	defer a.Close()
+
+	if err := server.RunWithInternal(ctx, cfg.Server, SetupRouter(a), cfg.Metrics.Addr, MetricsHandler(a)); err != nil {
+		return errors.Wrap(err)
+	}
+	return nil
+}
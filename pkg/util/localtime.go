@@ -0,0 +1,154 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/taxio/errors"
+)
+
+// DefaultTimeZone is used for offices that have no time zone configured.
+const DefaultTimeZone = "Asia/Tokyo"
+
+const DateLayout = "2006-01-02"
+
+var clockLayouts = []string{"15:04", "15:04:05"}
+
+// LoadLocation returns the location of an office. An empty name falls back to DefaultTimeZone.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultTimeZone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return loc, nil
+}
+
+// ParseLocalDate parses either a plain date or an RFC 3339 timestamp and returns the business day in loc.
+func ParseLocalDate(s string, loc *time.Location) (pgtype.Date, error) {
+	if d, err := time.ParseInLocation(DateLayout, s, loc); err == nil {
+		return NewDate(d), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return pgtype.Date{}, errors.Wrap(err)
+	}
+	return NewDate(t.In(loc)), nil
+}
+
+// ParseLocalClock parses either a clock time (15:04 or 15:04:05) or an RFC 3339 timestamp
+// and returns the wall clock time in loc.
+func ParseLocalClock(s string, loc *time.Location) (pgtype.Time, error) {
+	if c, err := ParseClock(s); err == nil {
+		return c.Time, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return pgtype.Time{}, errors.Wrap(err)
+	}
+	return NewClock(t.In(loc)), nil
+}
+
+// NewDate returns the calendar date of t as it is seen in t's location.
+func NewDate(t time.Time) pgtype.Date {
+	return pgtype.Date{
+		Time:  time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC),
+		Valid: true,
+	}
+}
+
+// NewClock returns the wall clock time of t as it is seen in t's location.
+func NewClock(t time.Time) pgtype.Time {
+	d := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	return pgtype.Time{
+		Microseconds: d.Microseconds(),
+		Valid:        true,
+	}
+}
+
+// Today returns the current business day in loc.
+func Today(loc *time.Location) pgtype.Date {
+	return NewDate(time.Now().In(loc))
+}
+
+// MonthRange returns the first and the last day of the month.
+func MonthRange(year int, month time.Month) (pgtype.Date, pgtype.Date) {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
+	return pgtype.Date{Time: first, Valid: true}, pgtype.Date{Time: last, Valid: true}
+}
+
+// ClockDuration returns the duration since midnight.
+func ClockDuration(t pgtype.Time) time.Duration {
+	return time.Duration(t.Microseconds) * time.Microsecond
+}
+
+// FormatDate formats a business day, or returns an empty string when d is null.
+func FormatDate(d pgtype.Date) string {
+	if !d.Valid {
+		return ""
+	}
+	return d.Time.Format(DateLayout)
+}
+
+// FormatClock formats a wall clock time, or returns an empty string when t is null.
+func FormatClock(t pgtype.Time) string {
+	if !t.Valid {
+		return ""
+	}
+	d := ClockDuration(t)
+	return fmt.Sprintf("%02d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+// FormatTimestamp formats a server timestamp in loc, or returns an empty string when ts is null.
+func FormatTimestamp(ts pgtype.Timestamp, loc *time.Location) string {
+	if !ts.Valid {
+		return ""
+	}
+	// timestamp columns are written by the database in UTC and scanned as UTC.
+	return ts.Time.In(loc).Format(time.RFC3339)
+}
+
+// Clock is a nullable wall clock time that is encoded as "15:04:05" in JSON.
+type Clock struct {
+	pgtype.Time
+}
+
+// ParseClock parses a clock time such as 15:04 or 15:04:05.
+func ParseClock(s string) (Clock, error) {
+	for _, layout := range clockLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return Clock{NewClock(t)}, nil
+		}
+	}
+	return Clock{}, errors.New("invalid clock", errors.WithAttrs(errors.Attr("value", s)))
+}
+
+func (c Clock) MarshalJSON() ([]byte, error) {
+	if !c.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(FormatClock(c.Time))
+}
+
+func (c *Clock) UnmarshalJSON(b []byte) error {
+	var s *string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return errors.Wrap(err)
+	}
+	if s == nil {
+		*c = Clock{}
+		return nil
+	}
+	v, err := ParseClock(*s)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	*c = v
+	return nil
+}
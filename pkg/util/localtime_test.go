@@ -0,0 +1,87 @@
+package util_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLocalDate(t *testing.T) {
+	tokyo, err := util.LoadLocation("")
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		Input string
+		Want  string
+	}{
+		"plain":         {Input: "2024-03-31", Want: "2024-03-31"},
+		"local-offset":  {Input: "2024-03-31T00:00:00.000+09:00", Want: "2024-03-31"},
+		"utc-evening":   {Input: "2024-03-30T15:30:00.000Z", Want: "2024-03-31"},
+		"utc-afternoon": {Input: "2024-03-30T14:59:59Z", Want: "2024-03-30"},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			d, err := util.ParseLocalDate(tt.Input, tokyo)
+			require.NoError(t, err)
+			require.Equal(t, tt.Want, util.FormatDate(d))
+		})
+	}
+
+	_, err = util.ParseLocalDate("2024/03/31", tokyo)
+	require.Error(t, err)
+}
+
+func TestParseLocalClock(t *testing.T) {
+	tokyo, err := util.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		Input string
+		Want  string
+	}{
+		"clock":         {Input: "08:30", Want: "08:30:00"},
+		"clock-seconds": {Input: "08:30:15", Want: "08:30:15"},
+		"local-offset":  {Input: "1970-01-01T08:30:00.000+09:00", Want: "08:30:00"},
+		"utc":           {Input: "1969-12-31T23:30:00.000Z", Want: "08:30:00"},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			c, err := util.ParseLocalClock(tt.Input, tokyo)
+			require.NoError(t, err)
+			require.Equal(t, tt.Want, util.FormatClock(c))
+		})
+	}
+}
+
+func TestMonthRange(t *testing.T) {
+	first, last := util.MonthRange(2024, time.February)
+	require.Equal(t, "2024-02-01", util.FormatDate(first))
+	require.Equal(t, "2024-02-29", util.FormatDate(last))
+
+	first, last = util.MonthRange(2024, time.December)
+	require.Equal(t, "2024-12-01", util.FormatDate(first))
+	require.Equal(t, "2024-12-31", util.FormatDate(last))
+}
+
+func TestClockJSON(t *testing.T) {
+	var v struct {
+		Start util.Clock `json:"start"`
+		End   util.Clock `json:"end"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"start": "09:30", "end": null}`), &v))
+	require.True(t, v.Start.Valid)
+	require.False(t, v.End.Valid)
+
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"start": "09:30:00", "end": null}`, string(b))
+
+	require.Error(t, json.Unmarshal([]byte(`{"start": "9:30pm"}`), &v))
+}
@@ -2,11 +2,76 @@ package util
 
 import (
 	"context"
+	"io"
 	"log/slog"
+	"slices"
+	"sort"
+	"strings"
 
+	"github.com/gin-gonic/gin"
 	"github.com/taxio/errors"
 )
 
+// NewLogger writes JSON lines at level and above to w. The lines carry the attributes of their context
+// given by WithLogAttrs, and the values of secrets are redacted.
+func NewLogger(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	})})
+}
+
+type logAttrsKey struct{}
+
+// WithLogAttrs returns a context whose log lines carry attrs after the ones of ctx.
+func WithLogAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	prev, _ := ctx.Value(logAttrsKey{}).([]slog.Attr)
+	return context.WithValue(ctx, logAttrsKey{}, append(slices.Clip(prev), attrs...))
+}
+
+func logAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	// the keys of a gin context do not reach its request
+	if c, ok := ctx.(*gin.Context); ok {
+		if c.Request == nil {
+			return nil
+		}
+		ctx = c.Request.Context()
+	}
+	attrs, _ := ctx.Value(logAttrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.AddAttrs(logAttrs(ctx)...)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// redactedKeys are attributes whose values are never logged, in any group and in any case.
+var redactedKeys = []string{"password", "secret", "secret_key", "token", "authorization", "cookie", "database_url", "dsn"}
+
+func redact(_ []string, a slog.Attr) slog.Attr {
+	if slices.Contains(redactedKeys, strings.ToLower(a.Key)) {
+		return slog.String(a.Key, "[REDACTED]")
+	}
+	return a
+}
+
+// LogError logs err with its attributes and the stack frames where it was created.
 func LogError(ctx context.Context, err error) {
 	if err == nil {
 		return
@@ -15,8 +80,19 @@ func LogError(ctx context.Context, err error) {
 	var cErr *errors.Error
 	var attrs []any
 	if errors.As(err, &cErr) {
+		// a group rather than a map, so that the attributes are redacted too
+		errAttrs := cErr.Attributes()
+		keys := make([]string, 0, len(errAttrs))
+		for k := range errAttrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		group := make([]any, 0, len(keys))
+		for _, k := range keys {
+			group = append(group, slog.Any(k, errAttrs[k]))
+		}
 		attrs = []any{
-			slog.Any("attributes", cErr.Attributes()),
+			slog.Group("attributes", group...),
 			slog.Any("stackTrace", ErrorStackFramePaths(err)),
 		}
 	}
@@ -0,0 +1,54 @@
+package util_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/mio256/wplus-server/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taxio/errors"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := util.NewLogger(&buf, slog.LevelInfo)
+	prev := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	ctx := util.WithLogAttrs(context.Background(), slog.String("request_id", "req-1"))
+	ctx = util.WithLogAttrs(ctx, slog.Uint64("user_id", 7))
+
+	lines := func() []map[string]any {
+		var res []map[string]any
+		for _, l := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+			var m map[string]any
+			require.NoError(t, json.Unmarshal(l, &m))
+			res = append(res, m)
+		}
+		buf.Reset()
+		return res
+	}
+
+	logger.DebugContext(ctx, "hidden")
+	logger.InfoContext(ctx, "login", slog.String("password", "hunter2"), slog.Group("db", slog.String("DSN", "postgres://u:p@h/db")))
+	got := lines()
+	require.Len(t, got, 1)
+	assert.Equal(t, "login", got[0]["msg"])
+	assert.Equal(t, "req-1", got[0]["request_id"])
+	assert.Equal(t, float64(7), got[0]["user_id"])
+	assert.Equal(t, "[REDACTED]", got[0]["password"])
+	assert.Equal(t, map[string]any{"DSN": "[REDACTED]"}, got[0]["db"])
+
+	util.LogError(ctx, errors.New("failed", errors.WithAttrs(errors.Attr("id", 1), errors.Attr("secret_key", "k"))))
+	got = lines()
+	require.Len(t, got, 1)
+	assert.Equal(t, "ERROR", got[0]["level"])
+	assert.Equal(t, "req-1", got[0]["request_id"])
+	assert.Equal(t, map[string]any{"id": float64(1), "secret_key": "[REDACTED]"}, got[0]["attributes"])
+	assert.NotEmpty(t, got[0]["stackTrace"])
+}
@@ -0,0 +1,19 @@
+package util
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DeletedRetentionDays is how long soft-deleted rows can be restored before they are purged.
+const DeletedRetentionDays = 30
+
+// DeletedSince returns the deleted_at boundary of rows deleted within the last days from now.
+// deleted_at is written by the database in UTC.
+func DeletedSince(now time.Time, days int) pgtype.Timestamp {
+	return pgtype.Timestamp{
+		Time:  now.UTC().AddDate(0, 0, -days),
+		Valid: true,
+	}
+}
@@ -21,8 +21,30 @@ type UserClaims struct {
 	Role        string
 }
 
+// secretKey signs the tokens. Commands that do not load the configuration fall back to SECRET_KEY.
+var secretKey string
+
+// SetSecretKey sets the key that signs the tokens. The server sets it from the configuration at startup.
+func SetSecretKey(key string) {
+	secretKey = key
+}
+
+func signingKey() ([]byte, error) {
+	key := secretKey
+	if key == "" {
+		key = os.Getenv("SECRET_KEY")
+	}
+	if key == "" {
+		return nil, errors.New("secret key is not set")
+	}
+	return []byte(key), nil
+}
+
 func GenerateToken(userClaims UserClaims) (string, error) {
-	key := os.Getenv("SECRET_KEY")
+	key, err := signingKey()
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
 
 	claims := jwt.MapClaims{
 		"sub":          "AccessToken",
@@ -35,7 +57,7 @@ func GenerateToken(userClaims UserClaims) (string, error) {
 		"exp":          time.Now().Add(time.Hour).Unix(),
 	}
 
-	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
 	if err != nil {
 		return "", errors.Wrap(err)
 	}
@@ -44,14 +66,17 @@ func GenerateToken(userClaims UserClaims) (string, error) {
 }
 
 func ParseToken(token string) (*jwt.Token, error) {
-	key := os.Getenv("SECRET_KEY")
+	key, err := signingKey()
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
 
 	t, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			err := fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 			return nil, errors.Wrap(err)
 		}
-		return []byte(key), nil
+		return key, nil
 	})
 	if err != nil {
 		return nil, errors.Wrap(err)